@@ -0,0 +1,61 @@
+package vugu
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type buildContextValue struct {
+	key   interface{}
+	value interface{}
+}
+
+// SetContextValue makes a value available to all descendants of the component whose Build
+// is currently running.  Descendants retrieve it with ContextValue (by key) or ContextValueFor
+// (by type).  The value is removed once the subtree of the current component has been built,
+// so siblings and ancestors do not see it.  Setting the same key again shadows the prior
+// value for the subtree.  Like context.Context, keys are compared with == and should be
+// of an unexported type to avoid collisions, e.g. `type themeKey struct{}`.
+func (bi *BuildIn) SetContextValue(key, value interface{}) {
+	if key == nil {
+		panic(fmt.Errorf("SetContextValue called with nil key"))
+	}
+	bi.contextValues = append(bi.contextValues, buildContextValue{key: key, value: value})
+}
+
+// ContextValue returns the nearest value provided by an ancestor for the specified key
+// (or by the current component itself), or nil if there is none.
+func (bi *BuildIn) ContextValue(key interface{}) interface{} {
+	for i := len(bi.contextValues) - 1; i >= 0; i-- {
+		if bi.contextValues[i].key == key {
+			return bi.contextValues[i].value
+		}
+	}
+	return nil
+}
+
+// ContextValueFor finds the nearest provided value which is assignable to the type pointed to
+// by ptr and assigns it, regardless of the key used to provide it.  Returns true if a value was found.
+// Example:
+//
+//	var theme *Theme
+//	if in.ContextValueFor(&theme) { /* ... */ }
+func (bi *BuildIn) ContextValueFor(ptr interface{}) bool {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		panic(fmt.Errorf("ContextValueFor requires a non-nil pointer, got %T", ptr))
+	}
+	et := rv.Type().Elem()
+	for i := len(bi.contextValues) - 1; i >= 0; i-- {
+		v := bi.contextValues[i].value
+		if v == nil {
+			continue
+		}
+		vv := reflect.ValueOf(v)
+		if vv.Type().AssignableTo(et) {
+			rv.Elem().Set(vv)
+			return true
+		}
+	}
+	return false
+}
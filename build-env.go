@@ -29,6 +29,12 @@ type BuildEnv struct {
 
 	// new build output from this build pass (becomes buildCache next build pass)
 	buildResults map[buildCacheKey]*BuildOut
+
+	// state from RestoreState not yet applied, keyed by CompKey string
+	pendingState map[string]*devStateComponent
+
+	// problems found while applying pendingState, see StateMismatches
+	stateMismatches []StateMismatch
 }
 
 // BuildResults contains the BuildOut values for full tree of components built.
@@ -90,12 +96,22 @@ func (e *BuildEnv) RunBuild(builder Builder) *BuildResults {
 	if len(buildIn.PositionHashList) != 0 {
 		panic(fmt.Errorf("unexpected PositionHashList len = %d", len(buildIn.PositionHashList)))
 	}
+	if len(buildIn.contextValues) != 0 {
+		panic(fmt.Errorf("unexpected contextValues len = %d", len(buildIn.contextValues)))
+	}
 
 	return &BuildResults{allOut: e.buildResults, Out: e.buildResults[makeBuildCacheKey(builder)]}
 }
 
 func (e *BuildEnv) buildOne(buildIn *BuildIn, thisb Builder) {
 
+	// context values provided during this Build are visible to this component's subtree only,
+	// remove them upon exit
+	contextMark := len(buildIn.contextValues)
+	defer func() {
+		buildIn.contextValues = buildIn.contextValues[:contextMark]
+	}()
+
 	beforeBuilder, ok := thisb.(BeforeBuilder)
 	if ok {
 		beforeBuilder.BeforeBuild()
@@ -150,6 +166,7 @@ func (e *BuildEnv) CachedComponent(compKey CompKey) Builder {
 func (e *BuildEnv) UseComponent(compKey CompKey, component Builder) {
 	delete(e.compCache, compKey)    // make sure it's not in the cache
 	e.compUsed[compKey] = component // make sure it is in the used
+	e.applyPendingCompState(compKey, component)
 }
 
 // SetWireFunc assigns the function to be called by WireComponent.
@@ -67,3 +67,59 @@ func (b *testb1) Build(in *BuildIn) (out *BuildOut) {
 		Out: []*VGNode{},
 	}
 }
+
+type ctxTestKey struct{}
+
+type ctxTestTheme struct{ Name string }
+
+// ctxTestBuilder provides (if set) and records context values, then builds its children
+type ctxTestBuilder struct {
+	provide  interface{}
+	children []Builder
+
+	gotKey   interface{}
+	gotTheme *ctxTestTheme
+}
+
+func (b *ctxTestBuilder) Build(in *BuildIn) (out *BuildOut) {
+	b.gotKey = in.ContextValue(ctxTestKey{})
+	in.ContextValueFor(&b.gotTheme)
+	if b.provide != nil {
+		in.SetContextValue(ctxTestKey{}, b.provide)
+	}
+	return &BuildOut{Components: b.children}
+}
+
+func TestBuildEnvContextValues(t *testing.T) {
+
+	assert := assert.New(t)
+
+	be, err := NewBuildEnv()
+	assert.NoError(err)
+
+	theme := &ctxTestTheme{Name: "dark"}
+
+	grandchild := &ctxTestBuilder{}
+	child1 := &ctxTestBuilder{provide: theme, children: []Builder{grandchild}}
+	child2 := &ctxTestBuilder{}
+	root := &ctxTestBuilder{provide: "root", children: []Builder{child1, child2}}
+
+	be.RunBuild(root)
+
+	// nothing above root
+	assert.Nil(root.gotKey)
+	assert.Nil(root.gotTheme)
+
+	// children see root's value
+	assert.Equal("root", child1.gotKey)
+	assert.Equal("root", child2.gotKey)
+	assert.Nil(child1.gotTheme)
+
+	// grandchild sees the shadowing value, by key and by type
+	assert.Equal(theme, grandchild.gotKey)
+	assert.Equal(theme, grandchild.gotTheme)
+
+	// child1's value did not leak to its sibling
+	assert.Nil(child2.gotTheme)
+
+}
@@ -0,0 +1,775 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/vugu/vugu/gen"
+	"github.com/vugu/vugu/vugufmt"
+)
+
+// checker checks .vugu files with the formatter, the code generator and the type checker.  Imported
+// packages are loaded from source once and shared between checks until reset is called.
+type checker struct {
+	mu     sync.Mutex
+	fset   *token.FileSet
+	imp    types.ImporterFrom
+	checks int    // since the last reset
+	tmpDir string // code generator output is written here
+}
+
+// maxChecks is the number of checks after which the checker starts over, so the FileSet, which
+// keeps every file added to it, does not grow without bound.
+const maxChecks = 50
+
+func newChecker(tmpDir string) *checker {
+	c := &checker{tmpDir: tmpDir}
+	c.reset()
+	return c
+}
+
+// reset forgets the loaded packages, e.g. after Go files have changed.
+func (c *checker) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resetLocked()
+}
+
+func (c *checker) resetLocked() {
+	c.fset = token.NewFileSet()
+	c.imp = importer.ForCompiler(c.fset, "source", nil).(types.ImporterFrom)
+	c.checks = 0
+}
+
+// segment is text copied from a .vugu file into the generated check file.
+type segment struct {
+	docOff, checkOff, n int
+}
+
+// checkResult is what a check found out about a document.
+type checkResult struct {
+	doc     *document
+	diags   []Diagnostic
+	pkgName string
+
+	// the rest is only set if the Go code in the file parsed
+	fset    *token.FileSet
+	file    *ast.File // the generated check file
+	segs    []segment
+	info    *types.Info
+	pkg     *types.Package
+	imports map[string]*types.Package // packages imported by the check file, by name
+}
+
+// check checks doc, a .vugu file
+func (c *checker) check(doc *document) *checkResult {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.checks >= maxChecks {
+		c.resetLocked()
+	}
+	c.checks++
+
+	dir := filepath.Dir(doc.path)
+	base := strings.TrimSuffix(filepath.Base(doc.path), ".vugu")
+	res := &checkResult{doc: doc, pkgName: packageName(dir)}
+
+	diag := func(source string, off int, msg string) {
+		res.diags = append(res.diags, Diagnostic{Range: doc.rangeOf(off), Severity: 1, Source: source, Message: msg})
+	}
+
+	// the structure of the markup and the syntax of Go code in attributes, with positions
+	err := vugufmt.NewFormatter().FormatHTML(doc.path, strings.NewReader(doc.text), ioutil.Discard)
+	if err != nil {
+		if ferr, ok := err.(*vugufmt.FmtError); ok {
+			diag("vugufmt", doc.offsetAt(ferr.Line, ferr.Column), ferr.Msg)
+		} else {
+			diag("vugufmt", 0, err.Error())
+		}
+		return res
+	}
+
+	root := parseVugu(doc.text)
+	structType := fnameToGoTypeName(base)
+	g := &checkGen{doc: doc, pkgName: res.pkgName, checkPath: filepath.Join(dir, base+"_vgcheck.go")}
+	src := g.generate(root, structType)
+
+	file, err := parser.ParseFile(c.fset, g.checkPath, src, parser.AllErrors)
+	if err != nil {
+		if el, ok := err.(scanner.ErrorList); ok {
+			for _, e := range el {
+				if e.Pos.Filename == doc.path {
+					diag("go", doc.offsetAt(e.Pos.Line, e.Pos.Column), e.Msg)
+				}
+			}
+		}
+		if len(res.diags) == 0 {
+			diag("go", 0, err.Error())
+		}
+		return res
+	}
+
+	// what the code generator has to say, the messages have no position so it is guessed from what they name
+	pg := &gen.ParserGo{PackageName: res.pkgName, StructType: structType, OutDir: c.tmpDir, OutFile: "check_vgen.go"}
+	err = pg.Parse(strings.NewReader(doc.text), filepath.Base(doc.path))
+	if err != nil {
+		diag("vugugen", genErrorOffset(doc.text, root, err.Error()), err.Error())
+	}
+
+	files := []*ast.File{file}
+	files = append(files, c.parsePackage(dir, g.checkPath, declaredNames(file))...)
+
+	// the code generator creates the struct if it is missing, so pretend it has
+	if !declaresType(files, structType) {
+		file.Decls = append(file.Decls, &ast.GenDecl{Tok: token.TYPE, Specs: []ast.Spec{&ast.TypeSpec{
+			Name: ast.NewIdent(structType),
+			Type: &ast.StructType{Fields: &ast.FieldList{}},
+		}}})
+	}
+
+	res.fset, res.file, res.segs = c.fset, file, g.segs
+	res.info = &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+
+	seen := make(map[string]bool)
+	conf := types.Config{
+		Importer: c.imp,
+		Error: func(err error) {
+			terr, ok := err.(types.Error)
+			if !ok {
+				return
+			}
+			p := terr.Fset.Position(terr.Pos)
+			key := fmt.Sprintf("%d:%d:%s", p.Line, p.Column, terr.Msg)
+			if p.Filename != doc.path || seen[key] {
+				return
+			}
+			seen[key] = true
+			diag("go", doc.offsetAt(p.Line, p.Column), terr.Msg)
+		},
+	}
+	res.pkg, _ = conf.Check(res.pkgName, c.fset, files, res.info)
+
+	res.imports = make(map[string]*types.Package)
+	for _, spec := range file.Imports {
+		var obj types.Object
+		if spec.Name != nil {
+			obj = res.info.Defs[spec.Name]
+		} else {
+			obj = res.info.Implicits[spec]
+		}
+		if pn, ok := obj.(*types.PkgName); ok {
+			res.imports[pn.Name()] = pn.Imported()
+		}
+	}
+
+	return res
+}
+
+// genErrorOffset returns where in text, with the elements root, the code generator error msg is about.
+// The code generator reports no positions, but its messages name the element or attribute they are about,
+// e.g. `vg-portal must have a ...` or `invalid component tag name "x"`; the first one in the document
+// named by msg is used, or the start of the document if there is none.
+func genErrorOffset(text string, root *vuguElem, msg string) int {
+
+	if s := strings.TrimPrefix(msg, "unexpected text outside any element: "); s != msg {
+		if t, err := strconv.Unquote(s); err == nil {
+			if i := strings.Index(text, strings.TrimSpace(t)); i >= 0 {
+				return i
+			}
+		}
+	}
+
+	if strings.HasPrefix(msg, "Found more than one top level element") {
+		n := 0
+		for _, e := range root.children {
+			if name := strings.ToLower(e.name); name != "script" && name != "style" && name != "link" {
+				if n++; n == 2 {
+					return e.nameOff
+				}
+			}
+		}
+	}
+
+	lmsg := strings.ToLower(msg)
+	names := func(name string) bool {
+		name = strings.ToLower(name)
+		return strings.Contains(lmsg, strconv.Quote(name)) || strings.HasPrefix(lmsg, name+" ") ||
+			strings.Contains(lmsg, " "+name+" tag")
+	}
+
+	var find func(e *vuguElem) int
+	find = func(e *vuguElem) int {
+		for _, c := range e.children {
+			if names(c.name) {
+				return c.nameOff
+			}
+			for _, a := range c.attrs {
+				if names(a.key) {
+					return a.keyOff
+				}
+				// options like vg-for.noshadow
+				for _, opt := range strings.Split(a.key, ".")[1:] {
+					if names(opt) {
+						return a.keyOff
+					}
+				}
+			}
+			if off := find(c); off >= 0 {
+				return off
+			}
+		}
+		return -1
+	}
+	if off := find(root); off >= 0 {
+		return off
+	}
+	return 0
+}
+
+// parsePackage parses the Go files of the package in dir, except skip.  Declarations in generated files
+// with names in drop are removed, they are in the .vugu file being checked and may have changed since
+// the code was generated.
+func (c *checker) parsePackage(dir, skip string, drop map[string]bool) []*ast.File {
+
+	bp, err := build.Default.ImportDir(dir, 0)
+	if err != nil {
+		return nil
+	}
+
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		p := filepath.Join(dir, name)
+		if p == skip {
+			continue
+		}
+		f, err := parser.ParseFile(c.fset, p, nil, 0)
+		if f == nil || err != nil && len(f.Decls) == 0 {
+			continue
+		}
+		if strings.HasSuffix(name, "_vgen.go") {
+			dropDecls(f, drop)
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// declaredNames returns the names declared at the top level of f, methods as "Type.Method".
+func declaredNames(f *ast.File) map[string]bool {
+	names := make(map[string]bool)
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			names[funcDeclName(d)] = true
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					names[s.Name.Name] = true
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						if n.Name != "_" {
+							names[n.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// dropDecls removes the top level declarations of f with names in drop.
+func dropDecls(f *ast.File, drop map[string]bool) {
+	decls := f.Decls[:0]
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if drop[funcDeclName(d)] {
+				continue
+			}
+		case *ast.GenDecl:
+			specs := d.Specs[:0]
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if drop[s.Name.Name] {
+						continue
+					}
+				case *ast.ValueSpec:
+					if len(s.Names) == 1 && drop[s.Names[0].Name] {
+						continue
+					}
+				}
+				specs = append(specs, spec)
+			}
+			if len(specs) == 0 && d.Tok != token.IMPORT {
+				continue
+			}
+			d.Specs = specs
+		}
+		decls = append(decls, decl)
+	}
+	f.Decls = decls
+}
+
+// funcDeclName returns the name of a function, or "Type.Method" for a method
+func funcDeclName(d *ast.FuncDecl) string {
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		return d.Name.Name
+	}
+	t := d.Recv.List[0].Type
+	if st, ok := t.(*ast.StarExpr); ok {
+		t = st.X
+	}
+	if id, ok := t.(*ast.Ident); ok {
+		return id.Name + "." + d.Name.Name
+	}
+	return d.Name.Name
+}
+
+// declaresType returns true if one of files declares the type name at the top level
+func declaresType(files []*ast.File, name string) bool {
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			if d, ok := decl.(*ast.GenDecl); ok && d.Tok == token.TYPE {
+				for _, spec := range d.Specs {
+					if spec.(*ast.TypeSpec).Name.Name == name {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// packageName returns the name of the package in dir, or "main" if there are no Go files
+// (which is what the code generator uses for a new project).
+func packageName(dir string) string {
+	bp, err := build.Default.ImportDir(dir, build.ImportComment)
+	if err == nil && bp.Name != "" {
+		return bp.Name
+	}
+	return "main"
+}
+
+// fnameToGoTypeName converts a file name to the name of the component type, like the code generator
+func fnameToGoTypeName(s string) string {
+	s = strings.Split(s, ".")[0]
+	parts := strings.Split(s, "-")
+	for i, p := range parts {
+		if len(p) > 0 {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// definition returns the position of the declaration of what is at off in the document, if it is
+// Go code in the file.
+func (r *checkResult) definition(off int) (token.Position, bool) {
+
+	if r.file == nil {
+		return token.Position{}, false
+	}
+
+	// find the code the offset was copied to
+	checkOff := -1
+	for _, s := range r.segs {
+		if off >= s.docOff && off <= s.docOff+s.n {
+			checkOff = s.checkOff + off - s.docOff
+			break
+		}
+	}
+	if checkOff < 0 {
+		return token.Position{}, false
+	}
+	pos := r.fset.File(r.file.Pos()).Pos(checkOff)
+
+	var id *ast.Ident
+	ast.Inspect(r.file, func(n ast.Node) bool {
+		if n == nil || n.Pos() > pos || n.End() < pos {
+			return false
+		}
+		if i, ok := n.(*ast.Ident); ok {
+			id = i
+		}
+		return true
+	})
+	if id == nil {
+		return token.Position{}, false
+	}
+
+	obj := r.info.Uses[id]
+	if obj == nil {
+		obj = r.info.Defs[id]
+	}
+	if obj == nil || !obj.Pos().IsValid() {
+		return token.Position{}, false
+	}
+	return r.fset.Position(obj.Pos()), true
+}
+
+// component returns the type of the component with the tag name, like "pkg:Comp", or nil.
+func (r *checkResult) component(tag string) *types.TypeName {
+	if r.pkg == nil {
+		return nil
+	}
+	parts := strings.SplitN(tag, ":", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	pkg := r.imports[parts[0]]
+	if parts[0] == r.pkgName {
+		pkg = r.pkg
+	}
+	if pkg == nil {
+		return nil
+	}
+	tn, _ := pkg.Scope().Lookup(parts[1]).(*types.TypeName)
+	if tn == nil || !isComponentType(tn) {
+		return nil
+	}
+	return tn
+}
+
+// components returns the tag names of the components which can be used in the file.
+func (r *checkResult) components() map[string]*types.TypeName {
+	ret := make(map[string]*types.TypeName)
+	if r.pkg == nil {
+		return ret
+	}
+	add := func(name string, pkg *types.Package, exportedOnly bool) {
+		for _, n := range pkg.Scope().Names() {
+			tn, ok := pkg.Scope().Lookup(n).(*types.TypeName)
+			if ok && (tn.Exported() || !exportedOnly) && isComponentType(tn) {
+				ret[name+":"+n] = tn
+			}
+		}
+	}
+	add(r.pkgName, r.pkg, false)
+	for name, pkg := range r.imports {
+		add(name, pkg, true)
+	}
+	return ret
+}
+
+// isComponentType returns true for struct types with a Build method
+func isComponentType(tn *types.TypeName) bool {
+	if _, ok := tn.Type().Underlying().(*types.Struct); !ok {
+		return false
+	}
+	ms := types.NewMethodSet(types.NewPointer(tn.Type()))
+	return ms.Lookup(tn.Pkg(), "Build") != nil
+}
+
+// checkGen generates the Go code that is type checked for a .vugu file.  It has the Go code from the
+// file, and a method with the expressions and statements from the markup, as the code generator
+// would use them.  Everything copied from the file is preceded by a line directive, so positions in
+// the code are positions in the file.
+type checkGen struct {
+	doc       *document
+	pkgName   string
+	checkPath string
+	buf       bytes.Buffer
+	segs      []segment
+}
+
+// genImports are imported by generated code, so they can be used in the file without importing them
+var genImports = []struct{ path, name, use string }{
+	{"fmt", "fmt", "Stringer"},
+	{"reflect", "reflect", "Type"},
+	{"github.com/vugu/vjson", "vjson", "RawMessage"},
+	{"github.com/vugu/vugu", "vugu", "Builder"},
+	{"github.com/vugu/vugu/js", "js", "Value"},
+}
+
+func (g *checkGen) generate(root *vuguElem, structType string) []byte {
+
+	var script *vuguElem
+	var walk func(e *vuguElem)
+	walk = func(e *vuguElem) {
+		for _, ch := range e.children {
+			if script == nil && strings.EqualFold(ch.name, "script") {
+				if a := ch.attr("type"); a != nil && a.val == "application/x-go" {
+					script = ch
+				}
+			}
+			walk(ch)
+		}
+	}
+	walk(root)
+
+	// imports already in the file are not repeated
+	names := make(map[string]string)
+	if script != nil {
+		if f, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+script.text, parser.ImportsOnly); err == nil {
+			for _, spec := range f.Imports {
+				p, _ := strconv.Unquote(spec.Path.Value)
+				names[p] = ""
+				if spec.Name != nil {
+					names[p] = spec.Name.Name
+				}
+			}
+		}
+	}
+
+	fmt.Fprintf(&g.buf, "package %s\n\n", g.pkgName)
+	for _, imp := range genImports {
+		if _, ok := names[imp.path]; !ok {
+			fmt.Fprintf(&g.buf, "import %s %q\n", imp.name, imp.path)
+		}
+	}
+
+	if script != nil {
+		g.code(script.textOff, script.text, true)
+	}
+
+	fmt.Fprintf(&g.buf, "\n/*line %s:1:1*/func (c *%s) vgcheck() {\n", g.checkPath, structType)
+	fmt.Fprintf(&g.buf, "var vgin *vugu.BuildIn\n_ = vgin\n")
+	for _, e := range root.children {
+		g.elem(e)
+	}
+	fmt.Fprintf(&g.buf, "}\n\n")
+
+	for _, imp := range genImports {
+		name := imp.name
+		if n := names[imp.path]; n != "" {
+			name = n
+		}
+		if name != "_" && name != "." {
+			fmt.Fprintf(&g.buf, "var _ %s.%s\n", name, imp.use)
+		}
+	}
+
+	return g.buf.Bytes()
+}
+
+// w writes generated code
+func (g *checkGen) w(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// at writes a line directive for the position at off in the document
+func (g *checkGen) at(off int) {
+	line, col := g.doc.lineCol(off)
+	fmt.Fprintf(&g.buf, "/*line %s:%d:%d*/", g.doc.path, line, col)
+}
+
+// code writes s, from off in the document.  If exact is true, s is as written in the document and
+// is recorded so positions in the document can be found in the code.
+func (g *checkGen) code(off int, s string, exact bool) {
+	g.at(off)
+	if exact {
+		g.segs = append(g.segs, segment{docOff: off, checkOff: g.buf.Len(), n: len(s)})
+	}
+	g.buf.WriteString(s)
+}
+
+// expr writes the value of a
+func (g *checkGen) expr(a *vuguAttr) {
+	g.code(a.valOff, a.val, a.exact)
+}
+
+func (g *checkGen) elem(e *vuguElem) {
+
+	name := strings.ToLower(e.name)
+	switch name {
+	case "script", "style", "link":
+		return
+	}
+
+	closers := 0
+
+	for i := range e.attrs {
+		if a := &e.attrs[i]; strings.HasPrefix(strings.ToLower(a.key), "vg-for") {
+			g.forClause(a)
+			closers++
+			break
+		}
+	}
+
+	if a := e.attr("vg-if"); a != nil {
+		g.w("if ")
+		g.expr(a)
+		g.w(" {\n")
+		closers++
+	}
+
+	g.w("{\n")
+	closers++
+
+	switch {
+	case e.isComponent():
+		g.component(e)
+	case name == "vg-comp":
+		if a := e.attr("expr"); a != nil {
+			g.w("var _ vugu.Builder = ")
+			g.expr(a)
+			g.w("\n")
+		}
+	default:
+		g.elemAttrs(e)
+	}
+
+	for _, ch := range e.children {
+		g.elem(ch)
+	}
+
+	g.w("%s", strings.Repeat("}\n", closers))
+}
+
+// forClause writes the for statement for a vg-for attribute, and uses the iteration variables
+func (g *checkGen) forClause(a *vuguAttr) {
+
+	val := strings.TrimLeftFunc(a.val, unicode.IsSpace)
+	trimmed := len(a.val) - len(val)
+	val = strings.TrimRightFunc(val, unicode.IsSpace)
+	exact := a.exact
+
+	var vars []string
+	if !strings.Contains(val, ":=") {
+		// `w` is short for `key, value := range w`
+		g.w("for key, value := range ")
+		vars = []string{"key", "value"}
+	} else {
+		g.w("for ")
+		for _, v := range strings.Split(val[:strings.Index(val, ":=")], ",") {
+			vars = append(vars, strings.TrimSpace(v))
+		}
+	}
+	g.code(a.valOff+trimmed, val, exact)
+	g.w(" {\n")
+	for _, v := range vars {
+		if v != "_" && v != "" {
+			g.w("_ = %s\n", v)
+		}
+	}
+}
+
+// elemAttrs writes the code for the attributes of a regular element
+func (g *checkGen) elemAttrs(e *vuguElem) {
+	for i := range e.attrs {
+		a := &e.attrs[i]
+		key := strings.ToLower(a.key)
+		switch {
+		case key == "vg-if", key == "vg-var", strings.HasPrefix(key, "vg-for"):
+		case key == "vg-key", key == "vg-html", key == "vg-content":
+			g.w("_ = ")
+			g.expr(a)
+			g.w("\n")
+		case key == "vg-attr", key == ":":
+			g.w("var _ vugu.VGAttributeLister = ")
+			g.expr(a)
+			g.w("\n")
+		case key == "vg-js-create", key == "vg-js-populate":
+			g.w("_ = func(value js.Value) {\n")
+			g.expr(a)
+			g.w("\n}\n")
+		case strings.HasPrefix(key, ":"), strings.HasPrefix(key, "."):
+			g.w("_ = ")
+			g.expr(a)
+			g.w("\n")
+		case strings.HasPrefix(key, "@"):
+			g.w("_ = func(event vugu.DOMEvent) {\n")
+			g.expr(a)
+			g.w("\n}\n")
+		}
+	}
+}
+
+// component writes the code for a component tag: its fields are set from the attributes
+func (g *checkGen) component(e *vuguElem) {
+
+	parts := strings.SplitN(e.name, ":", 2)
+	pkgPrefix := parts[0] + "."
+
+	g.w("var vgcomp *")
+	if parts[0] == g.pkgName {
+		pkgPrefix = ""
+	} else {
+		g.code(e.nameOff, parts[0], true)
+		g.w(".")
+	}
+	g.code(e.nameOff+len(parts[0])+1, parts[1], true)
+	g.w("\n_ = vgcomp\n")
+
+	if a := e.attr("vg-var"); a != nil {
+		g.w("var ")
+		g.expr(a)
+		g.w(" = vgcomp\n_ = %s\n", a.val)
+	}
+
+	if a := e.attr("vg-key"); a != nil {
+		g.w("_ = ")
+		g.expr(a)
+		g.w("\n")
+	}
+
+	for i := range e.attrs {
+		a := &e.attrs[i]
+		switch {
+		case strings.HasPrefix(strings.ToLower(a.key), "vg-"), strings.HasPrefix(a.key, "."):
+		case strings.HasPrefix(a.key, ":"):
+			g.field(a, a.key[1:], a.keyOff+1)
+			g.expr(a)
+			g.w("\n")
+		case strings.HasPrefix(a.key, "@"):
+			k := a.key[1:]
+			g.w("vgcomp.")
+			g.code(a.keyOff+1, k, true)
+			g.w(" = %s%sFunc(func(event %s%sEvent) {\n", pkgPrefix, k, pkgPrefix, k)
+			g.expr(a)
+			g.w("\n})\n")
+		default:
+			g.field(a, a.key, a.keyOff)
+			g.w("%q\n", a.val)
+		}
+	}
+}
+
+// field writes the start of the assignment of an attribute to a component: to the field if the name
+// starts with an upper case letter, otherwise to AttrMap.
+func (g *checkGen) field(a *vuguAttr, name string, off int) {
+	g.w("vgcomp.")
+	if r := []rune(name); len(r) > 0 && unicode.IsUpper(r[0]) {
+		g.code(off, name, true)
+		g.w(" = ")
+		return
+	}
+	g.code(a.keyOff, "AttrMap", false)
+	g.w("[%q] = ", name)
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]*types.TypeName) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
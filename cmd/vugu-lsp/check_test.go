@@ -0,0 +1,66 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "TestCheck")
+	assert.NoError(err)
+	defer os.RemoveAll(tmpDir)
+
+	dir, err := filepath.Abs(filepath.Join("testdata", "check"))
+	assert.NoError(err)
+	path := filepath.Join(dir, "root.vugu")
+
+	c := newChecker(tmpDir)
+
+	// a type error in an attribute is reported where it is in the .vugu file
+	text := "<div>\n\t<span vg-content='c.Nmae'></span>\n\t<p vg-if='c.Name != \"\"' vg-content='c.Name'></p>\n</div>\n"
+	r := c.check(newDocument(pathToURI(path), path, 1, text))
+	assert.Equal("check", r.pkgName)
+	if assert.Len(r.diags, 1) {
+		d := r.diags[0]
+		assert.Equal("go", d.Source)
+		assert.Contains(d.Message, "Nmae")
+		assert.Equal(Range{Start: Position{1, 21}, End: Position{1, 25}}, d.Range)
+	}
+
+	// a code generator error is reported at the element it is about
+	text = "<div>\n\t<p>x</p>\n\t<vg-portal></vg-portal>\n</div>\n"
+	r = c.check(newDocument(pathToURI(path), path, 2, text))
+	if assert.Len(r.diags, 1) {
+		d := r.diags[0]
+		assert.Equal("vugugen", d.Source)
+		assert.True(strings.HasPrefix(d.Message, "vg-portal must have"), d.Message)
+		assert.Equal(Position{2, 2}, d.Range.Start)
+	}
+
+}
+
+func TestGenErrorOffset(t *testing.T) {
+
+	assert := assert.New(t)
+
+	text := "<div>\n<vg-comp></vg-comp>\n<x:y:z></x:y:z>\n<p vg-for.fast='c.L'></p>\n</div>\n<span></span>\n"
+	root := parseVugu(text)
+	at := func(msg string) string {
+		off := genErrorOffset(text, root, msg)
+		return text[off : strings.IndexAny(text[off:], "> =")+off]
+	}
+
+	assert.Equal("vg-comp", at("vg-comp must have an `expr` attribute with a Go expression in it"))
+	assert.Equal("x:y:z", at(`invalid component tag name "x:y:z" must contain exactly one colon`))
+	assert.Equal("vg-for.fast", at(`option "fast" unknown`))
+	assert.Equal("span", at("Found more than one top level element: span"))
+	assert.Equal(0, genErrorOffset(text, root, "something else"))
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// What is being completed
+const (
+	completeNone = iota
+	completeTag  // a tag name
+	completeAttr // an attribute name
+)
+
+// completionContext is where in the markup the cursor is.
+type completionContext struct {
+	kind  int
+	start int    // offset of the start of the word being completed
+	tag   string // the tag name, for completeAttr
+}
+
+// states of the scan in completionAt
+const (
+	scanText = iota
+	scanComment
+	scanRaw     // in a script or style element
+	scanTagName // after <
+	scanAttrs   // between attributes
+	scanAttrName
+	scanAfterEq // after an attribute name and =
+	scanQuoted  // in a quoted attribute value
+	scanUnquoted
+)
+
+// completionAt scans text up to off to find out what is being typed there.
+func completionAt(text string, off int) completionContext {
+
+	state := scanText
+	var nameStart, attrStart int
+	var tag string
+	var quote byte
+
+	isSpace := func(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' }
+
+	for i := 0; i < off; i++ {
+		c := text[i]
+		switch state {
+
+		case scanText:
+			if strings.HasPrefix(text[i:], "<!--") {
+				state = scanComment
+				i += 3
+			} else if c == '<' {
+				state = scanTagName
+				nameStart = i + 1
+			}
+
+		case scanComment:
+			if strings.HasPrefix(text[i:], "-->") {
+				state = scanText
+				i += 2
+			}
+
+		case scanRaw:
+			end := "</" + tag
+			if i+len(end) <= len(text) && strings.EqualFold(text[i:i+len(end)], end) {
+				state = scanTagName
+				nameStart = i + 1
+			}
+
+		case scanTagName:
+			if isSpace(c) || c == '/' && i > nameStart || c == '>' {
+				tag = text[nameStart:i]
+				state = scanAttrs
+				i-- // look at c again
+			}
+
+		case scanAttrs, scanAttrName:
+			switch {
+			case c == '>':
+				state = scanText
+				if t := strings.ToLower(tag); t == "script" || t == "style" {
+					state = scanRaw
+				}
+			case c == '=':
+				state = scanAfterEq
+			case isSpace(c) || c == '/':
+				state = scanAttrs
+			case state == scanAttrs:
+				state = scanAttrName
+				attrStart = i
+			}
+
+		case scanAfterEq:
+			switch {
+			case c == '"' || c == '\'':
+				state = scanQuoted
+				quote = c
+			case c == '>':
+				state = scanAttrs
+				i--
+			case !isSpace(c):
+				state = scanUnquoted
+			}
+
+		case scanQuoted:
+			if c == quote {
+				state = scanAttrs
+			}
+
+		case scanUnquoted:
+			if isSpace(c) || c == '>' {
+				state = scanAttrs
+				i--
+			}
+		}
+	}
+
+	switch state {
+	case scanTagName:
+		if !strings.HasPrefix(text[nameStart:off], "/") {
+			return completionContext{kind: completeTag, start: nameStart}
+		}
+	case scanAttrs:
+		return completionContext{kind: completeAttr, start: off, tag: tag}
+	case scanAttrName:
+		return completionContext{kind: completeAttr, start: attrStart, tag: tag}
+	}
+	return completionContext{kind: completeNone}
+}
+
+// directives are the attributes handled by the code generator
+var directives = []string{"vg-if", "vg-for", "vg-key", "vg-var", "vg-content", "vg-html", "vg-attr", "vg-js-create", "vg-js-populate"}
+
+// specialTags are the elements handled by the code generator
+var specialTags = []string{"vg-comp", "vg-template", "vg-slot"}
+
+// completions returns the completion items for position off in doc.  r is a check of the document,
+// it may be of an earlier version of it.
+func completions(doc *document, off int, r *checkResult) []CompletionItem {
+
+	cc := completionAt(doc.text, off)
+	edit := func(text string) *TextEdit {
+		return &TextEdit{Range: Range{Start: doc.position(cc.start), End: doc.position(off)}, NewText: text}
+	}
+
+	items := []CompletionItem{}
+
+	switch cc.kind {
+
+	case completeTag:
+		if r != nil {
+			comps := r.components()
+			for _, name := range sortedKeys(comps) {
+				items = append(items, CompletionItem{Label: name, Kind: completionClass, Detail: comps[name].Type().String(), TextEdit: edit(name)})
+			}
+		}
+		for _, name := range specialTags {
+			items = append(items, CompletionItem{Label: name, Kind: completionKeyword, TextEdit: edit(name)})
+		}
+
+	case completeAttr:
+		var tn *types.TypeName
+		if r != nil {
+			tn = r.component(cc.tag)
+		}
+		if tn != nil {
+			for _, f := range componentFields(tn) {
+				if isEventField(tn, f) {
+					items = append(items, CompletionItem{Label: "@" + f.Name(), Kind: completionEvent, Detail: f.Type().String(), TextEdit: edit("@" + f.Name())})
+					continue
+				}
+				items = append(items, CompletionItem{Label: ":" + f.Name(), Kind: completionField, Detail: f.Type().String(), TextEdit: edit(":" + f.Name())})
+			}
+		}
+		for _, name := range directives {
+			items = append(items, CompletionItem{Label: name, Kind: completionKeyword, TextEdit: edit(name)})
+		}
+	}
+
+	return items
+}
+
+// componentFields returns the exported fields of a component, including those of embedded structs,
+// ordered by name.
+func componentFields(tn *types.TypeName) []*types.Var {
+
+	var ret []*types.Var
+	seen := make(map[string]bool)
+
+	var add func(t types.Type, depth int)
+	add = func(t types.Type, depth int) {
+		if p, ok := t.(*types.Pointer); ok {
+			t = p.Elem()
+		}
+		st, ok := t.Underlying().(*types.Struct)
+		if !ok || depth > 5 {
+			return
+		}
+		for i := 0; i < st.NumFields(); i++ {
+			f := st.Field(i)
+			if f.Exported() && !seen[f.Name()] {
+				seen[f.Name()] = true
+				ret = append(ret, f)
+			}
+			if f.Embedded() {
+				add(f.Type(), depth+1)
+			}
+		}
+	}
+	add(tn.Type(), 0)
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name() < ret[j].Name() })
+	return ret
+}
+
+// isEventField returns true if the field f of a component is for an event, i.e. its package has the
+// types the code generator uses for setting it from an @ attribute: f.Name()+"Func" and f.Name()+"Event".
+func isEventField(tn *types.TypeName, f *types.Var) bool {
+	scope := tn.Pkg().Scope()
+	return scope.Lookup(f.Name()+"Func") != nil && scope.Lookup(f.Name()+"Event") != nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletionAt(t *testing.T) {
+
+	// | is the cursor
+	tests := []struct {
+		text string
+		want completionContext
+	}{
+		{"<di|", completionContext{kind: completeTag, start: 1}},
+		{"<div>text|", completionContext{kind: completeNone}},
+		{"<div |", completionContext{kind: completeAttr, start: 5, tag: "div"}},
+		{"<div cl|", completionContext{kind: completeAttr, start: 5, tag: "div"}},
+		{"<main:Counter :Val|", completionContext{kind: completeAttr, start: 14, tag: "main:Counter"}},
+		{"<div class='a b|", completionContext{kind: completeNone}},
+		{"<div class='a' |", completionContext{kind: completeAttr, start: 15, tag: "div"}},
+		{"<div class=a |", completionContext{kind: completeAttr, start: 13, tag: "div"}},
+		{"</di|", completionContext{kind: completeNone}},
+		{"<!-- <di|", completionContext{kind: completeNone}},
+		{"<!-- x --><di|", completionContext{kind: completeTag, start: 11}},
+		{"<script>if (a <b|", completionContext{kind: completeNone}},
+		{"<script>x</script><p|", completionContext{kind: completeTag, start: 19}},
+		{"<br/><sp|", completionContext{kind: completeTag, start: 6}},
+	}
+
+	for _, tt := range tests {
+		off := strings.Index(tt.text, "|")
+		text := tt.text[:off] + tt.text[off+1:]
+		assert.Equal(t, tt.want, completionAt(text, off), tt.text)
+	}
+}
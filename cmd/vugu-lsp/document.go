@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/vugu/vugu/internal/htmlx"
+)
+
+// document is the text of a file with its line index, for converting between byte offsets and LSP positions.
+type document struct {
+	uri     string
+	path    string
+	version int
+	text    string
+	lines   []int // offset of the start of each line
+}
+
+func newDocument(uri, path string, version int, text string) *document {
+	d := &document{uri: uri, path: path, version: version, text: text, lines: []int{0}}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			d.lines = append(d.lines, i+1)
+		}
+	}
+	return d
+}
+
+// lineCol returns the one-based line and byte column of off.
+func (d *document) lineCol(off int) (int, int) {
+	i := sort.SearchInts(d.lines, off+1) - 1
+	return i + 1, off - d.lines[i] + 1
+}
+
+// offsetAt returns the byte offset of the one-based line and byte column, clamped to the document.
+func (d *document) offsetAt(line, col int) int {
+	if line < 1 {
+		return 0
+	}
+	if line > len(d.lines) {
+		return len(d.text)
+	}
+	off := d.lines[line-1] + col - 1
+	if end := d.lineEnd(line - 1); off > end {
+		off = end
+	}
+	return off
+}
+
+// lineEnd returns the offset of the end of the zero-based line i, before its newline.
+func (d *document) lineEnd(i int) int {
+	if i+1 < len(d.lines) {
+		return d.lines[i+1] - 1
+	}
+	return len(d.text)
+}
+
+// position converts a byte offset to an LSP position.
+func (d *document) position(off int) Position {
+	if off > len(d.text) {
+		off = len(d.text)
+	}
+	line, _ := d.lineCol(off)
+	n := 0
+	for _, r := range d.text[d.lines[line-1]:off] {
+		n += len(utf16.Encode([]rune{r}))
+	}
+	return Position{Line: line - 1, Character: n}
+}
+
+// offset converts an LSP position to a byte offset, clamped to the document.
+func (d *document) offset(p Position) int {
+	if p.Line < 0 {
+		return 0
+	}
+	if p.Line >= len(d.lines) {
+		return len(d.text)
+	}
+	start, end := d.lines[p.Line], d.lineEnd(p.Line)
+	n := 0
+	for i, r := range d.text[start:end] {
+		if n >= p.Character {
+			return start + i
+		}
+		n += len(utf16.Encode([]rune{r}))
+	}
+	return end
+}
+
+// rangeOf returns the range from off to the end of the word there, or a single character if there is no word.
+func (d *document) rangeOf(off int) Range {
+	end := off
+	for end < len(d.text) && isWordByte(d.text[end]) {
+		end++
+	}
+	if end == off && end < len(d.text) && d.text[end] != '\n' {
+		_, n := utf8.DecodeRuneInString(d.text[end:])
+		end += n
+	}
+	return Range{Start: d.position(off), End: d.position(end)}
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// uriToPath converts a file URI to a path.
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	p := u.Path
+	if runtime.GOOS == "windows" {
+		p = strings.TrimPrefix(p, "/") // "/C:/dir" to "C:/dir"
+	}
+	return filepath.FromSlash(p)
+}
+
+// pathToURI converts a path to a file URI.
+func pathToURI(p string) string {
+	p = filepath.ToSlash(p)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return (&url.URL{Scheme: "file", Path: p}).String()
+}
+
+// vuguElem is an element in a .vugu file.
+type vuguElem struct {
+	name     string // as written, e.g. "main:Counter"
+	nameOff  int
+	attrs    []vuguAttr
+	children []*vuguElem
+	text     string // contents of a script or style element
+	textOff  int
+}
+
+// vuguAttr is an attribute of an element.
+type vuguAttr struct {
+	key    string // as written, e.g. ":Value"
+	keyOff int
+	val    string // with character references replaced
+	valOff int    // start of the value as written, after the quote
+	exact  bool   // val is the value as written, so offsets in one are offsets in the other
+}
+
+// attr returns the attribute with key, ignoring case, or nil
+func (e *vuguElem) attr(key string) *vuguAttr {
+	for i := range e.attrs {
+		if strings.EqualFold(e.attrs[i].key, key) {
+			return &e.attrs[i]
+		}
+	}
+	return nil
+}
+
+// isComponent returns true for component tags like "pkg:Comp"
+func (e *vuguElem) isComponent() bool {
+	return strings.Contains(e.name, ":")
+}
+
+// voidElements have no end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// parseVugu returns an element which holds the top level elements of a .vugu file.  It is forgiving
+// about mismatched tags, the formatter reports those.
+func parseVugu(text string) *vuguElem {
+
+	root := &vuguElem{}
+	stack := []*vuguElem{root}
+
+	z := htmlx.NewTokenizer(bytes.NewReader([]byte(text)))
+	off := 0
+
+	for {
+		tt := z.Next()
+		if tt == htmlx.ErrorToken {
+			// at the end, or an error which the formatter reports, either way keep what we have
+			return root
+		}
+
+		// Raw must be copied before calling Token, which lower-cases tag and attribute names in place
+		raw := string(z.Raw())
+		spans := z.RawAttrSpans()
+		tok := z.Token()
+		top := stack[len(stack)-1]
+
+		switch tt {
+
+		case htmlx.StartTagToken, htmlx.SelfClosingTagToken:
+			nameEnd := 1
+			for nameEnd < len(raw) && !strings.ContainsRune(" \t\r\n\f/>", rune(raw[nameEnd])) {
+				nameEnd++
+			}
+			e := &vuguElem{name: raw[1:nameEnd], nameOff: off + 1}
+			for i, sp := range spans {
+				a := vuguAttr{key: raw[sp[0]:sp[1]], keyOff: off + sp[0], valOff: off + sp[2]}
+				if i < len(tok.Attr) {
+					a.val = tok.Attr[i].Val
+				}
+				a.exact = raw[sp[2]:sp[3]] == a.val
+				e.attrs = append(e.attrs, a)
+			}
+			top.children = append(top.children, e)
+			if tt == htmlx.StartTagToken && !voidElements[strings.ToLower(e.name)] {
+				stack = append(stack, e)
+			}
+
+		case htmlx.EndTagToken:
+			for i := len(stack) - 1; i > 0; i-- {
+				if strings.EqualFold(stack[i].name, tok.Data) {
+					stack = stack[:i]
+					break
+				}
+			}
+
+		case htmlx.TextToken:
+			switch strings.ToLower(top.name) {
+			case "script", "style":
+				if top.text == "" {
+					top.textOff = off
+				}
+				top.text += raw
+			}
+		}
+
+		off += len(raw)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocumentPosition(t *testing.T) {
+
+	assert := assert.New(t)
+
+	// "é" is two bytes and one UTF-16 unit, "😀" is four bytes and two UTF-16 units
+	doc := newDocument("file:///x.vugu", "/x.vugu", 1, "ab\né😀x\n")
+
+	assert.Equal(Position{Line: 0, Character: 0}, doc.position(0))
+	assert.Equal(Position{Line: 0, Character: 2}, doc.position(2))
+	assert.Equal(Position{Line: 1, Character: 0}, doc.position(3))
+	assert.Equal(Position{Line: 1, Character: 1}, doc.position(5))
+	assert.Equal(Position{Line: 1, Character: 3}, doc.position(9))
+	assert.Equal(Position{Line: 2, Character: 0}, doc.position(11))
+	assert.Equal(Position{Line: 2, Character: 0}, doc.position(100))
+
+	assert.Equal(0, doc.offset(Position{Line: 0, Character: 0}))
+	assert.Equal(5, doc.offset(Position{Line: 1, Character: 1}))
+	assert.Equal(9, doc.offset(Position{Line: 1, Character: 3}))
+	assert.Equal(10, doc.offset(Position{Line: 1, Character: 50}), "clamped to the end of the line")
+	assert.Equal(0, doc.offset(Position{Line: -1}))
+	assert.Equal(11, doc.offset(Position{Line: 5}))
+
+	for _, off := range []int{0, 1, 2, 3, 5, 9, 10} {
+		assert.Equal(off, doc.offset(doc.position(off)), "offset %d", off)
+	}
+
+	line, col := doc.lineCol(9)
+	assert.Equal(2, line)
+	assert.Equal(7, col)
+	assert.Equal(9, doc.offsetAt(2, 7))
+	assert.Equal(10, doc.offsetAt(2, 50))
+
+	assert.Equal(Range{Start: Position{0, 0}, End: Position{0, 2}}, doc.rangeOf(0))
+	assert.Equal(Range{Start: Position{1, 3}, End: Position{1, 4}}, doc.rangeOf(9))
+}
+
+func TestParseVugu(t *testing.T) {
+
+	assert := assert.New(t)
+
+	text := "<div>\n\t<main:Counter :Value='c.N' vg-if=\"c.Show\"></main:Counter>\n</div>"
+	root := parseVugu(text)
+
+	if assert.Len(root.children, 1) && assert.Len(root.children[0].children, 1) {
+		e := root.children[0].children[0]
+		assert.Equal("main:Counter", e.name)
+		assert.Equal(text[e.nameOff:e.nameOff+len(e.name)], e.name)
+		assert.True(e.isComponent())
+		if a := e.attr(":value"); assert.NotNil(a) {
+			assert.Equal(":Value", a.key)
+			assert.Equal("c.N", a.val)
+			assert.Equal("c.N", text[a.valOff:a.valOff+3])
+			assert.True(a.exact)
+		}
+		if a := e.attr("vg-if"); assert.NotNil(a) {
+			assert.Equal("c.Show", text[a.valOff:a.valOff+6])
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// conn reads and writes JSON-RPC messages framed with a Content-Length header, as used by LSP.
+type conn struct {
+	r  *textproto.Reader
+	w  io.Writer
+	mu sync.Mutex // protects w
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{r: textproto.NewReader(bufio.NewReader(r)), w: w}
+}
+
+// read returns the next message
+func (c *conn) read() (*rpcMessage, error) {
+
+	h, err := c.r.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(h.Get("Content-Length"))
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", h.Get("Content-Length"))
+	}
+
+	b := make([]byte, n)
+	_, err = io.ReadFull(c.r.R, b)
+	if err != nil {
+		return nil, err
+	}
+
+	var m rpcMessage
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		return nil, &rpcError{Code: codeParseError, Message: err.Error()}
+	}
+	return &m, nil
+}
+
+// write sends m
+func (c *conn) write(m *rpcMessage) error {
+
+	m.JSONRPC = "2.0"
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err = fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(b), b)
+	return err
+}
+
+// reply answers the request with id, with result or err
+func (c *conn) reply(id *json.RawMessage, result interface{}, err error) error {
+	m := &rpcMessage{ID: id}
+	switch e := err.(type) {
+	case nil:
+		if result == nil {
+			// a request that succeeded needs a result, even if it is null
+			result = json.RawMessage("null")
+		}
+		m.Result = result
+	case *rpcError:
+		m.Error = e
+	default:
+		m.Error = &rpcError{Code: codeRequestFailed, Message: err.Error()}
+	}
+	return c.write(m)
+}
+
+// notify sends a notification
+func (c *conn) notify(method string, params interface{}) error {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return c.write(&rpcMessage{Method: method, Params: b})
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConn(t *testing.T) {
+
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+	c := newConn(strings.NewReader(""), &out)
+
+	id := json.RawMessage("1")
+	assert.NoError(c.reply(&id, nil, nil))
+	assert.Equal("Content-Length: 38\r\n\r\n"+`{"jsonrpc":"2.0","id":1,"result":null}`, out.String())
+	out.Reset()
+
+	assert.NoError(c.reply(&id, nil, errors.New("bad")))
+	assert.NoError(c.notify("textDocument/publishDiagnostics", map[string]int{"a": 1}))
+
+	// what was written can be read back
+	r := newConn(&out, ioutil.Discard)
+	m, err := r.read()
+	assert.NoError(err)
+	if assert.NotNil(m.Error) {
+		assert.Equal(codeRequestFailed, m.Error.Code)
+		assert.Equal("bad", m.Error.Message)
+	}
+	m, err = r.read()
+	assert.NoError(err)
+	assert.Equal("textDocument/publishDiagnostics", m.Method)
+	assert.JSONEq(`{"a":1}`, string(m.Params))
+	_, err = r.read()
+	assert.Equal(io.EOF, err)
+}
+
+func TestConnRead(t *testing.T) {
+
+	assert := assert.New(t)
+
+	body := `{"jsonrpc":"2.0","id":"x","method":"initialize","params":{}}`
+	in := "Content-Length: " + strconv.Itoa(len(body)) + "\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8\r\n\r\n" + body +
+		"Content-Length: 3\r\n\r\n{x}" +
+		"Content-Length: nope\r\n\r\n"
+	c := newConn(strings.NewReader(in), ioutil.Discard)
+
+	m, err := c.read()
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal("initialize", m.Method)
+	assert.Equal(`"x"`, string(*m.ID))
+
+	_, err = c.read()
+	if assert.IsType(&rpcError{}, err) {
+		assert.Equal(codeParseError, err.(*rpcError).Code)
+	}
+
+	_, err = c.read()
+	assert.EqualError(err, `invalid Content-Length "nope"`)
+}
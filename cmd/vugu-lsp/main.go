@@ -0,0 +1,43 @@
+// vugu-lsp is a language server for .vugu files.  It speaks the Language Server Protocol over
+// stdin and stdout and provides:
+//
+//	diagnostics   errors from the formatter, the code generator and the type checker, at their
+//	              positions in the .vugu file
+//	completion    component tags (pkg:Comp) and their fields and events, and vg- directives
+//	definition    go to the declaration of what is used in a Go expression in the markup
+//	formatting    with vugufmt, using the settings in the project's vugu.json
+//
+// Log messages are written to stderr.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+func main() {
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: vugu-lsp\n\nRuns a language server for .vugu files on stdin and stdout.\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	log.SetPrefix("vugu-lsp: ")
+	log.SetOutput(os.Stderr)
+
+	// the code generator is run for its errors, its output is written here
+	tmpDir, err := ioutil.TempDir("", "vugu-lsp")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := newServer(newConn(os.Stdin, os.Stdout), newChecker(tmpDir))
+	code := s.run()
+
+	os.RemoveAll(tmpDir)
+	os.Exit(code)
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vugu/vugu/vugufmt"
+)
+
+// checkDelay is how long to wait after a change before checking a document, so typing is not
+// slowed down by a check for every key.
+const checkDelay = 300 * time.Millisecond
+
+// server handles the messages from a client.  Requests are answered in order on the goroutine
+// calling run, checks for diagnostics run in the background.
+type server struct {
+	conn    *conn
+	checker *checker
+
+	mu      sync.Mutex
+	docs    map[string]*document    // open documents by URI
+	results map[string]*checkResult // latest check of each document
+	typed   map[string]*checkResult // latest check of each document which got as far as type checking
+	timers  map[string]*time.Timer
+
+	shutdown bool
+}
+
+func newServer(c *conn, checker *checker) *server {
+	return &server{
+		conn:    c,
+		checker: checker,
+		docs:    make(map[string]*document),
+		results: make(map[string]*checkResult),
+		typed:   make(map[string]*checkResult),
+		timers:  make(map[string]*time.Timer),
+	}
+}
+
+// run handles messages until the client sends exit, and returns the exit code.
+func (s *server) run() int {
+	for {
+		m, err := s.conn.read()
+		if err != nil {
+			if rerr, ok := err.(*rpcError); ok {
+				s.conn.reply(nil, nil, rerr)
+				continue
+			}
+			if err != io.EOF {
+				log.Printf("error reading message: %v", err)
+			}
+			return 1
+		}
+
+		if m.Method == "exit" {
+			if s.shutdown {
+				return 0
+			}
+			return 1
+		}
+
+		if m.ID == nil {
+			s.notification(m)
+			continue
+		}
+
+		result, err := s.request(m)
+		err = s.conn.reply(m.ID, result, err)
+		if err != nil {
+			log.Printf("error writing reply: %v", err)
+			return 1
+		}
+	}
+}
+
+// request handles a request and returns its result
+func (s *server) request(m *rpcMessage) (interface{}, error) {
+	switch m.Method {
+
+	case "initialize":
+		var res InitializeResult
+		res.ServerInfo.Name = "vugu-lsp"
+		caps := &res.Capabilities
+		caps.TextDocumentSync.OpenClose = true
+		caps.TextDocumentSync.Change = 1
+		caps.TextDocumentSync.Save = true
+		caps.CompletionProvider.TriggerCharacters = []string{"<", ":", "@"}
+		caps.DefinitionProvider = true
+		caps.DocumentFormattingProvider = true
+		return res, nil
+
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/completion":
+		var p TextDocumentPositionParams
+		if err := unmarshalParams(m, &p); err != nil {
+			return nil, err
+		}
+		doc := s.doc(p.TextDocument.URI)
+		if doc == nil {
+			return nil, nil
+		}
+		r := s.result(doc)
+		if r == nil || r.pkg == nil {
+			// while typing the file often does not parse, use what was known before
+			s.mu.Lock()
+			r = s.typed[doc.uri]
+			s.mu.Unlock()
+		}
+		return CompletionList{Items: completions(doc, doc.offset(p.Position), r)}, nil
+
+	case "textDocument/definition":
+		var p TextDocumentPositionParams
+		if err := unmarshalParams(m, &p); err != nil {
+			return nil, err
+		}
+		doc := s.doc(p.TextDocument.URI)
+		if doc == nil {
+			return nil, nil
+		}
+		r := s.result(doc)
+		if r == nil {
+			return nil, nil
+		}
+		pos, ok := r.definition(doc.offset(p.Position))
+		if !ok {
+			return nil, nil
+		}
+		target := s.docForPath(pos.Filename)
+		if target == nil {
+			return nil, nil
+		}
+		off := target.offsetAt(pos.Line, pos.Column)
+		return []Location{{URI: pathToURI(pos.Filename), Range: Range{Start: target.position(off), End: target.position(off)}}}, nil
+
+	case "textDocument/formatting":
+		var p DocumentFormattingParams
+		if err := unmarshalParams(m, &p); err != nil {
+			return nil, err
+		}
+		doc := s.doc(p.TextDocument.URI)
+		if doc == nil {
+			return nil, nil
+		}
+		return format(doc)
+	}
+
+	return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + m.Method}
+}
+
+// notification handles a notification, those which are not known are ignored
+func (s *server) notification(m *rpcMessage) {
+	switch m.Method {
+
+	case "textDocument/didOpen":
+		var p DidOpenTextDocumentParams
+		if unmarshalParams(m, &p) == nil {
+			s.update(newDocument(p.TextDocument.URI, uriToPath(p.TextDocument.URI), p.TextDocument.Version, p.TextDocument.Text), 0)
+		}
+
+	case "textDocument/didChange":
+		var p DidChangeTextDocumentParams
+		if unmarshalParams(m, &p) == nil && len(p.ContentChanges) > 0 {
+			text := p.ContentChanges[len(p.ContentChanges)-1].Text
+			s.update(newDocument(p.TextDocument.URI, uriToPath(p.TextDocument.URI), p.TextDocument.Version, text), checkDelay)
+		}
+
+	case "textDocument/didSave":
+		var p DidSaveTextDocumentParams
+		if unmarshalParams(m, &p) == nil && strings.HasSuffix(p.TextDocument.URI, ".go") {
+			s.goFilesChanged()
+		}
+
+	case "workspace/didChangeWatchedFiles":
+		var p DidChangeWatchedFilesParams
+		if unmarshalParams(m, &p) == nil {
+			for _, c := range p.Changes {
+				if strings.HasSuffix(c.URI, ".go") && !strings.HasSuffix(c.URI, "_vgen.go") {
+					s.goFilesChanged()
+					break
+				}
+			}
+		}
+
+	case "textDocument/didClose":
+		var p DidCloseTextDocumentParams
+		if unmarshalParams(m, &p) == nil {
+			uri := p.TextDocument.URI
+			s.mu.Lock()
+			if t := s.timers[uri]; t != nil {
+				t.Stop()
+			}
+			delete(s.docs, uri)
+			delete(s.results, uri)
+			delete(s.typed, uri)
+			delete(s.timers, uri)
+			s.mu.Unlock()
+			s.publish(&checkResult{doc: &document{uri: uri}})
+		}
+	}
+}
+
+func unmarshalParams(m *rpcMessage, v interface{}) error {
+	err := json.Unmarshal(m.Params, v)
+	if err != nil {
+		log.Printf("invalid params for %s: %v", m.Method, err)
+		return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// doc returns the open document with uri, or nil
+func (s *server) doc(uri string) *document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.docs[uri]
+}
+
+// docForPath returns the open document for path, or else reads the file
+func (s *server) docForPath(path string) *document {
+	s.mu.Lock()
+	for _, doc := range s.docs {
+		if doc.path == path {
+			s.mu.Unlock()
+			return doc
+		}
+	}
+	s.mu.Unlock()
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Printf("error reading %s: %v", path, err)
+		return nil
+	}
+	return newDocument(pathToURI(path), path, 0, string(b))
+}
+
+// update stores a new version of a document and checks it after delay
+func (s *server) update(doc *document, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.docs[doc.uri] = doc
+	s.schedule(doc.uri, delay)
+}
+
+// schedule checks the document with uri after delay, unless it changes in the meantime.
+// s.mu must be held.
+func (s *server) schedule(uri string, delay time.Duration) {
+	if t := s.timers[uri]; t != nil {
+		t.Stop()
+	}
+	s.timers[uri] = time.AfterFunc(delay, func() {
+		doc := s.doc(uri)
+		if doc == nil {
+			return
+		}
+		if r := s.result(doc); r != nil {
+			s.publish(r)
+		}
+	})
+}
+
+// goFilesChanged makes the checker load packages again and checks all open documents
+func (s *server) goFilesChanged() {
+	s.checker.reset()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for uri := range s.docs {
+		delete(s.results, uri)
+		s.schedule(uri, checkDelay)
+	}
+}
+
+// result returns the check of doc, checking it if that has not been done yet.  It returns nil if
+// the document can not be checked, or has changed.
+func (s *server) result(doc *document) *checkResult {
+
+	s.mu.Lock()
+	r := s.results[doc.uri]
+	s.mu.Unlock()
+	if r != nil && r.doc == doc {
+		return r
+	}
+	if doc.path == "" || filepath.Ext(doc.path) != ".vugu" {
+		return nil
+	}
+
+	r = s.checker.check(doc)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.docs[doc.uri] != doc {
+		return nil
+	}
+	s.results[doc.uri] = r
+	if r.pkg != nil {
+		s.typed[doc.uri] = r
+	}
+	return r
+}
+
+// publish sends the diagnostics of a check to the client
+func (s *server) publish(r *checkResult) {
+	diags := r.diags
+	if diags == nil {
+		diags = []Diagnostic{}
+	}
+	err := s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: r.doc.uri, Version: r.doc.version, Diagnostics: diags})
+	if err != nil {
+		log.Printf("error publishing diagnostics: %v", err)
+	}
+}
+
+// format returns the edits which format doc with the settings in the project's vugu.json
+func format(doc *document) ([]TextEdit, error) {
+
+	cfg, _, err := vugufmt.FindConfig(filepath.Dir(doc.path))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = vugufmt.NewFormatter(cfg.Options()...).FormatHTML(doc.path, strings.NewReader(doc.text), &buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if buf.String() == doc.text {
+		return []TextEdit{}, nil
+	}
+	return []TextEdit{{
+		Range:   Range{Start: Position{}, End: doc.position(len(doc.text))},
+		NewText: buf.String(),
+	}}, nil
+}
@@ -0,0 +1,6 @@
+package check
+
+// Root is the component checked by TestCheck, its markup is in the test
+type Root struct {
+	Name string
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+
+	"github.com/vugu/vugu/distutil"
+)
+
+const defaultOutDir = "dist"
+
+func buildMain(args []string) {
+
+	dir, out, noGen, cfg, err := buildSetup(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !noGen {
+		err := runGen(dir, cfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	b := distutil.NewBuild(dir, out)
+	b.ParserGoPkgOpts = nil // already done above
+	if len(cfg.Build.Assets) > 0 {
+		b.Assets = cfg.Build.Assets
+	}
+	if len(cfg.Build.Pages) > 0 {
+		b.Pages = cfg.Build.Pages
+	}
+	b.Gzip = !cfg.Build.NoGzip
+	if cfg.Build.NoBrotli {
+		b.Brotli = nil
+	}
+
+	closeCompiler := func() {}
+	if cfg.tinygo() {
+		c, cl, err := newCompiler(dir, cfg, false)
+		if err != nil {
+			log.Fatal(err)
+		}
+		b.Compiler, closeCompiler = c, cl
+	}
+
+	m, err := b.Run()
+	closeCompiler()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	names := make([]string, 0, len(m.Files))
+	for name := range m.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		mf := m.Files[name]
+		fmt.Printf("%-24s %-40s %10d bytes\n", name, mf.Path, mf.Size)
+	}
+	fmt.Printf("Wrote %d files to %s\n", len(names), out)
+}
+
+// buildSetup parses the arguments of build and returns the project directory, the output directory,
+// whether to skip the code generator and the project's settings with the flags applied
+func buildSetup(args []string) (dir, out string, noGen bool, cfg *projectConfig, err error) {
+
+	fs := newFlagSet("build", "[dir]")
+	outDir := fs.String("o", "", "Output directory, relative to the current directory (default from "+configFileName+" or "+defaultOutDir+" in the project directory)")
+	tinygo := fs.Bool("tinygo", false, "Compile with TinyGo instead of Go")
+	skipGen := fs.Bool("no-gen", false, "Do not run the code generator first")
+	fs.Parse(args)
+
+	dir = projectDir(fs)
+	cfg, err = loadConfig(dir)
+	if err != nil {
+		return "", "", false, nil, err
+	}
+	if *tinygo {
+		cfg.Compiler = "tinygo"
+	}
+
+	out = *outDir
+	if out == "" {
+		out = cfg.Build.OutDir
+		if out == "" {
+			out = defaultOutDir
+		}
+		if !filepath.IsAbs(out) {
+			out = filepath.Join(dir, out)
+		}
+	}
+
+	return dir, out, *skipGen, cfg, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/vugu/vugu/devutil"
+)
+
+// projectCompiler is implemented by devutil.WasmCompiler and devutil.TinygoCompiler
+type projectCompiler interface {
+	devutil.Compiler
+	devutil.WasmExecJSer
+}
+
+// newCompiler returns the compiler for the project in dir and a function to release its resources.
+// If generate is true the code generator is run before each build by invoking the gen command of this
+// program, so that generate errors are reported the same way as build errors.
+func newCompiler(dir string, cfg *projectConfig, generate bool) (projectCompiler, func(), error) {
+
+	var genCmdFunc func() *exec.Cmd
+	if generate {
+		self, err := os.Executable()
+		if err != nil {
+			return nil, nil, err
+		}
+		genCmdFunc = func() *exec.Cmd {
+			args := []string{"gen"}
+			if cfg.tinygo() {
+				args = append(args, "-tinygo")
+			}
+			cmd := exec.Command(self, append(args, dir)...)
+			cmd.Dir = dir
+			return cmd
+		}
+	}
+
+	if cfg.tinygo() {
+		tc, err := devutil.NewTinygoCompiler()
+		if err != nil {
+			return nil, nil, err
+		}
+		tc.SetBuildDir(dir).SetTinygoArgs(cfg.TinygoArgs...).SetGenerateCmdFunc(genCmdFunc)
+		if cfg.TinygoNoDocker {
+			tc.NoDocker()
+		}
+		return tc, func() { tc.Close() }, nil
+	}
+
+	wc := devutil.NewWasmCompiler().SetBuildDir(dir).SetGenerateCmdFunc(genCmdFunc)
+	return wc, func() { wc.Close() }, nil
+}
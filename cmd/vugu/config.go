@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/vugu/vugu/gen"
+	"github.com/vugu/vugu/vugufmt"
+)
+
+// configFileName is the name of the project file in the project directory.
+const configFileName = "vugu.json"
+
+// projectConfig is the content of vugu.json.  Every setting is optional.  Example:
+//
+//	{
+//		"compiler": "tinygo",
+//		"gen": {"recursive": true},
+//		"serve": {"addr": "127.0.0.1:8844"},
+//		"build": {"out_dir": "dist", "assets": ["style.css"]},
+//		"fmt": {"imports": true, "sort_attrs": true, "max_width": 100}
+//	}
+type projectConfig struct {
+	Compiler       string   `json:"compiler"`         // "go" (the default) or "tinygo"
+	TinygoArgs     []string `json:"tinygo_args"`      // additional arguments to `tinygo build`
+	TinygoNoDocker bool     `json:"tinygo_no_docker"` // run tinygo directly instead of in Docker
+
+	Gen   genConfig      `json:"gen"`
+	Serve serveConfig    `json:"serve"`
+	Build buildConfig    `json:"build"`
+	Fmt   vugufmt.Config `json:"fmt"`
+}
+
+type genConfig struct {
+	Recursive   bool `json:"recursive"`    // also generate code in subdirectories
+	MergeSingle bool `json:"merge_single"` // merge the generated code for a package into a single file
+	SkipGoMod   bool `json:"skip_go_mod"`  // do not create go.mod if missing
+	SkipMainGo  bool `json:"skip_main"`    // do not create main_wasm.go if missing
+}
+
+type serveConfig struct {
+	Addr         string `json:"addr"`           // listen address, default ":8844"
+	Index        string `json:"index"`          // HTML file relative to the project directory served for paths without an extension, default is a page with live reload
+	NoLiveReload bool   `json:"no_live_reload"` // do not watch for changes and reload the page
+}
+
+type buildConfig struct {
+	OutDir   string   `json:"out_dir"`   // relative to the project directory, default "dist"
+	Assets   []string `json:"assets"`    // additional files written with content-hashed names
+	Pages    []string `json:"pages"`     // HTML files with references rewritten, default ["index.html"]
+	NoGzip   bool     `json:"no_gzip"`   // do not write .gz files
+	NoBrotli bool     `json:"no_brotli"` // do not write .br files (they are only written if the brotli command is installed)
+}
+
+// loadConfig reads vugu.json from dir.  If it does not exist the default configuration is returned.
+// Unknown settings are an error so typos don't go unnoticed.
+func loadConfig(dir string) (*projectConfig, error) {
+
+	cfg := &projectConfig{}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, configFileName))
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", filepath.Join(dir, configFileName), err)
+	}
+
+	switch cfg.Compiler {
+	case "", "go", "tinygo":
+	default:
+		return nil, fmt.Errorf("error reading %s: compiler must be \"go\" or \"tinygo\", not %q", filepath.Join(dir, configFileName), cfg.Compiler)
+	}
+	if cfg.Fmt.MaxWidth < 0 || cfg.Fmt.IndentSpaces < 0 {
+		return nil, fmt.Errorf("error reading %s: fmt max_width and indent_spaces must not be negative", filepath.Join(dir, configFileName))
+	}
+
+	return cfg, nil
+}
+
+// tinygo returns true if the project is compiled with TinyGo
+func (cfg *projectConfig) tinygo() bool {
+	return cfg.Compiler == "tinygo"
+}
+
+// genOpts returns the options for the code generator
+func (cfg *projectConfig) genOpts() *gen.ParserGoPkgOpts {
+	return &gen.ParserGoPkgOpts{
+		SkipGoMod:   cfg.Gen.SkipGoMod,
+		SkipMainGo:  cfg.Gen.SkipMainGo,
+		TinyGo:      cfg.tinygo(),
+		MergeSingle: cfg.Gen.MergeSingle,
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vugu/vugu/vugufmt"
+)
+
+// testProject returns a new project directory with config as its vugu.json, if not empty
+func testProject(t *testing.T, config string) string {
+	dir, err := ioutil.TempDir("", "vugu-cmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config != "" {
+		err = ioutil.WriteFile(filepath.Join(dir, configFileName), []byte(config), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadConfig(t *testing.T) {
+
+	cases := []struct {
+		config string
+		want   projectConfig
+		err    string
+	}{
+		{"", projectConfig{}, ""}, // no vugu.json
+		{`{}`, projectConfig{}, ""},
+		{`{"compiler": "tinygo", "tinygo_args": ["-no-debug"], "gen": {"recursive": true}}`,
+			projectConfig{Compiler: "tinygo", TinygoArgs: []string{"-no-debug"}, Gen: genConfig{Recursive: true}}, ""},
+		{`{"serve": {"addr": ":1"}, "build": {"out_dir": "out", "no_gzip": true}, "fmt": {"max_width": 80}}`,
+			projectConfig{Serve: serveConfig{Addr: ":1"}, Build: buildConfig{OutDir: "out", NoGzip: true}, Fmt: vugufmt.Config{MaxWidth: 80}}, ""},
+		{`{"compiler": "gccgo"}`, projectConfig{}, `compiler must be "go" or "tinygo"`},
+		{`{"fmt": {"indent_spaces": -2}}`, projectConfig{}, "must not be negative"},
+		{`{"fmt": {"max_widht": 100}}`, projectConfig{}, "max_widht"},
+		{`{"serve": {"adr": ":1"}}`, projectConfig{}, "adr"},
+		{`{"compiler": `, projectConfig{}, "error reading"},
+	}
+
+	for _, tc := range cases {
+		dir := testProject(t, tc.config)
+		cfg, err := loadConfig(dir)
+		os.RemoveAll(dir)
+		if tc.err != "" {
+			if assert.Error(t, err, tc.config) {
+				assert.Contains(t, err.Error(), tc.err, tc.config)
+			}
+			continue
+		}
+		if assert.NoError(t, err, tc.config) {
+			assert.Equal(t, tc.want, *cfg, tc.config)
+		}
+	}
+}
+
+func TestFlagsOverConfig(t *testing.T) {
+
+	assert := assert.New(t)
+
+	dir := testProject(t, `{"compiler": "go", "gen": {"merge_single": true}, "serve": {"addr": "127.0.0.1:1"}, "build": {"out_dir": "out"}}`)
+	defer os.RemoveAll(dir)
+	bare := testProject(t, "")
+	defer os.RemoveAll(bare)
+
+	// gen flags turn settings on, the rest comes from vugu.json
+	d, cfg, err := genSetup([]string{"-r", "-tinygo", dir})
+	assert.NoError(err)
+	assert.Equal(dir, d)
+	assert.Equal(genConfig{Recursive: true, MergeSingle: true}, cfg.Gen)
+	assert.True(cfg.tinygo())
+	assert.True(cfg.genOpts().TinyGo)
+	_, cfg, err = genSetup([]string{dir})
+	assert.NoError(err)
+	assert.Equal(genConfig{MergeSingle: true}, cfg.Gen)
+	assert.False(cfg.tinygo())
+
+	// build output is relative to the project, unless given with -o
+	_, out, noGen, cfg, err := buildSetup([]string{dir})
+	assert.NoError(err)
+	assert.Equal(filepath.Join(dir, "out"), out)
+	assert.False(noGen)
+	assert.False(cfg.tinygo())
+	_, out, noGen, cfg, err = buildSetup([]string{"-o", "elsewhere", "-no-gen", "-tinygo", dir})
+	assert.NoError(err)
+	assert.Equal("elsewhere", out)
+	assert.True(noGen)
+	assert.True(cfg.tinygo())
+	_, out, _, _, err = buildSetup([]string{bare})
+	assert.NoError(err)
+	assert.Equal(filepath.Join(bare, defaultOutDir), out)
+
+	// serve
+	_, cfg, err = serveSetup([]string{dir})
+	assert.NoError(err)
+	assert.Equal("127.0.0.1:1", cfg.Serve.Addr)
+	assert.False(cfg.Serve.NoLiveReload)
+	_, cfg, err = serveSetup([]string{"-addr", ":2", "-no-live-reload", dir})
+	assert.NoError(err)
+	assert.Equal(":2", cfg.Serve.Addr)
+	assert.True(cfg.Serve.NoLiveReload)
+	_, cfg, err = serveSetup([]string{bare})
+	assert.NoError(err)
+	assert.Equal(defaultServeAddr, cfg.Serve.Addr)
+
+	// errors in vugu.json are returned
+	assert.NoError(ioutil.WriteFile(filepath.Join(bare, configFileName), []byte(`{"gen": {"recursiv": true}}`), 0644))
+	_, _, err = genSetup([]string{bare})
+	assert.Error(err)
+	_, _, _, _, err = buildSetup([]string{bare})
+	assert.Error(err)
+	_, _, err = serveSetup([]string{bare})
+	assert.Error(err)
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"os"
+
+	"github.com/vugu/vugu/vugufmt"
+)
+
+// fmtMain runs vugufmt, the settings for each file are read from the "fmt" section of the nearest vugu.json
+func fmtMain(args []string) {
+	fs := newFlagSet("fmt", "[path ...]")
+	os.Exit(vugufmt.Command(fs, args, os.Stdin, os.Stdout, os.Stderr))
+}
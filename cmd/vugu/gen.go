@@ -0,0 +1,55 @@
+package main
+
+import (
+	"log"
+
+	"github.com/vugu/vugu/gen"
+)
+
+func genMain(args []string) {
+	dir, cfg, err := genSetup(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = runGen(dir, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// genSetup parses the arguments of gen and returns the project directory and its settings with the flags applied
+func genSetup(args []string) (string, *projectConfig, error) {
+
+	fs := newFlagSet("gen", "[dir]")
+	recursive := fs.Bool("r", false, "Run recursively on the directory and its subdirectories")
+	tinygo := fs.Bool("tinygo", false, "Generate code intended for compilation under TinyGo")
+	mergeSingle := fs.Bool("s", false, "Merge generated code for a package into a single file")
+	skipGoMod := fs.Bool("skip-go-mod", false, "Do not try to create go.mod as needed")
+	skipMainGo := fs.Bool("skip-main", false, "Do not try to create main_wasm.go as needed")
+	fs.Parse(args)
+
+	dir := projectDir(fs)
+	cfg, err := loadConfig(dir)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// flags can only turn settings on, the rest comes from the project file
+	cfg.Gen.Recursive = cfg.Gen.Recursive || *recursive
+	cfg.Gen.MergeSingle = cfg.Gen.MergeSingle || *mergeSingle
+	cfg.Gen.SkipGoMod = cfg.Gen.SkipGoMod || *skipGoMod
+	cfg.Gen.SkipMainGo = cfg.Gen.SkipMainGo || *skipMainGo
+	if *tinygo {
+		cfg.Compiler = "tinygo"
+	}
+
+	return dir, cfg, nil
+}
+
+// runGen runs the code generator on dir as configured
+func runGen(dir string, cfg *projectConfig) error {
+	if cfg.Gen.Recursive {
+		return gen.RunRecursive(dir, cfg.genOpts())
+	}
+	return gen.Run(dir, cfg.genOpts())
+}
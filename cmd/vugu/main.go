@@ -0,0 +1,94 @@
+// vugu is a command line tool for working on Vugu projects.  It combines the code generator,
+// a development server, production builds and the formatter in one command:
+//
+//	vugu new [-template name] [-module path] dir   create a new project from a built-in template
+//	vugu gen [-r] [dir]                            generate Go code from the .vugu files
+//	vugu serve [-addr host:port] [-tinygo] [dir]   run a development server which rebuilds and reloads on changes
+//	vugu build [-o dir] [-tinygo] [dir]            write a production build with hashed and precompressed files
+//	vugu fmt [-l] [-w] [-d] [path ...]             format .vugu files
+//
+// The directory defaults to the current one.  Settings are read from a vugu.json file in the project
+// directory if it exists, see projectConfig for what it can contain; flags take precedence over it.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string)
+}
+
+var commands = []command{
+	{"new", "create a new project from a built-in template", newMain},
+	{"gen", "generate Go code from the .vugu files", genMain},
+	{"serve", "run a development server which rebuilds and reloads on changes", serveMain},
+	{"build", "write a production build", buildMain},
+	{"fmt", "format .vugu files", fmtMain},
+}
+
+func main() {
+
+	log.SetFlags(0)
+	log.SetPrefix("vugu: ")
+
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := flag.Arg(0)
+	for _, c := range commands {
+		if c.name == name {
+			c.run(flag.Args()[1:])
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "vugu: unknown command %q\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: vugu <command> [flags] [arguments]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "\t%-8s %s\n", c.name, c.usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun 'vugu <command> -h' for the flags of a command.\n")
+}
+
+// newFlagSet returns a FlagSet for the named command with usage output that shows argsUsage
+func newFlagSet(name, argsUsage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: vugu %s [flags] %s\n", name, argsUsage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// projectDir returns the absolute path of the directory given as the only argument, or of the current directory
+func projectDir(fs *flag.FlagSet) string {
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if fs.NArg() == 1 {
+		dir = fs.Arg(0)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return dir
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const vuguModulePath = "github.com/vugu/vugu"
+
+func newMain(args []string) {
+
+	fs := newFlagSet("new", "dir")
+	tmplName := fs.String("template", "simple", "Project template, one of: "+strings.Join(templateNames(), ", "))
+	module := fs.String("module", "", "Module path for go.mod (default is the name of the directory)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	dir := projectDir(fs)
+
+	if *module == "" {
+		*module = filepath.Base(dir)
+	}
+	version := vuguVersion()
+
+	err := createProject(dir, *tmplName, *module, version)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// without a released version to require (e.g. this program was built from a local checkout)
+	// go.mod has no requirement for vugu, and module-aware builds do not add it themselves
+	getVugu := ""
+	if version == "" {
+		getVugu = "\tgo get " + vuguModulePath + "\n"
+	}
+
+	fmt.Printf("Created %s project in %s, to get started run:\n\n\tcd %s\n%s\tvugu serve\n\n", *tmplName, dir, fs.Arg(0), getVugu)
+}
+
+// createProject writes the files of the named template to dir, which must not exist or be empty.
+// The go.mod has the module path module and requires vugu at version, if it is not empty.
+func createProject(dir, tmplName, module, version string) error {
+
+	files, ok := projectTemplates[tmplName]
+	if !ok {
+		return fmt.Errorf("unknown template %q, must be one of: %s", tmplName, strings.Join(templateNames(), ", "))
+	}
+
+	// refuse to mix a new project into an existing one
+	existing, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("directory %s already exists and is not empty", dir)
+	}
+
+	data := map[string]string{
+		"Module":      module,
+		"VuguVersion": version,
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t, err := template.New(name).Parse(files[name])
+		if err != nil {
+			return fmt.Errorf("template %s/%s: %w", tmplName, name, err)
+		}
+		var buf bytes.Buffer
+		err = t.Execute(&buf, data)
+		if err != nil {
+			return fmt.Errorf("template %s/%s: %w", tmplName, name, err)
+		}
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		err = os.MkdirAll(filepath.Dir(p), 0755)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(p, buf.Bytes(), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// templateNames returns the names of the project templates in order
+func templateNames() []string {
+	names := make([]string, 0, len(projectTemplates))
+	for name := range projectTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// vuguVersion returns the version of the vugu module this program was built with, or an empty
+// string if it is not a usable version (e.g. built from a modified local checkout)
+func vuguVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	mods := append([]*debug.Module{&bi.Main}, bi.Deps...)
+	for _, m := range mods {
+		if m.Path == vuguModulePath && strings.HasPrefix(m.Version, "v") && !strings.Contains(m.Version, "+") {
+			return m.Version
+		}
+	}
+	return ""
+}
+
+// projectTemplates maps template names to the files they contain (slash-separated path to text/template source).
+var projectTemplates = map[string]map[string]string{
+	"simple": {
+		"go.mod":     goModTemplate,
+		".gitignore": gitignoreTemplate,
+		"vugu.json":  configTemplate,
+		"root.vugu":  simpleRootTemplate,
+	},
+	"counter": {
+		"go.mod":       goModTemplate,
+		".gitignore":   gitignoreTemplate,
+		"vugu.json":    configTemplate,
+		"root.vugu":    counterRootTemplate,
+		"counter.vugu": counterTemplate,
+	},
+}
+
+const goModTemplate = `module {{.Module}}
+
+go 1.14
+{{if .VuguVersion}}
+require ` + vuguModulePath + ` {{.VuguVersion}}
+{{end -}}
+`
+
+const gitignoreTemplate = `/dist/
+`
+
+const configTemplate = `{
+	"compiler": "go",
+	"gen": {"recursive": false},
+	"serve": {"addr": ":8844"},
+	"build": {"out_dir": "dist"}
+}
+`
+
+const simpleRootTemplate = `<div class="root">
+	<h1>Hello from Vugu</h1>
+	<p>Edit root.vugu and save, the page reloads with your changes.</p>
+</div>
+
+<script type="application/x-go">
+type Root struct {}
+</script>
+`
+
+const counterRootTemplate = `<div class="root">
+	<h1>Counters</h1>
+	<main:Counter Label="First"></main:Counter>
+	<main:Counter Label="Second" :Step="10"></main:Counter>
+</div>
+
+<script type="application/x-go">
+type Root struct {}
+</script>
+`
+
+const counterTemplate = `<div class="counter">
+	<span vg-content="c.Label"></span>:
+	<span vg-content="c.Count"></span>
+	<button @click="c.Inc(event)">+</button>
+</div>
+
+<style>
+.counter { margin: 8px 0; }
+</style>
+
+<script type="application/x-go">
+type Counter struct {
+	Label string
+	Step  int
+	Count int
+}
+
+func (c *Counter) Inc(event vugu.DOMEvent) {
+	if c.Step == 0 {
+		c.Count++
+		return
+	}
+	c.Count += c.Step
+}
+</script>
+`
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateProject(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "TestCreateProject")
+	assert.NoError(err)
+	defer os.RemoveAll(tmpDir)
+
+	read := func(p string) string {
+		b, err := ioutil.ReadFile(p)
+		assert.NoError(err)
+		return string(b)
+	}
+
+	cases := []struct {
+		template, version string
+		files             []string
+	}{
+		{"simple", "", []string{".gitignore", "go.mod", "root.vugu", "vugu.json"}},
+		{"counter", "v0.3.0", []string{".gitignore", "counter.vugu", "go.mod", "root.vugu", "vugu.json"}},
+	}
+
+	for _, tc := range cases {
+
+		dir := filepath.Join(tmpDir, tc.template)
+		assert.NoError(createProject(dir, tc.template, "example.com/"+tc.template, tc.version), tc.template)
+
+		infos, err := ioutil.ReadDir(dir)
+		assert.NoError(err)
+		var names []string
+		for _, fi := range infos {
+			names = append(names, fi.Name())
+		}
+		assert.Equal(tc.files, names, tc.template)
+
+		goMod := read(filepath.Join(dir, "go.mod"))
+		assert.Contains(goMod, "module example.com/"+tc.template+"\n")
+		if tc.version != "" {
+			assert.Contains(goMod, "require github.com/vugu/vugu "+tc.version+"\n")
+		} else {
+			assert.NotContains(goMod, "require")
+		}
+
+		// the project file is valid and the code generator accepts the markup
+		cfg, err := loadConfig(dir)
+		if assert.NoError(err, tc.template) {
+			assert.NoError(runGen(dir, cfg), tc.template)
+			_, err = os.Stat(filepath.Join(dir, "root_vgen.go"))
+			assert.NoError(err, tc.template)
+		}
+
+		// a directory which is not empty is left alone
+		err = createProject(dir, tc.template, "example.com/x", "")
+		if assert.Error(err, tc.template) {
+			assert.Contains(err.Error(), "not empty")
+		}
+		assert.Contains(read(filepath.Join(dir, "go.mod")), "module example.com/"+tc.template+"\n")
+	}
+
+	err = createProject(filepath.Join(tmpDir, "other"), "nope", "example.com/nope", "")
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "counter, simple")
+	}
+	_, err = os.Stat(filepath.Join(tmpDir, "other"))
+	assert.True(os.IsNotExist(err))
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"path/filepath"
+
+	"github.com/vugu/vugu/devutil"
+)
+
+const defaultServeAddr = ":8844"
+
+func serveMain(args []string) {
+
+	dir, cfg, err := serveSetup(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	c, closeCompiler, err := newCompiler(dir, cfg, true)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeCompiler()
+
+	h, stop := newDevHandler(dir, cfg, c)
+	defer stop()
+
+	log.Printf("Serving %s at %s", dir, cfg.Serve.Addr)
+	log.Fatal(http.ListenAndServe(cfg.Serve.Addr, h))
+}
+
+// serveSetup parses the arguments of serve and returns the project directory and its settings with the flags applied
+func serveSetup(args []string) (string, *projectConfig, error) {
+
+	fs := newFlagSet("serve", "[dir]")
+	addr := fs.String("addr", "", "Address to listen on (default from "+configFileName+" or "+defaultServeAddr+")")
+	tinygo := fs.Bool("tinygo", false, "Compile with TinyGo instead of Go")
+	noLiveReload := fs.Bool("no-live-reload", false, "Do not watch for changes and reload the page")
+	fs.Parse(args)
+
+	dir := projectDir(fs)
+	cfg, err := loadConfig(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	if *addr != "" {
+		cfg.Serve.Addr = *addr
+	}
+	if cfg.Serve.Addr == "" {
+		cfg.Serve.Addr = defaultServeAddr
+	}
+	if *tinygo {
+		cfg.Compiler = "tinygo"
+	}
+	cfg.Serve.NoLiveReload = cfg.Serve.NoLiveReload || *noLiveReload
+
+	return dir, cfg, nil
+}
+
+// newDevHandler returns the development server for the project in dir: the page, main.wasm built
+// by c on request, wasm_exec.js, build errors and live reload, and the other files in dir.
+// stop stops watching dir for live reload.
+func newDevHandler(dir string, cfg *projectConfig, c projectCompiler) (h http.Handler, stop func()) {
+
+	be := devutil.NewBuildErrorHandler(c)
+
+	mux := devutil.NewMux()
+	mux.Exact("/main.wasm", devutil.NewMainWasmHandler(be))
+	mux.Exact("/wasm_exec.js", devutil.NewWasmExecJSHandler(c))
+	mux.Exact(devutil.DefaultBuildErrorPath, be)
+
+	stop = func() {}
+	index := devutil.DefaultIndex
+	if !cfg.Serve.NoLiveReload {
+		lr := devutil.NewLiveReloadHandler().SetCompiler(be)
+		lr.Watch(dir)
+		stop = func() { lr.Close() }
+		mux.Exact(devutil.DefaultLiveReloadPath, lr)
+		index = devutil.DefaultLiveReloadIndex
+	}
+
+	if cfg.Serve.Index != "" {
+		mux.Match(devutil.NoFileExt, devutil.StaticFilePath(filepath.Join(dir, cfg.Serve.Index)))
+	} else {
+		mux.Match(devutil.NoFileExt, index)
+	}
+
+	mux.Default(devutil.NewFileServer().SetDir(dir))
+
+	return mux, stop
+}
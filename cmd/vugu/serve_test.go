@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vugu/vugu/devutil"
+)
+
+// serveTestCompiler writes a fake wasm file to dir on each build
+type serveTestCompiler struct{ dir string }
+
+func (c *serveTestCompiler) Execute() (string, error) {
+	f, err := ioutil.TempFile(c.dir, "main.wasm")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	_, err = f.WriteString("\x00asm")
+	return f.Name(), err
+}
+
+func (c *serveTestCompiler) WasmExecJS() (io.Reader, error) {
+	return strings.NewReader("// wasm_exec.js"), nil
+}
+
+func TestNewDevHandler(t *testing.T) {
+
+	assert := assert.New(t)
+
+	dir := testProject(t, "")
+	defer os.RemoveAll(dir)
+	outDir, err := ioutil.TempDir("", "TestNewDevHandler")
+	assert.NoError(err)
+	defer os.RemoveAll(outDir)
+
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, "style.css"), []byte("body{}"), 0644))
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, "page.html"), []byte("<p>my page</p>"), 0644))
+
+	c := &serveTestCompiler{dir: outDir}
+	get := func(cfg *projectConfig, path string) (int, string) {
+		h, stop := newDevHandler(dir, cfg, c)
+		defer stop()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec.Code, rec.Body.String()
+	}
+
+	cfg := &projectConfig{}
+	code, body := get(cfg, "/")
+	assert.Equal(200, code)
+	assert.Contains(body, devutil.DefaultLiveReloadPath)
+	_, body = get(cfg, "/some/page")
+	assert.Contains(body, devutil.DefaultLiveReloadPath)
+	_, body = get(cfg, "/main.wasm")
+	assert.Equal("\x00asm", body)
+	_, body = get(cfg, "/wasm_exec.js")
+	assert.Equal("// wasm_exec.js", body)
+	_, body = get(cfg, devutil.DefaultBuildErrorPath)
+	assert.Equal("null", body)
+	_, body = get(cfg, "/style.css")
+	assert.Equal("body{}", body)
+	code, _ = get(cfg, "/missing.css")
+	assert.Equal(404, code)
+
+	cfg.Serve.NoLiveReload = true
+	_, body = get(cfg, "/")
+	assert.NotContains(body, devutil.DefaultLiveReloadPath)
+
+	cfg.Serve.Index = "page.html"
+	_, body = get(cfg, "/some/page")
+	assert.Equal("<p>my page</p>", body)
+
+	// built files are removed after they are served
+	files, err := ioutil.ReadDir(outDir)
+	assert.NoError(err)
+	assert.Len(files, 0)
+}
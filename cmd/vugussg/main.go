@@ -0,0 +1,205 @@
+// vugussg is a command line tool to generate a static site from a Vugu package.
+//
+// It writes a temporary program into the package (built only with the vugussg tag) which renders
+// each route with package ssg, runs it and removes it again.  Routes are given with -routes and/or
+// -routes-file, and the package can provide more by declaring:
+//
+//	func vuguSSGRoutes() ([]string, error)
+//
+// The root component for each route is &Root{} unless the package declares:
+//
+//	func vuguSSGRoot(route string) (vugu.Builder, error)
+//
+// Any other non-wasm main function in the package must be excluded with a `// +build !vugussg` tag.
+// The exit code is non-zero if any route fails to render, including when a Build panics.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/vugu/vugu/gen"
+)
+
+const mainFileName = "vugussg_main.go"
+
+func main() {
+
+	// vugussg [flags] path/to/package
+
+	outDir := flag.String("o", "dist", "Output directory, relative to the current directory")
+	routesFlag := flag.String("routes", "", "Comma separated list of routes to render")
+	routesFile := flag.String("routes-file", "", "File with one route per line (blank lines and lines starting with # are ignored)")
+	assetDir := flag.String("assets", "", "Directory of static files to copy into the output, relative to the package directory")
+	baseURL := flag.String("base-url", "", "Absolute URL prefix for the sitemap, e.g. https://example.com")
+	noGen := flag.Bool("no-gen", false, "Do not run vugugen on the package first")
+	keep := flag.Bool("keep", false, "Keep the generated "+mainFileName+" instead of removing it")
+	flag.Parse()
+
+	pkgDir := "."
+	if flag.NArg() > 0 {
+		pkgDir = flag.Arg(0)
+	}
+	pkgDir, err := filepath.Abs(pkgDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	absOutDir, err := filepath.Abs(*outDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var routes []string
+	for _, r := range strings.Split(*routesFlag, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			routes = append(routes, r)
+		}
+	}
+	if *routesFile != "" {
+		fileRoutes, err := readRoutesFile(*routesFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		routes = append(routes, fileRoutes...)
+	}
+
+	if !*noGen {
+		err = gen.Run(pkgDir, &gen.ParserGoPkgOpts{SkipGoMod: true, SkipMainGo: true})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	names, err := funcNames(pkgDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(routes) == 0 && !names["vuguSSGRoutes"] {
+		log.Fatal("no routes specified, use -routes, -routes-file or declare vuguSSGRoutes in the package")
+	}
+
+	if *assetDir != "" && !filepath.IsAbs(*assetDir) {
+		*assetDir = filepath.Join(pkgDir, *assetDir)
+	}
+
+	var buf bytes.Buffer
+	err = mainTmpl.Execute(&buf, map[string]interface{}{
+		"OutDir":     absOutDir,
+		"Routes":     routes,
+		"AssetDir":   *assetDir,
+		"BaseURL":    *baseURL,
+		"RoutesFunc": names["vuguSSGRoutes"],
+		"RootFunc":   names["vuguSSGRoot"],
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mainPath := filepath.Join(pkgDir, mainFileName)
+	err = ioutil.WriteFile(mainPath, buf.Bytes(), 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cmd := exec.Command("go", "run", "-tags", "vugussg", ".")
+	cmd.Dir = pkgDir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+
+	if !*keep {
+		os.Remove(mainPath)
+	}
+
+	if err != nil {
+		log.Printf("vugussg: %v", err)
+		os.Exit(1)
+	}
+}
+
+func readRoutesFile(p string) ([]string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var ret []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ret = append(ret, line)
+	}
+	return ret, sc.Err()
+}
+
+// funcNames returns the names of the top level functions declared in the non-test .go files in dir
+func funcNames(dir string) (map[string]bool, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && fi.Name() != mainFileName
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			for _, d := range f.Decls {
+				if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv == nil {
+					ret[fd.Name.Name] = true
+				}
+			}
+		}
+	}
+	return ret, nil
+}
+
+var mainTmpl = template.Must(template.New("main").Parse(`// +build vugussg
+
+// Code generated by vugussg. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+{{if not .RootFunc}}
+	"github.com/vugu/vugu"
+{{- end}}
+	"github.com/vugu/vugu/ssg"
+)
+
+func main() {
+	g := ssg.New({{printf "%q" .OutDir}})
+	g.Routes = []string{ {{- range .Routes}}{{printf "%q" .}}, {{end -}} }
+{{- if .RoutesFunc}}
+	g.RoutesFunc = vuguSSGRoutes
+{{- end}}
+{{- if .RootFunc}}
+	g.RootFunc = vuguSSGRoot
+{{- else}}
+	g.RootFunc = func(route string) (vugu.Builder, error) { return &Root{}, nil }
+{{- end}}
+	g.AssetDir = {{printf "%q" .AssetDir}}
+	g.BaseURL = {{printf "%q" .BaseURL}}
+	err := g.Generate()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`))
@@ -0,0 +1,179 @@
+// +build !tinygo
+
+package vugu
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SaveState returns a JSON snapshot of the state of root and of each component used in the last
+// build, so it can be passed to RestoreState after the page is reloaded.  This is intended
+// for development, so live reloading does not throw away whatever state the app was in.
+//
+// The exported fields of each component are saved, except those tagged `vugu:"nostate"` or
+// `vugu:"inject"` and those that cannot be represented as JSON (funcs, channels, interfaces, etc.).
+// Components are identified by their CompKey, which is derived from their position
+// in the source and in the tree, so the same component is found again after a rebuild as long
+// as the structure around it did not change.
+func (e *BuildEnv) SaveState(root Builder) ([]byte, error) {
+
+	st := devState{
+		Version:    DevStateVersion,
+		Components: make(map[string]*devStateComponent, len(e.compUsed)+1),
+	}
+
+	st.Components[devStateRootKey] = saveComponentState(root)
+	for k, c := range e.compUsed {
+		st.Components[compKeyString(k)] = saveComponentState(c)
+	}
+
+	return json.Marshal(st)
+}
+
+// RestoreState puts back the state from a snapshot made by SaveState.  The root component is
+// restored immediately, every other component is restored the first time it is used in a following build,
+// before the fields passed to it by its parent are assigned.
+//
+// Saved state is skipped, and reported by StateMismatches, if the type of the component is not the same as
+// when it was saved, or for each field which no longer exists or whose value cannot be decoded into its type.
+// An error is only returned if data is not a snapshot at all.
+func (e *BuildEnv) RestoreState(root Builder, data []byte) error {
+
+	var st devState
+	err := json.Unmarshal(data, &st)
+	if err != nil {
+		return fmt.Errorf("vugu: unable to read saved state: %w", err)
+	}
+	if st.Version != DevStateVersion {
+		return fmt.Errorf("vugu: saved state has version %d, expected %d", st.Version, DevStateVersion)
+	}
+
+	e.pendingState = make(map[string]*devStateComponent, len(st.Components))
+	for k, c := range st.Components {
+		if c != nil {
+			e.pendingState[k] = c
+		}
+	}
+
+	e.applyPendingState(devStateRootKey, root)
+
+	return nil
+}
+
+// applyPendingState restores the saved state for key into component, if there is any
+func (e *BuildEnv) applyPendingState(key string, component Builder) {
+	sc, ok := e.pendingState[key]
+	if !ok {
+		return
+	}
+	delete(e.pendingState, key)
+	if len(e.pendingState) == 0 {
+		e.pendingState = nil
+	}
+	e.stateMismatches = append(e.stateMismatches, restoreComponentState(key, component, sc)...)
+}
+
+// applyPendingCompState is called by UseComponent
+func (e *BuildEnv) applyPendingCompState(compKey CompKey, component Builder) {
+	if e.pendingState == nil {
+		return
+	}
+	e.applyPendingState(compKeyString(compKey), component)
+}
+
+func compKeyString(k CompKey) string {
+	return fmt.Sprintf("%x:%v", k.ID, k.IterKey)
+}
+
+// stateFields returns the struct value c points to and the indexes of its fields which are part of its state
+func stateFields(c interface{}) (reflect.Value, []int) {
+
+	v := reflect.ValueOf(c)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, nil
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	var ret []int
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" { // unexported
+			continue
+		}
+		if sf.Tag.Get("json") == "-" {
+			continue
+		}
+		tag := "," + sf.Tag.Get("vugu") + ","
+		if strings.Contains(tag, ",nostate,") || strings.Contains(tag, ",inject,") {
+			continue
+		}
+		switch sf.Type.Kind() {
+		case reflect.Func, reflect.Chan, reflect.Interface, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+			continue
+		}
+		ret = append(ret, i)
+	}
+	return v, ret
+}
+
+func saveComponentState(c Builder) *devStateComponent {
+	ret := &devStateComponent{Type: fmt.Sprintf("%T", c), Fields: make(map[string]json.RawMessage)}
+	v, fields := stateFields(c)
+	for _, i := range fields {
+		b, err := json.Marshal(v.Field(i).Interface())
+		if err != nil { // not JSON-serializable, e.g. contains a func
+			continue
+		}
+		ret.Fields[v.Type().Field(i).Name] = b
+	}
+	return ret
+}
+
+func restoreComponentState(key string, c Builder, sc *devStateComponent) (ret []StateMismatch) {
+
+	typeName := fmt.Sprintf("%T", c)
+	if sc.Type != typeName {
+		return []StateMismatch{{Key: key, ComponentType: typeName,
+			Reason: fmt.Sprintf("type changed (saved from %s), using initial state", sc.Type)}}
+	}
+
+	v, fields := stateFields(c)
+	byName := make(map[string]reflect.Value, len(fields))
+	for _, i := range fields {
+		byName[v.Type().Field(i).Name] = v.Field(i)
+	}
+
+	names := make([]string, 0, len(sc.Fields))
+	for name := range sc.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b := sc.Fields[name]
+		fv, ok := byName[name]
+		if !ok {
+			ret = append(ret, StateMismatch{Key: key, ComponentType: typeName, Field: name,
+				Reason: "field no longer exists or is excluded, value dropped"})
+			continue
+		}
+		// decode into a scratch value first so a field is never left half restored
+		err := json.Unmarshal(b, reflect.New(fv.Type()).Interface())
+		if err == nil {
+			// decoding into the field itself means pointers which are already set,
+			// e.g. to a shared store, have their target updated instead of being replaced
+			err = json.Unmarshal(b, fv.Addr().Interface())
+		}
+		if err != nil {
+			ret = append(ret, StateMismatch{Key: key, ComponentType: typeName, Field: name,
+				Reason: fmt.Sprintf("cannot restore value (type changed?), using initial value: %v", err)})
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,21 @@
+// +build tinygo
+
+package vugu
+
+import "errors"
+
+// errStateNotSupported is returned by SaveState and RestoreState, which need more of reflect
+// than TinyGo has
+var errStateNotSupported = errors.New("vugu: saving and restoring state is not supported under tinygo")
+
+// SaveState is not supported under TinyGo, it returns an error.
+func (e *BuildEnv) SaveState(root Builder) ([]byte, error) {
+	return nil, errStateNotSupported
+}
+
+// RestoreState is not supported under TinyGo, it returns an error.
+func (e *BuildEnv) RestoreState(root Builder, data []byte) error {
+	return errStateNotSupported
+}
+
+func (e *BuildEnv) applyPendingCompState(compKey CompKey, component Builder) {}
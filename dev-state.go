@@ -0,0 +1,52 @@
+package vugu
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DevStateVersion is the version of the format produced by BuildEnv.SaveState.
+// Snapshots with a different version are rejected by RestoreState.
+const DevStateVersion = 1
+
+// devStateRootKey is the key used for the root component, it cannot collide with a CompKey
+const devStateRootKey = "root"
+
+// devState is the snapshot produced by SaveState
+type devState struct {
+	Version    int                           `json:"version"`
+	Components map[string]*devStateComponent `json:"components"` // keyed by devStateRootKey or CompKey
+}
+
+// devStateComponent is the saved state of one component
+type devStateComponent struct {
+	Type   string                     `json:"type"` // e.g. "*main.Root"
+	Fields map[string]json.RawMessage `json:"fields"`
+}
+
+// StateMismatch describes saved state which RestoreState could not put back into a component,
+// usually because the code changed since it was saved.  The component (or field) keeps
+// its initial value instead.
+type StateMismatch struct {
+	Key           string // "root" or the component's CompKey
+	ComponentType string // type name of the component, e.g. "*main.Root"
+	Field         string // name of the field, empty if the whole component was skipped
+	Reason        string
+}
+
+// String returns a human readable description.
+func (m StateMismatch) String() string {
+	if m.Field == "" {
+		return fmt.Sprintf("%s (%s): %s", m.ComponentType, m.Key, m.Reason)
+	}
+	return fmt.Sprintf("%s (%s): field %s: %s", m.ComponentType, m.Key, m.Field, m.Reason)
+}
+
+// StateMismatches returns the problems found while restoring state since the last call.
+// Child components are restored as they are created during builds after RestoreState,
+// so it is worth calling this after each build.
+func (e *BuildEnv) StateMismatches() []StateMismatch {
+	ret := e.stateMismatches
+	e.stateMismatches = nil
+	return ret
+}
@@ -0,0 +1,111 @@
+package vugu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type devStateTestStore struct{ User string }
+
+type devStateTestRoot struct {
+	Name     string
+	Count    int
+	Items    []string
+	Store    *devStateTestStore
+	Skipped  string             `vugu:"nostate"`
+	Injected *devStateTestStore `vugu:"inject"`
+	OnClick  func()
+	private  string
+
+	child *devStateTestChild
+}
+
+func (c *devStateTestRoot) Build(in *BuildIn) (out *BuildOut) {
+	// simplified version of what generated code does for a child component
+	key := MakeCompKey(0x1234^in.CurrentPositionHash(), 0)
+	child, _ := in.BuildEnv.CachedComponent(key).(*devStateTestChild)
+	if child == nil {
+		child = new(devStateTestChild)
+	}
+	in.BuildEnv.UseComponent(key, child)
+	child.Label = c.Name // passed from parent
+	c.child = child
+	return &BuildOut{Components: []Builder{child}}
+}
+
+type devStateTestChild struct {
+	Label string
+	Open  bool
+}
+
+func (c *devStateTestChild) Build(in *BuildIn) (out *BuildOut) { return &BuildOut{} }
+
+type devStateTestChildV2 struct {
+	Open string // type changed
+}
+
+func (c *devStateTestChildV2) Build(in *BuildIn) (out *BuildOut) { return &BuildOut{} }
+
+func TestBuildEnvDevState(t *testing.T) {
+
+	assert := assert.New(t)
+
+	be, _ := NewBuildEnv()
+	root := &devStateTestRoot{Name: "n1", Count: 2, Items: []string{"a"}, Store: &devStateTestStore{User: "joe"},
+		Skipped: "s", Injected: &devStateTestStore{User: "x"}, OnClick: func() {}, private: "p"}
+	be.RunBuild(root)
+	root.child.Open = true
+
+	data, err := be.SaveState(root)
+	assert.NoError(err)
+	assert.NotContains(string(data), "Skipped")
+	assert.NotContains(string(data), "Injected")
+	assert.NotContains(string(data), "OnClick")
+
+	// simulate a reload, with a store that is provided before restoring
+	store := &devStateTestStore{}
+	be2, _ := NewBuildEnv()
+	root2 := &devStateTestRoot{Skipped: "initial", Store: store}
+	assert.NoError(be2.RestoreState(root2, data))
+	assert.Equal("n1", root2.Name)
+	assert.Equal(2, root2.Count)
+	assert.Equal([]string{"a"}, root2.Items)
+	assert.Equal("initial", root2.Skipped)
+	assert.True(store == root2.Store) // pointer kept, target updated
+	assert.Equal("joe", store.User)
+	assert.Empty(root2.private)
+
+	be2.RunBuild(root2)
+	assert.True(root2.child.Open)
+	assert.Equal("n1", root2.child.Label)
+	assert.Empty(be2.StateMismatches())
+
+	// restored only once
+	root2.child.Open = false
+	be2.RunBuild(root2)
+	assert.False(root2.child.Open)
+
+	// mismatches are reported and the initial values kept
+	be3, _ := NewBuildEnv()
+	v2 := &devStateTestChildV2{}
+	assert.NoError(be3.RestoreState(v2, []byte(`{"version":1,"components":{"root":{"type":"*vugu.devStateTestChildV2","fields":{"Open":true,"Gone":1}}}}`)))
+	assert.Equal("", v2.Open)
+	mm := be3.StateMismatches()
+	if assert.Len(mm, 2) {
+		assert.Equal("Gone", mm[0].Field)
+		assert.Equal("Open", mm[1].Field)
+		assert.Contains(mm[1].String(), "field Open")
+	}
+	assert.Empty(be3.StateMismatches())
+
+	be4, _ := NewBuildEnv()
+	assert.NoError(be4.RestoreState(v2, data))
+	mm = be4.StateMismatches()
+	if assert.Len(mm, 1) {
+		assert.Contains(mm[0].String(), "type changed (saved from *vugu.devStateTestRoot)")
+	}
+
+	assert.Error(be4.RestoreState(v2, []byte(`{"version":99}`)))
+	assert.Error(be4.RestoreState(v2, []byte(`junk`)))
+}
@@ -0,0 +1,212 @@
+package devutil
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vugu/xxhash"
+)
+
+// DefaultBuildInputPattern matches the files in a build directory whose content is part of
+// the hash used to decide if a cached build is still current.
+var DefaultBuildInputPattern = regexp.MustCompile(`([.](go|vugu|s|c|h)|/go[.](mod|sum))$`)
+
+// buildCache keeps the output of the last successful build along with a hash of its inputs,
+// and makes concurrent callers share one build.
+type buildCache struct {
+	stateMu sync.Mutex // protects gen for reading before waiting for buildMu
+	buildMu sync.Mutex // held while checking the cache and building
+
+	gen     int    // incremented after each build
+	lastErr error  // error from the last build, if it failed
+	key     uint64 // hash of the inputs to the cached output
+	outpath string // cached output, owned by the cache, empty if none
+}
+
+// execute returns a new hard link (or copy) of the cached output if inputKey matches the
+// inputs of the cached build, otherwise it calls build and caches the result.
+// Callers which were waiting for a build done by another caller get the same error if it failed,
+// instead of immediately trying again.  inputKey is called again after a successful
+// build so files written by it (e.g. generated code) are accounted for.
+func (bc *buildCache) execute(inputKey func() (uint64, error), build func() (string, error)) (outpath string, cached bool, err error) {
+
+	bc.stateMu.Lock()
+	startGen := bc.gen
+	bc.stateMu.Unlock()
+
+	bc.buildMu.Lock()
+	defer bc.buildMu.Unlock()
+
+	if bc.gen != startGen && bc.lastErr != nil {
+		return "", false, bc.lastErr
+	}
+
+	key, err := inputKey()
+	if err == nil && bc.outpath != "" && key == bc.key {
+		outpath, err = linkTempFile(bc.outpath)
+		if err == nil {
+			return outpath, true, nil
+		}
+	}
+
+	outpath, err = build()
+
+	bc.stateMu.Lock()
+	bc.gen++
+	bc.lastErr = err
+	bc.stateMu.Unlock()
+
+	if err != nil {
+		return outpath, false, err
+	}
+
+	key, kerr := inputKey()
+	cachePath, lerr := linkTempFile(outpath)
+	if kerr != nil || lerr != nil {
+		// still a valid build, it just won't be cached
+		return outpath, false, nil
+	}
+	if bc.outpath != "" {
+		os.Remove(bc.outpath)
+	}
+	bc.outpath, bc.key = cachePath, key
+
+	return outpath, false, nil
+}
+
+// close removes the cached output
+func (bc *buildCache) close() {
+	bc.buildMu.Lock()
+	defer bc.buildMu.Unlock()
+	if bc.outpath != "" {
+		os.Remove(bc.outpath)
+		bc.outpath = ""
+	}
+}
+
+// linkTempFile makes a hard link to src with a new temporary name, falling back to a
+// copy with the same modification time if that fails
+func linkTempFile(src string) (string, error) {
+
+	f, err := ioutil.TempFile(filepath.Dir(src), "buildcache")
+	if err != nil {
+		return "", err
+	}
+	dst := f.Name()
+
+	f.Close()
+	os.Remove(dst)
+	if os.Link(src, dst) == nil {
+		return dst, nil
+	}
+
+	err = copyFileTo(src, dst)
+	if err != nil {
+		os.Remove(dst)
+		return "", err
+	}
+	return dst, nil
+}
+
+func copyFileTo(src, dst string) error {
+	sf, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer sf.Close()
+	st, err := sf.Stat()
+	if err != nil {
+		return err
+	}
+	df, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(df, sf)
+	if err != nil {
+		df.Close()
+		return err
+	}
+	err = df.Close()
+	if err != nil {
+		return err
+	}
+	return os.Chtimes(dst, st.ModTime(), st.ModTime())
+}
+
+// inputHasher computes the key for a buildCache from settings and directory contents
+type inputHasher struct {
+	d    *xxhash.Digest
+	dirs map[string]bool
+}
+
+func newInputHasher() *inputHasher {
+	return &inputHasher{d: xxhash.New(), dirs: make(map[string]bool)}
+}
+
+// setting adds a named setting, e.g. build command arguments, to the hash
+func (ih *inputHasher) setting(name string, v interface{}) {
+	fmt.Fprintf(ih.d, "%s=%q\n", name, v)
+}
+
+// env adds environment variables to the hash, nil means the environment of this process
+func (ih *inputHasher) env(env []string) {
+	if env == nil {
+		env = os.Environ()
+	}
+	env = append([]string(nil), env...)
+	sort.Strings(env)
+	ih.setting("env", env)
+}
+
+// dir adds the path and content of each file in dir matching DefaultBuildInputPattern to the hash.
+// Directories starting with a dot and testdata directories are skipped, as are test files.
+// Each directory is only hashed once.
+func (ih *inputHasher) dir(dir string) error {
+
+	if dir == "" {
+		dir = "."
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if ih.dirs[dir] {
+		return nil
+	}
+	ih.dirs[dir] = true
+
+	return filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if p != dir && (strings.HasPrefix(fi.Name(), ".") || fi.Name() == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		sp := filepath.ToSlash(p)
+		if !DefaultBuildInputPattern.MatchString(sp) || strings.HasSuffix(sp, "_test.go") {
+			return nil
+		}
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(ih.d, "file %q %d\n", sp, len(b))
+		ih.d.Write(b)
+		return nil
+	})
+}
+
+func (ih *inputHasher) sum() uint64 {
+	return ih.d.Sum64()
+}
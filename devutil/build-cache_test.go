@@ -0,0 +1,152 @@
+package devutil
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBuildCacheShared(t *testing.T) {
+
+	tmpDir, err := ioutil.TempDir("", "TestBuildCacheShared")
+	must(err)
+	defer os.RemoveAll(tmpDir)
+
+	var key uint64 = 1
+	var builds int32
+	var buildErr error
+	var bc buildCache
+	defer bc.close()
+
+	inputKey := func() (uint64, error) { return atomic.LoadUint64(&key), nil }
+	build := func() (string, error) {
+		atomic.AddInt32(&builds, 1)
+		time.Sleep(50 * time.Millisecond) // give the other callers time to start waiting
+		if buildErr != nil {
+			return "", buildErr
+		}
+		p := filepath.Join(tmpDir, "out")
+		return p, ioutil.WriteFile(p, []byte("built"), 0644)
+	}
+
+	// concurrent callers share one build
+	run := func() (errs []error) {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				outpath, _, err := bc.execute(inputKey, build)
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+				if err == nil {
+					b, _ := ioutil.ReadFile(outpath)
+					if string(b) != "built" {
+						t.Errorf("unexpected output %q", b)
+					}
+					os.Remove(outpath)
+				}
+			}()
+		}
+		wg.Wait()
+		return errs
+	}
+
+	for _, err := range run() {
+		must(err)
+	}
+	if builds != 1 {
+		t.Fatalf("expected 1 build, got %d", builds)
+	}
+
+	// the output is still cached after every caller removed its copy
+	outpath, cached, err := bc.execute(inputKey, build)
+	must(err)
+	os.Remove(outpath)
+	if !cached || builds != 1 {
+		t.Fatalf("expected cached result, cached=%v builds=%d", cached, builds)
+	}
+
+	// a failed build is shared by those waiting for it
+	atomic.StoreUint64(&key, 2)
+	buildErr = errors.New("failed")
+	for _, err := range run() {
+		if err != buildErr {
+			t.Fatalf("expected shared build error, got %v", err)
+		}
+	}
+	if builds != 2 {
+		t.Fatalf("expected 2 builds, got %d", builds)
+	}
+}
+
+func TestWasmCompilerCache(t *testing.T) {
+
+	tmpDir, err := ioutil.TempDir("", "TestWasmCompilerCache")
+	must(err)
+	defer os.RemoveAll(tmpDir)
+
+	var logBuf bytes.Buffer
+	wc := NewWasmCompiler().SetBuildDir(tmpDir).SetLogWriter(&logBuf)
+
+	must(ioutil.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module TestWasmCompilerCache\n"), 0644))
+	must(ioutil.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\nfunc main() {}\n"), 0644))
+
+	h := NewMainWasmHandler(wc)
+	get := func(etag string) *http.Response {
+		req, _ := http.NewRequest("GET", "/main.wasm", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		wr := httptest.NewRecorder()
+		h.ServeHTTP(wr, req)
+		return wr.Result()
+	}
+
+	res := get("")
+	if res.StatusCode != 200 {
+		t.Fatalf("unexpected status %d", res.StatusCode)
+	}
+	etag := res.Header.Get("ETag")
+
+	// nothing changed
+	res = get(etag)
+	if res.StatusCode != 304 {
+		t.Fatalf("expected 304, got %d", res.StatusCode)
+	}
+	if !strings.Contains(logBuf.String(), "WasmCompiler: Using cached build") {
+		t.Fatalf("expected cached build, log: %s", logBuf.String())
+	}
+
+	// source changed
+	logBuf.Reset()
+	must(ioutil.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\nfunc main() { println(1) }\n"), 0644))
+	res = get(etag)
+	if res.StatusCode != 200 || res.Header.Get("ETag") == etag {
+		t.Fatalf("expected new build, got status %d and ETag %q", res.StatusCode, res.Header.Get("ETag"))
+	}
+	if strings.Contains(logBuf.String(), "Using cached build") {
+		t.Fatalf("unexpected cached build, log: %s", logBuf.String())
+	}
+
+	// Close removes the cached output
+	cachePath := wc.cache.outpath
+	if cachePath == "" {
+		t.Fatalf("expected a cached build")
+	}
+	must(wc.Close())
+	if _, err := os.Stat(cachePath); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed, got: %v", cachePath, err)
+	}
+}
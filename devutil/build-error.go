@@ -0,0 +1,323 @@
+package devutil
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuildErrorPath is the path BuildErrorHandler is usually served from and the one BuildErrorScript fetches.
+const DefaultBuildErrorPath = "/_vugu/build-error"
+
+// Build phases reported in BuildError.Phase.
+const (
+	BuildPhaseGenerate = "generate" // code generation, i.e. vugugen via `go generate`
+	BuildPhaseBuild    = "build"    // the Go or Tinygo compiler
+)
+
+// buildErrorContextLines is the number of lines shown before and after the offending line
+const buildErrorContextLines = 3
+
+// BuildError describes a failed build in a form that can be shown in the browser.
+// The location fields are from the first error found in the tool output and are empty if none was found.
+type BuildError struct {
+	Phase    string       `json:"phase"`              // BuildPhaseGenerate or BuildPhaseBuild
+	File     string       `json:"file,omitempty"`     // file the first error refers to, as reported by the tool
+	Line     int          `json:"line,omitempty"`     // line number in File
+	Column   int          `json:"column,omitempty"`   // column number in File
+	VuguFile string       `json:"vuguFile,omitempty"` // the .vugu file File was generated from, if it could be determined
+	VuguLine int          `json:"vuguLine,omitempty"` // best guess of the line in VuguFile that corresponds to Line
+	Message  string       `json:"message"`            // message of the first error, or the full output if none was found
+	Output   string       `json:"output"`             // full output of the tool
+	Source   []SourceLine `json:"source,omitempty"`   // lines around the error, from VuguFile if set or File otherwise
+
+	Err error `json:"-"` // the original error
+}
+
+// SourceLine is a numbered line of source code.
+type SourceLine struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// Error implements error and returns the message of the original error.
+func (e *BuildError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the original error.
+func (e *BuildError) Unwrap() error { return e.Err }
+
+// errLocRE matches "file.go:12:5: message" style locations in compiler and vet output
+var errLocRE = regexp.MustCompile(`(?m)^\s*(\S+\.(?:go|vugu)):(\d+)(?::(\d+))?:\s*(.*)$`)
+
+// errQuotedFileRE matches the quoted file name in generator errors like `error parsing "root.vugu": ...`
+var errQuotedFileRE = regexp.MustCompile(`"([^"\s]+\.vugu)"`)
+
+// errLineRE matches a line number mentioned in a generator error
+var errLineRE = regexp.MustCompile(`\bline (\d+)`)
+
+// NewBuildError returns a BuildError for err which occurred during phase.  The output of the
+// tool is parsed for the location of the first error and relative file names are resolved against dir.
+// Errors in generated _vgen.go files are traced back to the .vugu file they came from where possible.
+func NewBuildError(phase, dir string, output []byte, err error) *BuildError {
+
+	ret := &BuildError{
+		Phase:   phase,
+		Output:  string(output),
+		Message: strings.TrimSpace(string(output)),
+		Err:     err,
+	}
+	if ret.Message == "" {
+		ret.Message = err.Error()
+	}
+
+	if m := errLocRE.FindStringSubmatch(ret.Output); m != nil {
+		ret.File = m[1]
+		ret.Line, _ = strconv.Atoi(m[2])
+		ret.Column, _ = strconv.Atoi(m[3])
+		ret.Message = m[4]
+	} else if m := errQuotedFileRE.FindStringSubmatch(ret.Message); m != nil {
+		ret.File = m[1]
+		if m := errLineRE.FindStringSubmatch(ret.Message); m != nil {
+			ret.Line, _ = strconv.Atoi(m[1])
+		}
+	}
+
+	if ret.File == "" {
+		return ret
+	}
+
+	fpath := resolveErrorFile(dir, ret.File)
+
+	if strings.HasSuffix(fpath, ".vugu") {
+		ret.VuguFile, ret.VuguLine = ret.File, ret.Line
+	} else if strings.HasSuffix(fpath, "_vgen.go") {
+		vuguPath := strings.TrimSuffix(fpath, "_vgen.go") + ".vugu"
+		if _, err := os.Stat(vuguPath); err == nil {
+			ret.VuguFile = strings.TrimSuffix(ret.File, "_vgen.go") + ".vugu"
+			ret.VuguLine = vuguLineFor(fpath, vuguPath, ret.Line, ret.Column)
+			fpath = vuguPath
+		}
+	}
+
+	line := ret.Line
+	if ret.VuguFile != "" {
+		line = ret.VuguLine
+	}
+	if line > 0 {
+		ret.Source = readSourceLines(fpath, line-buildErrorContextLines, line+buildErrorContextLines)
+	}
+
+	return ret
+}
+
+// resolveErrorFile finds the file an error refers to, falling back to a file with the same
+// name in dir for paths that only exist inside the build environment (e.g. a Tinygo container)
+func resolveErrorFile(dir, fname string) string {
+	p := fname
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(dir, p)
+	}
+	if _, err := os.Stat(p); err == nil {
+		return p
+	}
+	return filepath.Join(dir, filepath.Base(fname))
+}
+
+// vuguLineFor guesses which line of the .vugu file produced goLine of the generated Go file,
+// by looking for the Go code at the error position in the .vugu source.  Returns 0 if not found.
+func vuguLineFor(goPath, vuguPath string, goLine, goCol int) int {
+
+	lines := readSourceLines(goPath, goLine, goLine)
+	if len(lines) == 0 {
+		return 0
+	}
+	text := lines[0].Text
+
+	// the identifier (with selectors) at the error position, e.g. "c.Missing"
+	start := goCol - 1
+	if start < 0 || start >= len(text) {
+		start = 0
+	}
+	end := start
+	for end < len(text) && isGoIdentChar(text[end]) {
+		end++
+	}
+	for start > 0 && isGoIdentChar(text[start-1]) {
+		start--
+	}
+	expr := strings.Trim(text[start:end], ".")
+	if len(expr) < 2 {
+		return 0
+	}
+
+	for _, vl := range readSourceLines(vuguPath, 1, -1) {
+		if strings.Contains(vl.Text, expr) {
+			return vl.Line
+		}
+	}
+	return 0
+}
+
+func isGoIdentChar(c byte) bool {
+	return c == '_' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// readSourceLines returns lines from to to (inclusive, 1-based) of a file, to < 0 means until the end
+func readSourceLines(fpath string, from, to int) (ret []SourceLine) {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		if to >= 0 && n > to {
+			break
+		}
+		if n >= from {
+			ret = append(ret, SourceLine{Line: n, Text: sc.Text()})
+		}
+	}
+	return ret
+}
+
+// NewBuildErrorHandler returns a BuildErrorHandler which records the results of c.
+func NewBuildErrorHandler(c Compiler) *BuildErrorHandler {
+	return &BuildErrorHandler{c: c}
+}
+
+// BuildErrorHandler wraps a Compiler and records the error from the last call to Execute.
+// It is an http.Handler which responds with the last error as a BuildError in JSON,
+// or null if the last build succeeded, for use by BuildErrorScript.
+// Use it in place of the Compiler it wraps, e.g.:
+//
+//	wc := devutil.NewWasmCompiler().SetDir(".")
+//	be := devutil.NewBuildErrorHandler(wc)
+//	mux := devutil.NewMux()
+//	mux.Exact("/main.wasm", devutil.NewMainWasmHandler(be))
+//	mux.Exact(devutil.DefaultBuildErrorPath, be)
+//	...
+//
+// If a LiveReloadHandler is used, pass it to SetCompiler too so rebuilds done on file changes update the error.
+type BuildErrorHandler struct {
+	c Compiler
+
+	rwmu    sync.RWMutex
+	lastErr *BuildError
+}
+
+// Execute implements Compiler.
+func (h *BuildErrorHandler) Execute() (outpath string, err error) {
+	outpath, err = h.c.Execute()
+	h.SetLastError(err)
+	return outpath, err
+}
+
+// SetLastError records the result of a build done elsewhere.  err is converted with
+// NewBuildError if it does not already contain a BuildError.  A nil err clears the last error.
+func (h *BuildErrorHandler) SetLastError(err error) {
+	var be *BuildError
+	if err != nil && !errors.As(err, &be) {
+		be = NewBuildError(BuildPhaseBuild, "", []byte(err.Error()), err)
+	}
+	h.rwmu.Lock()
+	h.lastErr = be
+	h.rwmu.Unlock()
+}
+
+// LastError returns the error from the last build or nil if it succeeded.
+func (h *BuildErrorHandler) LastError() *BuildError {
+	h.rwmu.RLock()
+	defer h.rwmu.RUnlock()
+	return h.lastErr
+}
+
+// ServeHTTP implements http.Handler.
+func (h *BuildErrorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ServeBuildError(w, h.LastError())
+}
+
+// ServeBuildError responds with be as JSON, or null if be is nil.
+func ServeBuildError(w http.ResponseWriter, be *BuildError) {
+	b, err := json.Marshal(be)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error encoding build error: %v", err), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(b)
+}
+
+// BuildErrorScript returns a script tag which defines window.vuguBuildError(fallbackText).
+// When called (e.g. after main.wasm failed to load) it fetches the BuildError JSON from
+// the given path and shows it in an overlay on top of the page, with the offending source lines.
+// It then polls the path and reloads the page as soon as a build succeeds.
+// If the response is not a BuildError the fallback text is shown instead.
+func BuildErrorScript(path string) string {
+	return strings.Replace(buildErrorScript, "BUILD_ERROR_PATH", fmt.Sprintf("%q", path), 1)
+}
+
+const buildErrorScript = `<script>
+window.vuguBuildError = function(fallbackText) {
+	var path = BUILD_ERROR_PATH;
+	function el(tag, style, text) {
+		var e = document.createElement(tag);
+		if (style) { e.style.cssText = style; }
+		if (text) { e.textContent = text; }
+		return e;
+	}
+	function show(be) {
+		var old = document.getElementById("vugu_build_error");
+		if (old) { old.parentNode.removeChild(old); }
+		var o = el("div", "position: fixed; top: 0; left: 0; right: 0; bottom: 0; z-index: 2147483647; overflow: auto; " +
+			"background: rgba(20,20,20,0.95); color: #eee; font: 14px/1.5 monospace; padding: 20px;");
+		o.id = "vugu_build_error";
+		if (!be) {
+			o.appendChild(el("pre", "white-space: pre-wrap; color: #f66;", fallbackText));
+			document.body.appendChild(o);
+			return;
+		}
+		o.appendChild(el("div", "color: #f66; font-size: 18px; margin-bottom: 10px;", "Build error (" + be.phase + ")"));
+		var loc = be.vuguFile ? be.vuguFile + (be.vuguLine ? ":" + be.vuguLine : "") : be.file;
+		if (be.file) {
+			if (loc != be.file) { loc += " (" + be.file + ":" + be.line + ")"; } else if (be.line) { loc += ":" + be.line + (be.column ? ":" + be.column : ""); }
+			o.appendChild(el("div", "color: #9cf;", loc));
+		}
+		o.appendChild(el("pre", "white-space: pre-wrap; color: #fff; font-weight: bold;", be.message));
+		var errLine = be.vuguFile ? be.vuguLine : be.line;
+		if (be.source) {
+			var src = el("pre", "background: #000; padding: 10px; overflow-x: auto;");
+			be.source.forEach(function(sl) {
+				var hl = sl.line == errLine;
+				src.appendChild(el("div", hl ? "background: #600;" : "color: #aaa;", (hl ? "> " : "  ") + ("     " + sl.line).slice(-5) + " | " + sl.text));
+			});
+			o.appendChild(src);
+		}
+		var det = el("details", "margin-top: 10px;");
+		det.appendChild(el("summary", "cursor: pointer;", "Full output"));
+		det.appendChild(el("pre", "white-space: pre-wrap; color: #ccc;", be.output));
+		o.appendChild(det);
+		document.body.appendChild(o);
+	}
+	function poll() {
+		fetch(path, {cache: "no-store"}).then(function(res) { return res.json(); }).then(function(be) {
+			if (!be) { window.location.reload(); return; }
+			setTimeout(poll, 1000);
+		}).catch(function() { setTimeout(poll, 1000); });
+	}
+	fetch(path, {cache: "no-store"}).then(function(res) { return res.json(); }).then(function(be) {
+		if (!be || !be.phase) { throw new Error("no build error"); }
+		show(be);
+		setTimeout(poll, 1000);
+	}).catch(function() { show(null); });
+};
+</script>`
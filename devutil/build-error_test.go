@@ -0,0 +1,78 @@
+package devutil
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type buildErrorTestCompiler struct{ err error }
+
+func (c *buildErrorTestCompiler) Execute() (string, error) { return "", c.err }
+
+func TestBuildError(t *testing.T) {
+
+	tmpDir, err := ioutil.TempDir("", "TestBuildError")
+	must(err)
+	defer os.RemoveAll(tmpDir)
+
+	must(ioutil.WriteFile(filepath.Join(tmpDir, "root.vugu"), []byte("<div>\n  <span>x</span>\n  <p vg-if='c.Missing'></p>\n</div>\n"), 0644))
+	must(ioutil.WriteFile(filepath.Join(tmpDir, "root_vgen.go"), []byte("package main\n\nfunc (c *Root) Build() {\n\tif c.Missing {\n\t}\n}\n"), 0644))
+
+	// compiler error in generated code is traced back to the .vugu file
+	out := []byte("# example.com/test\n./root_vgen.go:4:7: c.Missing undefined (type *Root has no field or method Missing)\n./root_vgen.go:9:2: other\n")
+	be := NewBuildError(BuildPhaseBuild, tmpDir, out, errors.New("build failed"))
+	if be.File != "./root_vgen.go" || be.Line != 4 || be.Column != 7 {
+		t.Fatalf("unexpected location %s:%d:%d", be.File, be.Line, be.Column)
+	}
+	if be.Message != "c.Missing undefined (type *Root has no field or method Missing)" {
+		t.Fatalf("unexpected message %q", be.Message)
+	}
+	if be.VuguFile != "./root.vugu" || be.VuguLine != 3 {
+		t.Fatalf("unexpected vugu location %s:%d", be.VuguFile, be.VuguLine)
+	}
+	if len(be.Source) != 4 || be.Source[0].Line != 1 || be.Source[2].Text != "  <p vg-if='c.Missing'></p>" {
+		t.Fatalf("unexpected source %#v", be.Source)
+	}
+	if be.Error() != "build failed" || !errors.Is(be, be.Err) {
+		t.Fatalf("unexpected error %v", be)
+	}
+
+	// generator errors name the file in quotes
+	be = NewBuildError(BuildPhaseGenerate, tmpDir, []byte(`error parsing "root.vugu": Found more than one top level element: p`), errors.New("generate failed"))
+	if be.VuguFile != "root.vugu" || be.Line != 0 || len(be.Source) != 0 {
+		t.Fatalf("unexpected generate error %#v", be)
+	}
+
+	// handler records the last result
+	c := &buildErrorTestCompiler{err: errors.New("./root_vgen.go:4:7: c.Missing undefined")}
+	h := NewBuildErrorHandler(c)
+	_, err = h.Execute()
+	if err == nil || h.LastError() == nil || h.LastError().Line != 4 {
+		t.Fatalf("unexpected last error %#v", h.LastError())
+	}
+
+	wr := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", DefaultBuildErrorPath, nil)
+	h.ServeHTTP(wr, r)
+	checkStatus(t, r, wr.Result(), 200)
+	checkHeader(t, r, wr.Result(), "Content-Type", "application/json")
+	var m map[string]interface{}
+	must(json.Unmarshal(wr.Body.Bytes(), &m))
+	if m["phase"] != BuildPhaseBuild || m["file"] != "./root_vgen.go" {
+		t.Fatalf("unexpected JSON %s", wr.Body.Bytes())
+	}
+
+	// cleared by the next successful build
+	c.err = nil
+	_, err = h.Execute()
+	must(err)
+	wr = httptest.NewRecorder()
+	h.ServeHTTP(wr, r)
+	checkBody(t, r, wr.Result(), "null")
+}
@@ -22,12 +22,21 @@ func NewWasmCompiler() *WasmCompiler {
 }
 
 // WasmCompiler provides a convenient way to call `go generate` and `go build` and produce Wasm executables for your system.
+// Builds are cached, see SetCache.
 type WasmCompiler struct {
 	beforeFunc      func() error
 	generateCmdFunc func() *exec.Cmd
 	buildCmdFunc    func(outpath string) *exec.Cmd
 	afterFunc       func(outpath string, err error) error
 	logWriter       io.Writer
+	noCache         bool
+	cache           buildCache
+}
+
+// Close performs any cleanup.  It removes the cached build, see SetCache.
+func (c *WasmCompiler) Close() error {
+	c.cache.close()
+	return nil
 }
 
 // SetLogWriter sets the writer to use for logging output.  Setting it to nil disables logging.
@@ -40,6 +49,17 @@ func (c *WasmCompiler) SetLogWriter(w io.Writer) *WasmCompiler {
 	return c
 }
 
+// SetCache enables or disables build caching, it is enabled by default.  When enabled, Execute
+// hashes the inputs of the build (the build and generate commands and their environment and the
+// contents of the .go, .vugu, go.mod and go.sum files, etc. in their directories) and if they are the same as
+// for the last successful build it returns a link to that output instead of running generate and build again.
+// Concurrent calls to Execute share one build.  Note that changes outside these directories (e.g. to
+// a module included with a replace directive) are not detected.
+func (c *WasmCompiler) SetCache(v bool) *WasmCompiler {
+	c.noCache = !v
+	return c
+}
+
 // SetDir sets both the build and generate directories.
 func (c *WasmCompiler) SetDir(dir string) *WasmCompiler {
 	return c.SetBuildDir(dir).SetGenerateDir(dir)
@@ -97,9 +117,11 @@ func (c *WasmCompiler) SetAfterFunc(f func(outpath string, err error) error) *Wa
 // and produces a wasm executable (or an error).
 // The value of outpath is the absolute path to the output file on disk.
 // It will be created with a temporary name and if no error is returned
-// it is the caller's responsibility to delete the file when it is no longer needed.
+// it is the caller's responsibility to delete the file when it is no longer needed
+// (if the build was cached it is a hard link to or copy of the cached output, see SetCache).
 // If an error occurs during any of the steps it will be returned with (possibly multi-line)
 // descriptive output in it's error message, as produced by the underlying tool.
+// Errors from the generate and build commands are a *BuildError.
 func (c *WasmCompiler) Execute() (outpath string, err error) {
 
 	logerr := func(e error) error {
@@ -121,11 +143,35 @@ func (c *WasmCompiler) Execute() (outpath string, err error) {
 		}
 	}
 
+	if c.noCache {
+		outpath, err = c.build(logerr)
+	} else {
+		var cached bool
+		outpath, cached, err = c.cache.execute(c.inputHash, func() (string, error) { return c.build(logerr) })
+		if cached {
+			fmt.Fprintln(c.logWriter, "WasmCompiler: Using cached build")
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if c.afterFunc != nil {
+		err = c.afterFunc(outpath, err)
+	}
+
+	return outpath, logerr(err)
+
+}
+
+// build runs generate and build and returns the path of the output
+func (c *WasmCompiler) build(logerr func(error) error) (outpath string, err error) {
+
 	if c.generateCmdFunc != nil {
 		cmd := c.generateCmdFunc()
 		b, err := cmd.CombinedOutput()
 		if err != nil {
-			return "", logerr(fmt.Errorf("WasmCompiler: generate error: %w; full output:\n%s", err, b))
+			return "", logerr(NewBuildError(BuildPhaseGenerate, cmd.Dir, b, fmt.Errorf("WasmCompiler: generate error: %w; full output:\n%s", err, b)))
 		}
 		fmt.Fprintln(c.logWriter, "WasmCompiler: Successful generate")
 	}
@@ -145,16 +191,31 @@ func (c *WasmCompiler) Execute() (outpath string, err error) {
 	cmd := c.buildCmdFunc(outpath)
 	b, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", logerr(fmt.Errorf("WasmCompiler: build error: %w; full output:\n%s", err, b))
+		os.Remove(outpath)
+		return "", logerr(NewBuildError(BuildPhaseBuild, cmd.Dir, b, fmt.Errorf("WasmCompiler: build error: %w; full output:\n%s", err, b)))
 	}
 	fmt.Fprintln(c.logWriter, "WasmCompiler: Successful build")
 
-	if c.afterFunc != nil {
-		err = c.afterFunc(outpath, err)
-	}
-
-	return outpath, logerr(err)
+	return outpath, nil
+}
 
+// inputHash returns the hash of the build inputs for the cache
+func (c *WasmCompiler) inputHash() (uint64, error) {
+	ih := newInputHasher()
+	if c.generateCmdFunc != nil {
+		cmd := c.generateCmdFunc()
+		ih.setting("generate", cmd.Args)
+		ih.env(cmd.Env)
+		err := ih.dir(cmd.Dir)
+		if err != nil {
+			return 0, err
+		}
+	}
+	cmd := c.buildCmdFunc("")
+	ih.setting("build", cmd.Args)
+	ih.env(cmd.Env)
+	err := ih.dir(cmd.Dir)
+	return ih.sum(), err
 }
 
 // WasmExecJS returns the contents of the wasm_exec.js file bundled with the Go compiler.
@@ -19,6 +19,7 @@ func TestWasmCompiler(t *testing.T) {
 	t.Logf("Using temporary dir: %s", tmpDir)
 
 	wc := NewWasmCompiler().SetBuildDir(tmpDir)
+	defer wc.Close()
 
 	must(ioutil.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte(`module TestWasmCompiler
 `), 0644))
@@ -72,6 +73,7 @@ func main() { ioutil.WriteFile("./other.go", []byte("package main\nvar other = 1
 	defer os.RemoveAll(tmpDir)
 	t.Logf("Using temporary dir: %s", tmpDir)
 	wc = NewWasmCompiler().SetBuildDir(tmpDir)
+	defer wc.Close()
 	var h http.Handler
 
 	must(ioutil.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte(`module TestWasmCompiler
@@ -2,7 +2,9 @@ package devutil
 
 import (
 	"fmt"
+	"io"
 	"log"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
@@ -27,10 +29,14 @@ import (
 // that's not present http.NotFound is called.
 //
 // Directory listings are disabled by default due to security concerns but can be enabled with SetListings.
+//
+// If precompressed files are enabled with SetPrecompressed, a .br or .gz sibling of the file is served instead
+// when the client accepts that encoding (as written by distutil.Build).
 type FileServer struct {
 	fsys            http.FileSystem
 	listings        bool         // do we show directory listings
 	notFoundHandler http.Handler // call when not found
+	precompressed   bool         // serve .br and .gz siblings when accepted
 }
 
 // NewFileServer returns a FileServer instance.
@@ -56,6 +62,12 @@ func (fs *FileServer) SetListings(v bool) *FileServer {
 	return fs
 }
 
+// SetPrecompressed enables or disables serving precompressed .br and .gz siblings of files.
+func (fs *FileServer) SetPrecompressed(v bool) *FileServer {
+	fs.precompressed = v
+	return fs
+}
+
 // SetNotFoundHandler sets the handle used when no applicable file can be found.
 func (fs *FileServer) SetNotFoundHandler(h http.Handler) *FileServer {
 	fs.notFoundHandler = h
@@ -122,6 +134,7 @@ func (fs *FileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		f2, err2 := fs.fsys.Open(name + ".html")
 		if err2 == nil {
 			f = f2
+			name += ".html"
 		} else {
 
 			msg, code := toHTTPError(err)
@@ -204,9 +217,76 @@ func (fs *FileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// log.Printf("about to serve: f=%#v, d=%#v", f, d)
 
+	if fs.precompressed && !d.IsDir() {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if fs.servePrecompressed(w, r, name, d, f) {
+			return
+		}
+	}
+
 	http.ServeContent(w, r, d.Name(), d.ModTime(), f)
 }
 
+// precompressedEncodings are the encodings servePrecompressed looks for, in order of preference
+var precompressedEncodings = []struct{ name, ext string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// servePrecompressed serves the first compressed sibling of name which the client accepts,
+// returns false if there is none.  The content type is that of the original file f.
+func (fs *FileServer) servePrecompressed(w http.ResponseWriter, r *http.Request, name string, d os.FileInfo, f http.File) bool {
+
+	for _, enc := range precompressedEncodings {
+
+		if !acceptsEncoding(r, enc.name) {
+			continue
+		}
+
+		cf, err := fs.fsys.Open(name + enc.ext)
+		if err != nil {
+			continue
+		}
+		defer cf.Close()
+		cd, err := cf.Stat()
+		if err != nil || cd.IsDir() {
+			continue
+		}
+
+		ctype := mime.TypeByExtension(path.Ext(name))
+		if ctype == "" {
+			// sniff the original, the compressed content would just be detected as binary
+			var buf [512]byte
+			n, _ := io.ReadFull(f, buf[:])
+			ctype = http.DetectContentType(buf[:n])
+		}
+
+		w.Header().Set("Content-Type", ctype)
+		w.Header().Set("Content-Encoding", enc.name)
+		http.ServeContent(w, r, d.Name(), d.ModTime(), cf)
+		return true
+	}
+
+	return false
+}
+
+// acceptsEncoding returns true if the Accept-Encoding request header allows enc
+func acceptsEncoding(r *http.Request, enc string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		fields := strings.Split(part, ";")
+		if strings.TrimSpace(fields[0]) != enc {
+			continue
+		}
+		for _, param := range fields[1:] {
+			if q := strings.Replace(strings.TrimSpace(param), " ", "", -1); q == "q=0" || strings.HasPrefix(q, "q=0.0") && strings.Trim(q[len("q=0."):], "0") == "" {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
 // localRedirect gives a Moved Permanently response.
 // It does not convert relative paths to absolute paths like Redirect does.
 func localRedirect(w http.ResponseWriter, r *http.Request, newPath string) {
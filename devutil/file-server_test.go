@@ -3,6 +3,7 @@ package devutil
 import (
 	"bytes"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/http/httptest"
 	"net/http/httputil"
@@ -102,6 +103,56 @@ func TestFileServer(t *testing.T) {
 
 }
 
+func TestFileServerPrecompressed(t *testing.T) {
+
+	tmpDir, err := ioutil.TempDir("", "TestFileServerPrecompressed")
+	must(err)
+	defer os.RemoveAll(tmpDir)
+
+	must(ioutil.WriteFile(filepath.Join(tmpDir, "app.js"), []byte(`plain`), 0644))
+	must(ioutil.WriteFile(filepath.Join(tmpDir, "app.js.gz"), []byte(`gzipped`), 0644))
+	must(ioutil.WriteFile(filepath.Join(tmpDir, "app.js.br"), []byte(`brotlied`), 0644))
+	must(ioutil.WriteFile(filepath.Join(tmpDir, "page.html"), []byte(`<html>plain page</html>`), 0644))
+
+	fs := NewFileServer().SetDir(tmpDir)
+
+	get := func(p, acceptEncoding string) (*http.Request, *http.Response) {
+		wr := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", p, nil)
+		if acceptEncoding != "" {
+			r.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		fs.ServeHTTP(wr, r)
+		return r, wr.Result()
+	}
+
+	// off by default
+	r, res := get("/app.js", "gzip, br")
+	checkBody(t, r, res, "plain")
+	checkHeader(t, r, res, "Content-Encoding", "")
+
+	fs.SetPrecompressed(true)
+
+	r, res = get("/app.js", "gzip, deflate, br")
+	checkBody(t, r, res, "brotlied")
+	checkHeader(t, r, res, "Content-Encoding", "br")
+	checkHeader(t, r, res, "Content-Type", mime.TypeByExtension(".js"))
+	checkHeader(t, r, res, "Vary", "Accept-Encoding")
+
+	r, res = get("/app.js", "gzip, br;q=0")
+	checkBody(t, r, res, "gzipped")
+	checkHeader(t, r, res, "Content-Encoding", "gzip")
+
+	r, res = get("/app.js", "")
+	checkBody(t, r, res, "plain")
+	checkHeader(t, r, res, "Content-Encoding", "")
+
+	// no compressed sibling
+	r, res = get("/page", "gzip, br")
+	checkBody(t, r, res, "plain page")
+	checkHeader(t, r, res, "Content-Encoding", "")
+}
+
 func checkBody(t *testing.T, req *http.Request, res *http.Response, text string) {
 	b, err := httputil.DumpResponse(res, true)
 	if err != nil {
@@ -2,6 +2,7 @@ package devutil
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
@@ -22,6 +23,8 @@ type WasmExecJSer interface {
 }
 
 // MainWasmHandler calls WasmCompiler.Build and responds with the resulting .wasm file.
+// The ETag and Last-Modified headers are derived from the output file, so with a compiler
+// that caches builds a client which already has the current build gets a 304 response.
 type MainWasmHandler struct {
 	wc Compiler
 }
@@ -63,6 +66,12 @@ func (h *MainWasmHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the browser must check back each time, but it can use what it has if the build did not change
+	if w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, st.Size(), st.ModTime().UnixNano()))
+
 	http.ServeContent(w, r, r.URL.Path, st.ModTime(), f)
 
 }
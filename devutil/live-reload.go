@@ -0,0 +1,276 @@
+package devutil
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLiveReloadPath is the path LiveReloadHandler is usually served from and the one LiveReloadScript connects to.
+const DefaultLiveReloadPath = "/_vugu/live-reload"
+
+// DefaultLiveReloadPattern matches the files which are watched for changes by default:
+// .vugu and .go files, go.mod and the static file types a page typically loads.
+var DefaultLiveReloadPattern = regexp.MustCompile(`([.](vugu|go|css|js|html|json|map|jpg|jpeg|png|gif|svg|ico|eot|ttf|otf|woff|woff2)|/go[.](mod|sum))$`)
+
+// LiveReloadScript returns a script tag which connects to a LiveReloadHandler at the given path
+// and reloads the page when told to.  If the connection is lost (e.g. because the dev server
+// was restarted) it keeps trying to reconnect and reloads the page once it succeeds.
+func LiveReloadScript(path string) string {
+	return strings.Replace(liveReloadScript, "LIVE_RELOAD_PATH", fmt.Sprintf("%q", path), 1)
+}
+
+const liveReloadScript = `<script>
+(function() {
+	if (!window.EventSource) { return; }
+	var lost = false;
+	function connect() {
+		var es = new EventSource(LIVE_RELOAD_PATH);
+		es.addEventListener("reload", function() { window.location.reload(); });
+		es.onopen = function() { if (lost) { window.location.reload(); } };
+		es.onerror = function() {
+			lost = true;
+			// the browser retries by itself unless the response was not an event stream
+			if (es.readyState === EventSource.CLOSED) { setTimeout(connect, 1000); }
+		};
+	}
+	connect();
+})();
+</script>`
+
+// DefaultLiveReloadIndex is like DefaultIndex but also includes LiveReloadScript for DefaultLiveReloadPath.
+var DefaultLiveReloadIndex = DefaultIndex.Replace(
+	"<!-- scripts -->",
+	LiveReloadScript(DefaultLiveReloadPath)+"\n<!-- scripts -->")
+
+// NewLiveReloadHandler returns a LiveReloadHandler.  Call Watch to start watching for changes.
+func NewLiveReloadHandler() *LiveReloadHandler {
+	return &LiveReloadHandler{
+		pattern:   DefaultLiveReloadPattern,
+		interval:  500 * time.Millisecond,
+		logWriter: os.Stderr,
+		clients:   make(map[chan struct{}]bool),
+	}
+}
+
+// LiveReloadHandler serves a Server-Sent Events stream which tells the connected pages to reload.
+// Reload can be called directly, or Watch can be used to poll a directory for changes,
+// rebuild and then reload if the rebuild succeeds.  Example use:
+//
+//	wc := devutil.NewWasmCompiler().SetDir(".")
+//	lr := devutil.NewLiveReloadHandler().SetCompiler(wc)
+//	lr.Watch(".")
+//	mux := devutil.NewMux()
+//	mux.Exact(devutil.DefaultLiveReloadPath, lr)
+//	mux.Match(devutil.NoFileExt, devutil.DefaultLiveReloadIndex)
+//	...
+type LiveReloadHandler struct {
+	pattern     *regexp.Regexp
+	interval    time.Duration
+	rebuildFunc func() error
+	logWriter   io.Writer
+
+	mu      sync.Mutex
+	clients map[chan struct{}]bool
+	stop    chan struct{} // closed to stop the watch goroutine
+	done    chan struct{} // closed when it has stopped
+}
+
+// SetPattern sets the pattern which the slash-separated path of a file must match for it to be watched.
+// The default is DefaultLiveReloadPattern.
+func (h *LiveReloadHandler) SetPattern(pattern *regexp.Regexp) *LiveReloadHandler {
+	h.pattern = pattern
+	return h
+}
+
+// SetInterval sets how often the watched directory is checked for changes.  The default is 500ms.
+func (h *LiveReloadHandler) SetInterval(d time.Duration) *LiveReloadHandler {
+	h.interval = d
+	return h
+}
+
+// SetRebuildFunc sets a function which is called after a change is detected.  Pages are only
+// told to reload if it returns nil.
+func (h *LiveReloadHandler) SetRebuildFunc(f func() error) *LiveReloadHandler {
+	h.rebuildFunc = f
+	return h
+}
+
+// SetCompiler is a shortcut for SetRebuildFunc with a function that calls c.Execute
+// and removes the output file.
+func (h *LiveReloadHandler) SetCompiler(c Compiler) *LiveReloadHandler {
+	return h.SetRebuildFunc(func() error {
+		outpath, err := c.Execute()
+		if err != nil {
+			return err
+		}
+		return os.Remove(outpath)
+	})
+}
+
+// SetLogWriter sets the writer to use for logging output.  Setting it to nil disables logging.
+// The default from NewLiveReloadHandler is os.Stderr
+func (h *LiveReloadHandler) SetLogWriter(w io.Writer) *LiveReloadHandler {
+	if w == nil {
+		w = ioutil.Discard
+	}
+	h.logWriter = w
+	return h
+}
+
+// Watch starts a goroutine which polls dir for changes to files matching the pattern, and calls
+// the rebuild function and Reload when there are any.  Changes made by the rebuild itself
+// (e.g. generated code) are ignored.  Calling Watch again stops the previous watch.
+func (h *LiveReloadHandler) Watch(dir string) {
+
+	h.Close()
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	h.mu.Lock()
+	h.stop, h.done = stop, done
+	h.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+
+		last, err := dirStateHash(dir, h.pattern)
+		if err != nil {
+			fmt.Fprintf(h.logWriter, "LiveReloadHandler: error scanning %q: %v\n", dir, err)
+		}
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			cur, err := dirStateHash(dir, h.pattern)
+			if err != nil {
+				fmt.Fprintf(h.logWriter, "LiveReloadHandler: error scanning %q: %v\n", dir, err)
+				continue
+			}
+			if cur == last {
+				continue
+			}
+
+			if h.rebuildFunc != nil {
+				err = h.rebuildFunc()
+				// rescan so anything written by the rebuild does not trigger another one
+				last, _ = dirStateHash(dir, h.pattern)
+				if err != nil {
+					fmt.Fprintf(h.logWriter, "LiveReloadHandler: rebuild error, not reloading: %v\n", err)
+					continue
+				}
+			} else {
+				last = cur
+			}
+
+			fmt.Fprintln(h.logWriter, "LiveReloadHandler: change detected, reloading")
+			h.Reload()
+		}
+	}()
+}
+
+// Close stops watching for changes, if Watch was called.  It waits for a scan or rebuild
+// in progress to finish, so dir can be removed after it returns.
+func (h *LiveReloadHandler) Close() error {
+	h.mu.Lock()
+	stop, done := h.stop, h.done
+	h.stop, h.done = nil, nil
+	h.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	return nil
+}
+
+// Reload tells every connected page to reload.
+func (h *LiveReloadHandler) Reload() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		// never block, a pending reload is as good as two
+		select {
+		case c <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *LiveReloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "LiveReloadHandler: streaming not supported", 500)
+		return
+	}
+
+	c := make(chan struct{}, 1)
+	h.mu.Lock()
+	h.clients[c] = true
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, c)
+		h.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(200)
+	fmt.Fprint(w, "retry: 1000\n\n")
+	flusher.Flush()
+
+	// a comment is sent periodically so proxies and the browser don't give up on an idle connection
+	ping := time.NewTicker(30 * time.Second)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ping.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case <-c:
+			fmt.Fprint(w, "event: reload\ndata: reload\n\n")
+		}
+		flusher.Flush()
+	}
+}
+
+// dirStateHash returns a hash of the paths, sizes and modification times of the files in dir
+// which match pattern.  Directories starting with a dot (.git etc.) are skipped.
+func dirStateHash(dir string, pattern *regexp.Regexp) (uint64, error) {
+	hsh := fnv.New64a()
+	err := filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if p != dir && strings.HasPrefix(fi.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		sp := filepath.ToSlash(p)
+		if pattern != nil && !pattern.MatchString(sp) {
+			return nil
+		}
+		fmt.Fprintf(hsh, "%s\x00%d\x00%d\n", sp, fi.Size(), fi.ModTime().UnixNano())
+		return nil
+	})
+	return hsh.Sum64(), err
+}
@@ -0,0 +1,90 @@
+package devutil
+
+import (
+	"bufio"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLiveReloadHandler(t *testing.T) {
+
+	tmpDir, err := ioutil.TempDir("", "TestLiveReloadHandler")
+	must(err)
+	defer os.RemoveAll(tmpDir)
+	must(ioutil.WriteFile(filepath.Join(tmpDir, "root.vugu"), []byte(`<div>one</div>`), 0644))
+
+	var rebuilds int32
+	var fail atomic.Value
+	fail.Store(false)
+	h := NewLiveReloadHandler().SetLogWriter(nil).SetInterval(10 * time.Millisecond).SetRebuildFunc(func() error {
+		atomic.AddInt32(&rebuilds, 1)
+		if fail.Load().(bool) {
+			return errors.New("build failed")
+		}
+		// files written by the rebuild itself must not cause another one
+		return ioutil.WriteFile(filepath.Join(tmpDir, "0_components_vgen.go"), []byte("package main"), 0644)
+	})
+	h.Watch(tmpDir)
+	defer h.Close()
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	res, err := http.Get(srv.URL)
+	must(err)
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected Content-Type %q", ct)
+	}
+
+	events := make(chan string, 10)
+	go func() {
+		sc := bufio.NewScanner(res.Body)
+		for sc.Scan() {
+			if strings.HasPrefix(sc.Text(), "event: ") {
+				events <- strings.TrimPrefix(sc.Text(), "event: ")
+			}
+		}
+	}()
+
+	// a failed rebuild does not reload
+	fail.Store(true)
+	touch(t, filepath.Join(tmpDir, "root.vugu"), time.Now().Add(time.Second))
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected event %q after failed rebuild", ev)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	fail.Store(false)
+	touch(t, filepath.Join(tmpDir, "root.vugu"), time.Now().Add(2*time.Second))
+	select {
+	case ev := <-events:
+		if ev != "reload" {
+			t.Fatalf("unexpected event %q", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for reload event")
+	}
+
+	// files not matching the pattern are ignored
+	must(ioutil.WriteFile(filepath.Join(tmpDir, "notes.txt"), []byte(`x`), 0644))
+	time.Sleep(200 * time.Millisecond)
+	if n := atomic.LoadInt32(&rebuilds); n != 2 {
+		t.Fatalf("expected 2 rebuilds, got %d", n)
+	}
+}
+
+func touch(t *testing.T, p string, mt time.Time) {
+	err := os.Chtimes(p, mt, mt)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
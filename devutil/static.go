@@ -12,6 +12,8 @@ import (
 // are meant to be replaced as needed if you quickly need to hack in CSS or
 // JS references for a development Vugu application.  If you need more control
 // than that, just copy it into your application.
+// If main.wasm fails to load, the error from a BuildErrorHandler at DefaultBuildErrorPath
+// is shown in an overlay, or the response text if there is none.
 var DefaultIndex = StaticContent(`<!doctype html>
 <html>
 <head>
@@ -26,6 +28,7 @@ var DefaultIndex = StaticContent(`<!doctype html>
 <script src="https://cdn.jsdelivr.net/npm/text-encoding@0.7.0/lib/encoding.min.js"></script> <!-- MS Edge polyfill -->
 <script src="/wasm_exec.js"></script>
 <!-- scripts -->
+` + BuildErrorScript(DefaultBuildErrorPath) + `
 <script>
 var wasmSupported = (typeof WebAssembly === "object");
 if (wasmSupported) {
@@ -43,9 +46,7 @@ if (wasmSupported) {
 			});		
 		} else {
 			res.text().then(function(txt) {
-				var el = document.getElementById("vugu_mount_point");
-				el.style = 'font-family: monospace; background: black; color: red; padding: 10px';
-				el.innerText = txt;
+				window.vuguBuildError(txt);
 			})
 		}
 	})
@@ -70,13 +70,24 @@ type TinygoCompiler struct {
 	wasmExecJS        []byte            // contents of wasm_exec.js
 	pkgReplaceMap     map[string]string // package replacements pkgName->directory
 	tinygoArgs        []string          // additional arguments to pass to the tinygo build cmd
+	noCache           bool              // disable build caching
+	cache             buildCache
 }
 
-// Close performs any cleanup.  For now it removes the temporary directory created by NewTinygoCompiler.
+// Close performs any cleanup.  It removes the temporary directory created by NewTinygoCompiler and the cached build.
 func (c *TinygoCompiler) Close() error {
+	c.cache.close()
 	return os.RemoveAll(c.dlTmpGopath)
 }
 
+// SetCache enables or disables build caching, it is enabled by default.  See WasmCompiler.SetCache.
+// For Tinygo the hashed inputs also include the module directory of the build directory, the directories
+// added with AddPkgReplace, and the go get commands, Tinygo arguments and docker image.
+func (c *TinygoCompiler) SetCache(v bool) *TinygoCompiler {
+	c.noCache = !v
+	return c
+}
+
 // SetTinygoArgs sets arguments to be passed to tinygo, e.g. -no-debug
 func (c *TinygoCompiler) SetTinygoArgs(tinygoArgs ...string) *TinygoCompiler {
 	c.tinygoArgs = tinygoArgs
@@ -195,9 +206,11 @@ func (c *TinygoCompiler) SetTinygoDockerImage(img string) *TinygoCompiler {
 // and produces a wasm executable (or an error).
 // The value of outpath is the absolute path to the output file on disk.
 // It will be created with a temporary name and if no error is returned
-// it is the caller's responsibility to delete the file when it is no longer needed.
+// it is the caller's responsibility to delete the file when it is no longer needed
+// (if the build was cached it is a hard link to or copy of the cached output, see SetCache).
 // If an error occurs during any of the steps it will be returned with (possibly multi-line)
 // descriptive output in it's error message, as produced by the underlying tool.
+// Errors from the generate and build commands are a *BuildError.
 func (c *TinygoCompiler) Execute() (outpath string, err error) {
 
 	logerr := func(e error) error {
@@ -219,11 +232,77 @@ func (c *TinygoCompiler) Execute() (outpath string, err error) {
 		}
 	}
 
+	if c.noCache {
+		return c.build(logerr)
+	}
+
+	outpath, cached, err := c.cache.execute(c.inputHash, func() (string, error) { return c.build(logerr) })
+	if cached {
+		fmt.Fprintln(c.logWriter, "TinygoCompiler: Using cached build")
+	}
+	return outpath, err
+}
+
+// inputHash returns the hash of the build inputs for the cache
+func (c *TinygoCompiler) inputHash() (uint64, error) {
+
+	ih := newInputHasher()
+	ih.env(nil)
+	ih.setting("goget", c.goGetCmdList)
+	ih.setting("replace", c.pkgReplaceMap)
+	ih.setting("image", c.tinygoDockerImage)
+	ih.setting("args", c.tinygoArgs)
+
+	if c.generateCmdFunc != nil {
+		cmd := c.generateCmdFunc()
+		ih.setting("generate", cmd.Args)
+		ih.env(cmd.Env)
+		err := ih.dir(cmd.Dir)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	buildDirAbs, err := filepath.Abs(c.buildDir)
+	if err != nil {
+		return 0, err
+	}
+	buildDirAbs, err = filepath.EvalSymlinks(buildDirAbs)
+	if err != nil {
+		return 0, err
+	}
+	modDir, _, _, err := detectMod(buildDirAbs)
+	if err != nil {
+		return 0, err
+	}
+	err = ih.dir(modDir)
+	if err != nil {
+		return 0, err
+	}
+
+	pkgNames := make([]string, 0, len(c.pkgReplaceMap))
+	for pkgName := range c.pkgReplaceMap {
+		pkgNames = append(pkgNames, pkgName)
+	}
+	sort.Strings(pkgNames)
+	for _, pkgName := range pkgNames {
+		err := ih.dir(c.pkgReplaceMap[pkgName])
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return ih.sum(), nil
+}
+
+// build runs everything after beforeFunc and returns the path of the output
+func (c *TinygoCompiler) build(logerr func(error) error) (outpath string, err error) {
+
 	if c.generateCmdFunc != nil {
 		cmd := c.generateCmdFunc()
 		b, err := cmd.CombinedOutput()
 		if err != nil {
-			return "", logerr(fmt.Errorf("TinygoCompiler: generate error: %w; full output:\n%s", err, b))
+			return "", logerr(NewBuildError(BuildPhaseGenerate, cmd.Dir, b, fmt.Errorf("TinygoCompiler: generate error: %w; full output:\n%s", err, b)))
 		}
 		fmt.Fprintln(c.logWriter, "TinygoCompiler: Successful generate")
 	}
@@ -334,7 +413,7 @@ func (c *TinygoCompiler) Execute() (outpath string, err error) {
 		cmd.Env = append(cmd.Env, "GO111MODULE=off")
 		b, err := cmd.CombinedOutput()
 		if err != nil {
-			return "", logerr(fmt.Errorf("TinygoCompiler: build error (cmd=tinygo %v): %w; full output:\n%s", args, err, b))
+			return "", logerr(NewBuildError(BuildPhaseBuild, c.buildDir, b, fmt.Errorf("TinygoCompiler: build error (cmd=tinygo %v): %w; full output:\n%s", args, err, b)))
 		}
 		fmt.Fprintf(c.logWriter, "TinygoCompiler: successful build: tinygo %v; output: %s\n", args, b)
 
@@ -369,7 +448,7 @@ func (c *TinygoCompiler) Execute() (outpath string, err error) {
 		cmd := exec.Command("docker", args...)
 		b, err := cmd.CombinedOutput()
 		if err != nil {
-			return "", logerr(fmt.Errorf("TinygoCompiler: build error (cmd=docker %v): %w; full output:\n%s", args, err, b))
+			return "", logerr(NewBuildError(BuildPhaseBuild, c.buildDir, b, fmt.Errorf("TinygoCompiler: build error (cmd=docker %v): %w; full output:\n%s", args, err, b)))
 		}
 		fmt.Fprintf(c.logWriter, "TinygoCompiler: successful build: docker %v; output: %s\n", args, b)
 
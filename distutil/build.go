@@ -0,0 +1,384 @@
+package distutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vugu/vugu/gen"
+)
+
+// Compiler produces a Wasm executable, it is implemented by devutil.WasmCompiler and devutil.TinygoCompiler.
+// If it also has a `WasmExecJS() (io.Reader, error)` method (as both of those do) it is used to get wasm_exec.js.
+type Compiler interface {
+	Execute() (outpath string, err error)
+}
+
+// BrotliFunc compresses src with Brotli and writes the result to dst.
+type BrotliFunc func(dst io.Writer, src io.Reader) error
+
+// DefaultManifestName is the file name the Manifest is written to in the output directory.
+const DefaultManifestName = "manifest.json"
+
+// NewBuild returns a Build for the project in dir which writes to outDir,
+// with sensible defaults for the other fields.  Brotli is set from ExecBrotli,
+// so .br files are only written if the brotli command is installed.
+func NewBuild(dir, outDir string) *Build {
+	return &Build{
+		Dir:             dir,
+		OutDir:          outDir,
+		ParserGoPkgOpts: &gen.ParserGoPkgOpts{},
+		Pages:           []string{"index.html"},
+		DefaultPage:     DefaultPage,
+		Gzip:            true,
+		Brotli:          ExecBrotli(),
+		ManifestName:    DefaultManifestName,
+	}
+}
+
+// Build is a production build pipeline which runs the code generator, builds main.wasm,
+// writes it, wasm_exec.js and any other assets under content-hashed names (e.g. main.3f2a5c0e12b4d9a7.wasm),
+// rewrites references to them in the pages, writes precompressed .gz and .br siblings
+// for each file and finally a Manifest which maps original names to hashed names.
+// Example use in a dist.go which is "go run":
+//
+//	b := distutil.NewBuild(".", "dist")
+//	b.Assets = []string{"style.css"}
+//	m := b.MustRun()
+//	fmt.Printf("main.wasm is %s\n", m.Files["main.wasm"].Path)
+//
+// Files which are referenced from Go code or templates can be mapped at runtime with Manifest.Rewrite.
+type Build struct {
+	Dir    string // project directory with the .vugu files and main package
+	OutDir string // output directory, created if it doesn't exist
+
+	ParserGoPkgOpts *gen.ParserGoPkgOpts // options for the code generator, nil skips code generation
+	Compiler        Compiler             // builds main.wasm, if nil `go build` is run in Dir with GOOS=js GOARCH=wasm
+	WasmExecJSPath  string               // path to wasm_exec.js, if empty it is obtained from the Compiler or the Go installation
+
+	Assets []string // additional files relative to Dir to write with a content-hashed name, e.g. "style.css"
+	Pages  []string // HTML files relative to Dir which have references rewritten and are written to OutDir under their own name, missing ones are skipped
+
+	// DefaultPage is written as index.html, with references rewritten, if none of Pages exist.
+	// If it is empty as well Run returns an error, as the output would have no page loading main.wasm.
+	DefaultPage string
+
+	Gzip         bool       // write a .gz sibling for each file
+	Brotli       BrotliFunc // if not nil write a .br sibling for each file
+	ManifestName string     // file name of the manifest in OutDir, empty to not write it
+}
+
+// Manifest lists the files written by Build.
+type Manifest struct {
+	Files map[string]*ManifestFile `json:"files"` // keyed by original name, e.g. "main.wasm"
+}
+
+// ManifestFile describes one file written by Build.
+type ManifestFile struct {
+	Path      string   `json:"path"`                // path relative to OutDir, e.g. "main.3f2a5c0e12b4d9a7.wasm"
+	Hash      string   `json:"hash"`                // hex encoded SHA-256 of the content
+	Size      int64    `json:"size"`                // uncompressed size in bytes
+	Encodings []string `json:"encodings,omitempty"` // precompressed siblings written, "gzip" (.gz) and/or "br" (.br)
+}
+
+// DefaultPage is the index.html written by Build for projects without one of their own.
+// It is like devutil.DefaultIndex, without the development build error overlay.
+const DefaultPage = `<!doctype html>
+<html>
+<head>
+<title>Vugu App</title>
+<meta charset="utf-8"/>
+</head>
+<body>
+<div id="vugu_mount_point">
+<img style="position: absolute; top: 50%; left: 50%;" src="https://cdnjs.cloudflare.com/ajax/libs/galleriffic/2.0.1/css/loader.gif">
+</div>
+<script src="https://cdn.jsdelivr.net/npm/text-encoding@0.7.0/lib/encoding.min.js"></script> <!-- MS Edge polyfill -->
+<script src="/wasm_exec.js"></script>
+<script>
+var wasmSupported = (typeof WebAssembly === "object");
+if (wasmSupported) {
+	if (!WebAssembly.instantiateStreaming) { // polyfill
+		WebAssembly.instantiateStreaming = async (resp, importObject) => {
+			const source = await (await resp).arrayBuffer();
+			return await WebAssembly.instantiate(source, importObject);
+		};
+	}
+	const go = new Go();
+	WebAssembly.instantiateStreaming(fetch("/main.wasm"), go.importObject).then((result) => {
+		go.run(result.instance);
+	});
+} else {
+	document.getElementById("vugu_mount_point").innerHTML = 'This application requires WebAssembly support.  Please upgrade your browser.';
+}
+</script>
+</body>
+</html>
+`
+
+// MustRun is like Run but panics on error.
+func (b *Build) MustRun() *Manifest {
+	m, err := b.Run()
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Run performs the build and returns the Manifest.
+func (b *Build) Run() (*Manifest, error) {
+
+	err := os.MkdirAll(b.OutDir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.ParserGoPkgOpts != nil {
+		err := gen.NewParserGoPkg(b.Dir, b.ParserGoPkgOpts).Run()
+		if err != nil {
+			return nil, fmt.Errorf("distutil.Build: generate error: %w", err)
+		}
+	}
+
+	m := &Manifest{Files: make(map[string]*ManifestFile)}
+
+	wasm, err := b.buildWasm()
+	if err != nil {
+		return nil, err
+	}
+	err = b.writeHashed(m, "main.wasm", wasm)
+	if err != nil {
+		return nil, err
+	}
+
+	wasmExecJS, err := b.wasmExecJS()
+	if err != nil {
+		return nil, err
+	}
+	err = b.writeHashed(m, "wasm_exec.js", wasmExecJS)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range b.Assets {
+		content, err := ioutil.ReadFile(filepath.Join(b.Dir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		err = b.writeHashed(m, name, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pages := 0
+	for _, name := range b.Pages {
+		content, err := ioutil.ReadFile(filepath.Join(b.Dir, filepath.FromSlash(name)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		content = []byte(m.Rewrite(string(content)))
+		err = b.writeFile(m, name, name, content)
+		if err != nil {
+			return nil, err
+		}
+		pages++
+	}
+	if pages == 0 {
+		if b.DefaultPage == "" {
+			return nil, fmt.Errorf("distutil.Build: none of the pages %q exist in %s and DefaultPage is empty", b.Pages, b.Dir)
+		}
+		err = b.writeFile(m, "index.html", "index.html", []byte(m.Rewrite(b.DefaultPage)))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if b.ManifestName != "" {
+		mb, err := json.MarshalIndent(m, "", "\t")
+		if err != nil {
+			return nil, err
+		}
+		err = ioutil.WriteFile(filepath.Join(b.OutDir, b.ManifestName), append(mb, '\n'), 0644)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func (b *Build) buildWasm() ([]byte, error) {
+
+	if b.Compiler != nil {
+		outpath, err := b.Compiler.Execute()
+		if err != nil {
+			return nil, fmt.Errorf("distutil.Build: compile error: %w", err)
+		}
+		defer os.Remove(outpath)
+		return ioutil.ReadFile(outpath)
+	}
+
+	tmpf, err := ioutil.TempFile("", "distutil-build")
+	if err != nil {
+		return nil, err
+	}
+	outpath := tmpf.Name()
+	tmpf.Close()
+	defer os.Remove(outpath)
+
+	cmd := exec.Command("go", "build", "-o", outpath, ".")
+	cmd.Dir = b.Dir
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("distutil.Build: compile error: %w; full output:\n%s", err, out)
+	}
+	return ioutil.ReadFile(outpath)
+}
+
+func (b *Build) wasmExecJS() ([]byte, error) {
+
+	if b.WasmExecJSPath != "" {
+		return ioutil.ReadFile(b.WasmExecJSPath)
+	}
+
+	if wc, ok := b.Compiler.(interface {
+		WasmExecJS() (io.Reader, error)
+	}); ok {
+		r, err := wc.WasmExecJS()
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadAll(r)
+	}
+
+	p, err := WasmExecJsPath()
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(p)
+}
+
+// writeHashed writes content under a name with its hash inserted before the extension and adds it to m
+func (b *Build) writeHashed(m *Manifest, name string, content []byte) error {
+	ext := path.Ext(name)
+	hashedName := fmt.Sprintf("%s.%s%s", strings.TrimSuffix(name, ext), contentHash(content)[:16], ext)
+	return b.writeFile(m, name, hashedName, content)
+}
+
+// writeFile writes content to outName in OutDir, along with its compressed siblings, and adds it to m under name
+func (b *Build) writeFile(m *Manifest, name, outName string, content []byte) error {
+
+	p := filepath.Join(b.OutDir, filepath.FromSlash(outName))
+	err := os.MkdirAll(filepath.Dir(p), 0755)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(p, content, 0644)
+	if err != nil {
+		return err
+	}
+
+	mf := &ManifestFile{Path: outName, Hash: contentHash(content), Size: int64(len(content))}
+
+	if b.Gzip {
+		var buf bytes.Buffer
+		gzw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		gzw.Write(content)
+		err := gzw.Close()
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(p+".gz", buf.Bytes(), 0644)
+		if err != nil {
+			return err
+		}
+		mf.Encodings = append(mf.Encodings, "gzip")
+	}
+
+	if b.Brotli != nil {
+		var buf bytes.Buffer
+		err := b.Brotli(&buf, bytes.NewReader(content))
+		if err != nil {
+			return fmt.Errorf("distutil.Build: brotli error for %q: %w", name, err)
+		}
+		err = ioutil.WriteFile(p+".br", buf.Bytes(), 0644)
+		if err != nil {
+			return err
+		}
+		mf.Encodings = append(mf.Encodings, "br")
+	}
+
+	m.Files[name] = mf
+	return nil
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Rewrite replaces references to the original file names in s with their hashed names.
+// A reference is a file name preceded by a quote, slash, equals sign, opening parenthesis or whitespace
+// and followed by a quote, query string, fragment, closing parenthesis, whitespace or '>',
+// e.g. `src="/wasm_exec.js"` or `fetch("main.wasm")`.  Files whose name is unchanged (pages) are skipped.
+func (m *Manifest) Rewrite(s string) string {
+
+	// longest names first so "a/main.wasm" is not partially matched by "main.wasm"
+	names := make([]string, 0, len(m.Files))
+	for name, mf := range m.Files {
+		if mf.Path != name {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		re := regexp.MustCompile(`(^|["'/=(\s])` + regexp.QuoteMeta(name) + `(["'?#)\s>]|$)`)
+		s = re.ReplaceAllString(s, "${1}"+strings.Replace(m.Files[name].Path, "$", "$$", -1)+"${2}")
+	}
+
+	return s
+}
+
+// ExecBrotli returns a BrotliFunc which runs the `brotli` command line tool with maximum compression,
+// or nil if it is not found in PATH.
+func ExecBrotli() BrotliFunc {
+	p, err := exec.LookPath("brotli")
+	if err != nil {
+		return nil
+	}
+	return func(dst io.Writer, src io.Reader) error {
+		cmd := exec.Command(p, "-c", "-q", "11")
+		cmd.Stdin = src
+		cmd.Stdout = dst
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		if err != nil {
+			return fmt.Errorf("%w; output: %s", err, stderr.Bytes())
+		}
+		return nil
+	}
+}
@@ -0,0 +1,124 @@
+package distutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// buildTestCompiler writes a fixed file as the build output
+type buildTestCompiler struct{ content string }
+
+func (c *buildTestCompiler) Execute() (string, error) {
+	f, err := ioutil.TempFile("", "buildTestCompiler")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	_, err = f.WriteString(c.content)
+	return f.Name(), err
+}
+
+func TestBuild(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "TestBuild")
+	assert.NoError(err)
+	defer os.RemoveAll(tmpDir)
+	outDir := filepath.Join(tmpDir, "dist")
+
+	assert.NoError(ioutil.WriteFile(filepath.Join(tmpDir, "wasm_exec.js"), []byte("// wasm_exec.js"), 0644))
+	assert.NoError(ioutil.WriteFile(filepath.Join(tmpDir, "style.css"), []byte("body { margin: 0 }"), 0644))
+	assert.NoError(ioutil.WriteFile(filepath.Join(tmpDir, "index.html"), []byte(`<link rel="stylesheet" href="/style.css?v=1">`+
+		`<script src="/wasm_exec.js"></script><script>fetch("main.wasm")</script><p>main.wasm.old style.css2</p>`), 0644))
+
+	b := NewBuild(tmpDir, outDir)
+	b.ParserGoPkgOpts = nil
+	b.Compiler = &buildTestCompiler{content: "\x00asm"}
+	b.WasmExecJSPath = filepath.Join(tmpDir, "wasm_exec.js")
+	b.Assets = []string{"style.css"}
+	b.Brotli = func(dst io.Writer, src io.Reader) error { // stand-in so the test does not depend on the brotli command
+		_, err := io.Copy(dst, src)
+		return err
+	}
+	m, err := b.Run()
+	assert.NoError(err)
+
+	wasmPath := m.Files["main.wasm"].Path
+	assert.Regexp(`^main\.[0-9a-f]{16}\.wasm$`, wasmPath)
+	assert.Equal(int64(4), m.Files["main.wasm"].Size)
+	assert.Equal([]string{"gzip", "br"}, m.Files["main.wasm"].Encodings)
+	jsPath, cssPath := m.Files["wasm_exec.js"].Path, m.Files["style.css"].Path
+	assert.Regexp(`^style\.[0-9a-f]{16}\.css$`, cssPath)
+
+	// references are rewritten, other text mentioning the names is not
+	page, err := ioutil.ReadFile(filepath.Join(outDir, "index.html"))
+	assert.NoError(err)
+	assert.Equal(`<link rel="stylesheet" href="/`+cssPath+`?v=1">`+
+		`<script src="/`+jsPath+`"></script><script>fetch("`+wasmPath+`")</script><p>main.wasm.old style.css2</p>`, string(page))
+	assert.Equal("index.html", m.Files["index.html"].Path)
+
+	// compressed siblings
+	gzb, err := ioutil.ReadFile(filepath.Join(outDir, wasmPath+".gz"))
+	assert.NoError(err)
+	gzr, err := gzip.NewReader(bytes.NewReader(gzb))
+	assert.NoError(err)
+	wasm, err := ioutil.ReadAll(gzr)
+	assert.NoError(err)
+	assert.Equal("\x00asm", string(wasm))
+	assert.FileExists(filepath.Join(outDir, wasmPath+".br"))
+
+	// manifest
+	mb, err := ioutil.ReadFile(filepath.Join(outDir, DefaultManifestName))
+	assert.NoError(err)
+	var m2 Manifest
+	assert.NoError(json.Unmarshal(mb, &m2))
+	assert.Equal(m, &m2)
+
+	// same content gives the same names
+	b.Compiler = &buildTestCompiler{content: "\x00asm"}
+	m3, err := b.Run()
+	assert.NoError(err)
+	assert.Equal(wasmPath, m3.Files["main.wasm"].Path)
+	assert.True(strings.HasPrefix(m3.Rewrite(`"/main.wasm"`), `"/main.`))
+}
+
+func TestBuildDefaultPage(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "TestBuildDefaultPage")
+	assert.NoError(err)
+	defer os.RemoveAll(tmpDir)
+	outDir := filepath.Join(tmpDir, "dist")
+	assert.NoError(ioutil.WriteFile(filepath.Join(tmpDir, "wasm_exec.js"), []byte("// wasm_exec.js"), 0644))
+
+	// no index.html in the project, DefaultPage is written with the hashed names
+	b := NewBuild(tmpDir, outDir)
+	b.ParserGoPkgOpts = nil
+	b.Compiler = &buildTestCompiler{content: "\x00asm"}
+	b.WasmExecJSPath = filepath.Join(tmpDir, "wasm_exec.js")
+	b.Brotli = nil
+	m, err := b.Run()
+	assert.NoError(err)
+	page, err := ioutil.ReadFile(filepath.Join(outDir, "index.html"))
+	assert.NoError(err)
+	assert.Contains(string(page), `fetch("/`+m.Files["main.wasm"].Path+`")`)
+	assert.Contains(string(page), `<script src="/`+m.Files["wasm_exec.js"].Path+`"></script>`)
+	assert.Equal("index.html", m.Files["index.html"].Path)
+
+	// without a DefaultPage it is an error
+	b.DefaultPage = ""
+	b.Compiler = &buildTestCompiler{content: "\x00asm"}
+	_, err = b.Run()
+	assert.Error(err)
+}
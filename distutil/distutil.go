@@ -31,5 +31,9 @@ Executing a command while overriding certain environment variables is also easy:
 		[]string{"GOOS=js", "GOARCH=wasm"},
 		"go", "build", "-o", filepath.Join(outDir, "main.wasm"), "."))
 
+Or do a complete production build with content-hashed file names, precompressed files and a manifest:
+
+	distutil.NewBuild(".", outDir).MustRun()
+
 */
 package distutil
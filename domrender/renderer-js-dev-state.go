@@ -0,0 +1,76 @@
+package domrender
+
+import (
+	"fmt"
+
+	"github.com/vugu/vugu"
+
+	js "github.com/vugu/vugu/js"
+)
+
+// DevStateStorageKey is the sessionStorage key used by PreserveState, followed by the mount point selector.
+const DevStateStorageKey = "vugu:dev-state:"
+
+// PreserveState makes the state of the component tree survive page reloads, which is useful during
+// development so a live reload after an edit does not put the app back to its initial state.
+// If state was saved by a previous page load it is restored into root (and into child components as they
+// are created during the following builds), and a beforeunload handler is registered which saves it
+// to sessionStorage.  It should be called before the first build, e.g.:
+//
+//	rootBuilder := &Root{}
+//	err = renderer.PreserveState(buildEnv, rootBuilder)
+//
+// See BuildEnv.SaveState for which fields are saved.  Saved state which no longer fits the code
+// (e.g. because the type of a field changed) is skipped and printed after the render that found it.
+// It should not be used in production, since old state would be restored into a new version of the app.
+//
+// State can not be saved under TinyGo, PreserveState returns an error there.
+func (r *JSRenderer) PreserveState(buildEnv *vugu.BuildEnv, root vugu.Builder) error {
+
+	storage := r.window.Get("sessionStorage")
+	if !storage.Truthy() {
+		return fmt.Errorf("PreserveState: sessionStorage not available")
+	}
+
+	// fail now rather than in the beforeunload handler if state cannot be saved (e.g. under TinyGo)
+	if _, err := buildEnv.SaveState(root); err != nil {
+		return fmt.Errorf("PreserveState: %w", err)
+	}
+	key := DevStateStorageKey + r.MountPointSelector
+
+	r.stateBuildEnv = buildEnv
+
+	saved := storage.Call("getItem", key)
+	storage.Call("removeItem", key)
+	if saved.Type() == js.TypeString {
+		err := buildEnv.RestoreState(root, []byte(saved.String()))
+		if err != nil {
+			// not fatal, we just start with the initial state
+			fmt.Println("vugu: saved state not restored:", err)
+		}
+	}
+
+	r.window.Call("addEventListener", "beforeunload", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		r.eventRWMU.Lock()
+		defer r.eventRWMU.Unlock()
+		b, err := buildEnv.SaveState(root)
+		if err != nil {
+			fmt.Println("vugu: unable to save state:", err)
+			return nil
+		}
+		storage.Call("setItem", key, string(b))
+		return nil
+	}))
+
+	return nil
+}
+
+// reportStateMismatches prints any problems found restoring state during the last build
+func (r *JSRenderer) reportStateMismatches() {
+	if r.stateBuildEnv == nil {
+		return
+	}
+	for _, m := range r.stateBuildEnv.StateMismatches() {
+		fmt.Println("vugu: state not restored:", m)
+	}
+}
@@ -0,0 +1,22 @@
+// +build vugudev
+
+package domrender
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hydrateCheck enables verification of position markers while hydrating
+const hydrateCheck = true
+
+// reportHydrateMismatches prints any differences found between the server rendered DOM and the first render.
+func (r *JSRenderer) reportHydrateMismatches() {
+	s := r.window.Call("vuguTakeHydrateMismatches").String()
+	if s == "" {
+		return
+	}
+	for _, m := range strings.Split(s, "\n") {
+		fmt.Println("vugu: hydrate mismatch:", m)
+	}
+}
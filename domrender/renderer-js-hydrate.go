@@ -0,0 +1,11 @@
+// +build !vugudev
+
+package domrender
+
+// hydrateCheck enables verification of position markers while hydrating, only with the vugudev tag
+const hydrateCheck = false
+
+// reportHydrateMismatches discards the recorded mismatches, they are only printed with the vugudev tag.
+func (r *JSRenderer) reportHydrateMismatches() {
+	r.window.Call("vuguTakeHydrateMismatches")
+}
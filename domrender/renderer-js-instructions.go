@@ -63,6 +63,12 @@ const (
 	opcodeCallback            uint8 = 40 // issue callback, sends just callbackID
 	opcodeCallbackLastElement uint8 = 41 // issue callback with callbackID and most recent element reference
 
+	opcodeRemoveChildrenQuery uint8 = 42 // remove all children from the element matching a selector, if it exists
+
+	opcodeSetHydrate        uint8 = 43 // turn hydrate mode on or off, existing DOM is adopted and differences recorded as mismatches
+	opcodeHydrateMountPoint uint8 = 44 // locate the mount point by its position marker if the selector has not been used yet
+	opcodeHydrateCheck      uint8 = 45 // record a mismatch if the position marker of the current element is not as expected
+
 )
 
 // newInstructionList will create a new instance backed by the specified slice and with a clearBufFunc
@@ -532,6 +538,63 @@ func (il *instructionList) writeCallbackLastElement(callbackID uint32) error {
 	return nil
 }
 
+func (il *instructionList) writeRemoveChildrenQuery(selector string) error {
+
+	il.logf("writeRemoveChildrenQuery[%d](selector=%q)", opcodeRemoveChildrenQuery, selector)
+
+	err := il.checkLenAndFlush(5 + len(selector))
+	if err != nil {
+		return err
+	}
+	il.writeValUint8(opcodeRemoveChildrenQuery)
+	il.writeValString(selector)
+	return nil
+}
+
+func (il *instructionList) writeSetHydrate(hydrate bool) error {
+
+	il.logf("writeSetHydrate[%d](hydrate=%v)", opcodeSetHydrate, hydrate)
+
+	err := il.checkLenAndFlush(2)
+	if err != nil {
+		return err
+	}
+	il.writeValUint8(opcodeSetHydrate)
+	hydrateB := uint8(0)
+	if hydrate {
+		hydrateB = 1
+	}
+	il.writeValUint8(hydrateB)
+	return nil
+}
+
+func (il *instructionList) writeHydrateMountPoint(selector string, positionID []byte) error {
+
+	il.logf("writeHydrateMountPoint[%d](selector=%q, positionID=%q)", opcodeHydrateMountPoint, selector, positionID)
+
+	err := il.checkLenAndFlush(len(selector) + len(positionID) + 9)
+	if err != nil {
+		return err
+	}
+	il.writeValUint8(opcodeHydrateMountPoint)
+	il.writeValString(selector)
+	il.writeValBytes(positionID)
+	return nil
+}
+
+func (il *instructionList) writeHydrateCheck(positionID []byte) error {
+
+	il.logf("writeHydrateCheck[%d](positionID=%q)", opcodeHydrateCheck, positionID)
+
+	err := il.checkLenAndFlush(len(positionID) + 5)
+	if err != nil {
+		return err
+	}
+	il.writeValUint8(opcodeHydrateCheck)
+	il.writeValBytes(positionID)
+	return nil
+}
+
 func (il *instructionList) writeValUint8(b uint8) {
 	il.buf[il.pos] = b
 	il.pos++
@@ -0,0 +1,165 @@
+package domrender
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/vugu/vugu"
+)
+
+// Island is an independent root component rendered into its own mount point.
+// Several islands can be placed on one page (e.g. widgets embedded in a server-rendered app),
+// each with its own BuildEnv, all sharing the event loop of the JSRenderer that created them.
+// Islands are created with JSRenderer.AddIsland and rendered with JSRenderer.RenderIslands.
+type Island struct {
+	MountPointSelector string
+	BuildEnv           *vugu.BuildEnv
+	Root               vugu.Builder
+
+	r             *JSRenderer
+	positionID    []byte             // root position ID, distinct for each island so event handlers do not collide
+	portalTargets map[string]bool    // portal targets used by the last render of this island
+	lastResults   *vugu.BuildResults // from the last build, needed so other islands can keep our CSS
+	needsRender   bool               // set when an event for this island occurs
+	rendered      bool               // set after the first render, so JSRenderer.Hydrate only applies to it
+	eventEnv      *islandEventEnv
+}
+
+// EventEnv returns an EventEnv for this island.  It shares the renderer's lock but UnlockRender
+// causes only this island to be re-rendered.
+func (is *Island) EventEnv() vugu.EventEnv {
+	return is.eventEnv
+}
+
+// islandEventEnv marks the island as needing render before requesting a render from the renderer
+type islandEventEnv struct {
+	*vugu.EventEnvImpl
+	is *Island
+}
+
+// UnlockRender marks the island for render, then releases the write lock and requests a re-render.
+func (ee *islandEventEnv) UnlockRender() {
+	ee.is.needsRender = true // still holding write lock here
+	ee.EventEnvImpl.UnlockRender()
+}
+
+// AddIsland creates a new island which renders root into the element matched by mountPointSelector.
+// A new BuildEnv is created for the island, the caller can use it to set up wiring before the
+// first call to RenderIslands.  Islands are used instead of Render, the two should not be mixed
+// on the same JSRenderer.
+//
+// The root element of the island has position ID "i1" for the first island added, "i2" for the second
+// and so on.  To hydrate an island, render its markup on the server with the same ID,
+// see staticrender.StaticRenderer.SetRootPositionID.
+func (r *JSRenderer) AddIsland(mountPointSelector string, root vugu.Builder) (*Island, error) {
+
+	if mountPointSelector == "" {
+		return nil, errors.New("island must have a mount point selector")
+	}
+	for _, is := range r.islands {
+		if is.MountPointSelector == mountPointSelector {
+			return nil, errors.New("island already exists for mount point selector: " + mountPointSelector)
+		}
+	}
+
+	buildEnv, err := vugu.NewBuildEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	is := &Island{
+		MountPointSelector: mountPointSelector,
+		BuildEnv:           buildEnv,
+		Root:               root,
+		r:                  r,
+		positionID:         []byte("i" + strconv.Itoa(len(r.islands)+1)),
+		portalTargets:      make(map[string]bool),
+		needsRender:        true, // always render the first time
+	}
+	is.eventEnv = &islandEventEnv{
+		EventEnvImpl: vugu.NewEventEnvImpl(&r.eventRWMU, r.eventWaitCh),
+		is:           is,
+	}
+
+	r.islands = append(r.islands, is)
+
+	return is, nil
+}
+
+// Islands returns the islands created with AddIsland, in the order they were added.
+func (r *JSRenderer) Islands() []*Island {
+	return r.islands
+}
+
+// RenderIslands builds and renders each island which needs it: islands that have not been rendered yet,
+// islands where a DOM event occurred and islands whose EventEnv requested a render.  If nothing in
+// particular was marked (e.g. the renderer's own EventEnv was used), all islands are rendered.
+// This is intended to be called in the render loop in place of RunBuild and Render:
+//
+//	for ok := true; ok; ok = renderer.EventWait() {
+//		err = renderer.RenderIslands()
+//		...
+//	}
+func (r *JSRenderer) RenderIslands() error {
+
+	// acquire read lock so events are not changing data while building and rendering
+	r.eventRWMU.RLock()
+
+	marked := false
+	for _, is := range r.islands {
+		if is.needsRender {
+			marked = true
+			break
+		}
+	}
+
+	var err error
+	for _, is := range r.islands {
+		if marked && !is.needsRender {
+			continue
+		}
+		err = is.render()
+		if err != nil {
+			break
+		}
+	}
+
+	// for now, not using defer so this works the same in Tinygo
+	r.eventRWMU.RUnlock()
+
+	return err
+}
+
+// render builds and renders this island
+func (is *Island) render() error {
+
+	r := is.r
+
+	is.lastResults = is.BuildEnv.RunBuild(is.Root)
+	is.needsRender = false
+
+	// the CSS for other islands is included from their last build so it is not removed
+	cssResults := make([]*vugu.BuildResults, 0, len(r.islands))
+	for _, is2 := range r.islands {
+		if is2.lastResults != nil {
+			cssResults = append(cssResults, is2.lastResults)
+		}
+	}
+
+	hydrate := r.Hydrate && !is.rendered
+	is.rendered = true
+
+	return r.renderRoot(is.MountPointSelector, is.positionID, is.portalTargets, hydrate, is.lastResults, cssResults)
+}
+
+// markIslandForPosition marks the island that a DOM element position ID belongs to as needing render.
+// Must be called with the write lock held.
+func (r *JSRenderer) markIslandForPosition(positionID string) {
+	for _, is := range r.islands {
+		pid := string(is.positionID)
+		if positionID == pid || (len(positionID) > len(pid) && positionID[:len(pid)] == pid && positionID[len(pid)] == '_') {
+			is.needsRender = true
+			return
+		}
+	}
+}
@@ -46,6 +46,22 @@
     const opcodeCallback = 40 // issue callback, sends just callbackID
     const opcodeCallbackLastElement = 41 // issue callback with callbackID and most recent element reference
 
+    const opcodeRemoveChildrenQuery = 42 // remove all children from the element matching a selector, if it exists
+
+    const opcodeSetHydrate = 43 // turn hydrate mode on or off, existing DOM is adopted and differences recorded as mismatches
+    const opcodeHydrateMountPoint = 44 // locate the mount point by its position marker if the selector has not been used yet
+    const opcodeHydrateCheck = 45 // record a mismatch if the position marker of the current element is not as expected
+
+    // attribute emitted by the static renderer with the position ID of each element
+    const positionMarkerAttr = "data-vgpos";
+
+    // hydrateMismatch records a difference between the existing DOM and what was rendered, only while hydrating
+    function hydrateMismatch(state, msg) {
+        if (state.hydrating) {
+            state.hydrateMismatches.push(msg);
+        }
+    }
+
     /*DEBUG OPCODE STRINGS*/
 
     // Decoder provides our binary decoding.
@@ -146,6 +162,14 @@
         state.callbackHandlerFunc = callbackHandlerFunc;
     }
 
+    // returns the mismatches recorded during the last hydrate, one per line, and clears them
+    window.vuguTakeHydrateMismatches = function () {
+        let state = window.vuguState || {};
+        let ret = (state.hydrateMismatches || []).join("\n");
+        state.hydrateMismatches = [];
+        return ret;
+    }
+
     window.vuguGetRenderArray = function () {
         if (!window.vuguRenderArray) {
             window.vuguRenderArray = new Uint8Array(16384);
@@ -182,8 +206,8 @@
         // state.curRefEl = state.curRefEl || null; // current reference element
         // state.elStack = state.elStack || []; // stack of elements as we traverse the DOM tree
 
-        // mount point element
-        state.mountPointEl = state.mountPointEl || null;
+        // mount point elements, by selector (there is more than one when islands are used)
+        state.mountPointEls = state.mountPointEls || {};
 
         // currently selected element
         state.el = state.el || null;
@@ -209,6 +233,10 @@
         // keeps track of event listeners that are being set on the current element, so we can remvoe any extras
         state.elEventKeys = state.elEventKeys || {};
 
+        // when hydrating, differences from the existing (server rendered) DOM are recorded here
+        state.hydrating = state.hydrating || false;
+        state.hydrateMismatches = state.hydrateMismatches || [];
+
         instructionLoop: while (true) {
 
             let opcode = decoder.readUint8();
@@ -249,6 +277,61 @@
                         break;
                     }
 
+                    case opcodeRemoveChildrenQuery: {
+                        let selector = decoder.readString();
+                        /*DEBUG*/ console.log("opcodeRemoveChildrenQuery", selector);
+                        let el = document.querySelector(selector);
+                        if (el) {
+                            while (el.firstChild) {
+                                el.removeChild(el.firstChild);
+                            }
+                        }
+                        state.el = el;
+                        state.nextElMove = null;
+                        break;
+                    }
+
+                    case opcodeSetHydrate: {
+                        let hydrate = decoder.readUint8();
+                        /*DEBUG*/ console.log("opcodeSetHydrate", hydrate);
+                        state.hydrating = !!hydrate;
+                        if (state.hydrating) {
+                            state.hydrateMismatches = [];
+                        }
+                        break;
+                    }
+
+                    case opcodeHydrateMountPoint: {
+                        let selector = decoder.readString();
+                        let positionID = decoder.readString();
+                        /*DEBUG*/ console.log("opcodeHydrateMountPoint", selector, positionID);
+                        // the server rendered root usually replaced the element the selector would match
+                        if (!state.mountPointEls[selector]) {
+                            let el = document.querySelector("[" + positionMarkerAttr + "=\"" + positionID + "\"]");
+                            // for a full document the mount point shares its position with body
+                            if (el && el.nodeName.toUpperCase() == "BODY") {
+                                el = el.firstElementChild;
+                            }
+                            if (el) {
+                                state.mountPointEls[selector] = el;
+                            }
+                        }
+                        break;
+                    }
+
+                    case opcodeHydrateCheck: {
+                        let positionID = decoder.readString();
+                        /*DEBUG*/ console.log("opcodeHydrateCheck", positionID);
+                        let el = state.el;
+                        if (el && el.nodeType == 1) {
+                            let elPositionID = el.getAttribute(positionMarkerAttr);
+                            if (elPositionID != null && elPositionID != positionID) {
+                                hydrateMismatch(state, "position " + positionID + ": found <" + el.nodeName.toLowerCase() + "> with position marker " + elPositionID);
+                            }
+                        }
+                        break;
+                    }
+
                     case opcodeSetAttrStr: {
                         let el = state.el;
                         if (!el) {
@@ -293,19 +376,17 @@
                         /*DEBUG*/ console.log("opcodeSelectMountPoint", selector, nodeName);
 
                         // console.log("GOT HERE selector,nodeName = ", selector, nodeName);
-                        // console.log("state.mountPointEl", state.mountPointEl);
-                        if (state.mountPointEl) {
-                            // console.log("opcodeSelectMountPoint: state.mountPointEl already exists, using it", state.mountPointEl, "parent is", state.mountPointEl.parentNode);
-                            state.el = state.mountPointEl;
-                            // state.elStack.push(state.mountPointEl);
+                        // console.log("state.mountPointEls[selector]", state.mountPointEls[selector]);
+                        if (state.mountPointEls[selector]) {
+                            // console.log("opcodeSelectMountPoint: mount point already exists, using it", state.mountPointEls[selector]);
+                            state.el = state.mountPointEls[selector];
                         } else {
-                            // console.log("opcodeSelectMountPoint: state.mountPointEl does not exist, using selector to find it", selector);
+                            // console.log("opcodeSelectMountPoint: mount point does not exist, using selector to find it", selector);
                             let el = document.querySelector(selector);
                             if (!el) {
                                 throw "mount point selector not found: " + selector;
                             }
-                            state.mountPointEl = el;
-                            // state.elStack.push(el);
+                            state.mountPointEls[selector] = el;
                             state.el = el;
                         }
 
@@ -314,10 +395,11 @@
                         // make sure it's the right element name and replace if not
                         if (el.nodeName.toUpperCase() != nodeName.toUpperCase()) {
 
+                            hydrateMismatch(state, "mount point " + selector + ": replaced <" + el.nodeName.toLowerCase() + "> with <" + nodeName.toLowerCase() + ">");
                             let newEl = document.createElement(nodeName);
                             el.parentNode.replaceChild(newEl, el);
 
-                            state.mountPointEl = newEl;
+                            state.mountPointEls[selector] = newEl;
                             el = newEl;
 
                         }
@@ -369,6 +451,7 @@
                             let p = state.el.parentNode;
                             let e = state.el;
                             while (e.nextSibling) {
+                                hydrateMismatch(state, "removed extra " + e.nextSibling.nodeName.toLowerCase() + " after " + e.nodeName.toLowerCase());
                                 p.removeChild(e.nextSibling);
                             }
 
@@ -456,6 +539,7 @@
                                 state.el = newEl;
                                 // fall through to verify state.el is correct below
                             } else {
+                                hydrateMismatch(state, "created missing <" + nodeName.toLowerCase() + ">");
                                 newEl = document.createElement(nodeName);
                                 state.el.appendChild(newEl);
                                 state.el = newEl;
@@ -468,6 +552,7 @@
                                 state.el = newEl;
                                 // fall through to verify state.el is correct below
                             } else {
+                                hydrateMismatch(state, "created missing <" + nodeName.toLowerCase() + ">");
                                 newEl = document.createElement(nodeName);
                                 // console.log("HERE1", state.el);
                                 // state.el.insertAdjacentElement(newEl, 'afterend');
@@ -484,6 +569,7 @@
 
                         if (state.el.nodeType != 1 || state.el.nodeName.toUpperCase() != nodeName.toUpperCase()) {
 
+                            hydrateMismatch(state, "replaced " + state.el.nodeName.toLowerCase() + " with <" + nodeName.toLowerCase() + ">");
                             let newEl = document.createElement(nodeName);
                             // throw "stopping here";
                             state.el.parentNode.replaceChild(newEl, state.el);
@@ -513,6 +599,7 @@
                                 state.el = newEl;
                                 // fall through to verify state.el is correct below
                             } else {
+                                hydrateMismatch(state, "created missing <" + nodeName.toLowerCase() + ">");
                                 newEl = document.createElementNS(namespace, nodeName);
                                 state.el.appendChild(newEl);
                                 state.el = newEl;
@@ -525,6 +612,7 @@
                                 state.el = newEl;
                                 // fall through to verify state.el is correct below
                             } else {
+                                hydrateMismatch(state, "created missing <" + nodeName.toLowerCase() + ">");
                                 newEl = document.createElementNS(namespace, nodeName);
                                 // console.log("HERE1", state.el);
                                 // state.el.insertAdjacentElement(newEl, 'afterend');
@@ -541,6 +629,7 @@
 
                         if (state.el.nodeType != 1 || state.el.nodeName.toUpperCase() != nodeName.toUpperCase()) {
 
+                            hydrateMismatch(state, "replaced " + state.el.nodeName.toLowerCase() + " with <" + nodeName.toLowerCase() + ">");
                             let newEl = document.createElementNS(namespace, nodeName);
                             // throw "stopping here";
                             state.el.parentNode.replaceChild(newEl, state.el);
@@ -570,6 +659,9 @@
                                 state.el = newEl;
                                 // fall through to verify state.el is correct below
                             } else {
+                                if (content != "") { // empty text produces no node in server output
+                                    hydrateMismatch(state, "created missing text " + JSON.stringify(content));
+                                }
                                 let newEl = document.createTextNode(content);
                                 state.el.appendChild(newEl);
                                 state.el = newEl;
@@ -584,6 +676,9 @@
                                 state.el = newEl;
                                 // fall through to verify state.el is correct below
                             } else {
+                                if (content != "") {
+                                    hydrateMismatch(state, "created missing text " + JSON.stringify(content));
+                                }
                                 let newEl = document.createTextNode(content);
                                 // state.el.insertAdjacentElement(newEl, 'afterend');
                                 state.el.parentNode.appendChild(newEl);
@@ -602,12 +697,29 @@
                         if (state.el.nodeType != 3) {
 
                             let newEl = document.createTextNode(content);
-                            state.el.parentNode.replaceChild(newEl, state.el);
+                            if (state.hydrating) {
+                                // text missing from the server output (e.g. empty), insert it and leave the node for the next instruction
+                                if (content != "") {
+                                    hydrateMismatch(state, "inserted missing text " + JSON.stringify(content) + " before " + state.el.nodeName.toLowerCase());
+                                }
+                                state.el.parentNode.insertBefore(newEl, state.el);
+                            } else {
+                                state.el.parentNode.replaceChild(newEl, state.el);
+                            }
                             state.el = newEl;
                             // console.log("in opcodeSetText 7");
 
                         } else {
                             // console.log("in opcodeSetText 8");
+                            if (state.hydrating && state.el.textContent != content) {
+                                let existing = state.el.textContent;
+                                if (content != "" && existing.length > content.length && existing.startsWith(content)) {
+                                    // the HTML parser merges adjacent text nodes, split them back apart
+                                    state.el.splitText(content.length);
+                                } else {
+                                    hydrateMismatch(state, "text " + JSON.stringify(existing) + " changed to " + JSON.stringify(content));
+                                }
+                            }
                             state.el.textContent = content;
                         }
                         // console.log("in opcodeSetText 9");
@@ -631,6 +743,7 @@
                                 state.el = newEl;
                                 // fall through to verify state.el is correct below
                             } else {
+                                hydrateMismatch(state, "created missing comment");
                                 let newEl = document.createComment(content);
                                 state.el.appendChild(newEl);
                                 state.el = newEl;
@@ -643,6 +756,7 @@
                                 state.el = newEl;
                                 // fall through to verify state.el is correct below
                             } else {
+                                hydrateMismatch(state, "created missing comment");
                                 let newEl = document.createComment(content);
                                 // state.el.insertAdjacentElement(newEl, 'afterend');
                                 state.el.parentNode.appendChild(newEl);
@@ -658,6 +772,7 @@
 
                         if (state.el.nodeType != 8) {
 
+                            hydrateMismatch(state, "replaced " + state.el.nodeName.toLowerCase() + " with comment");
                             let newEl = document.createComment(content);
                             state.el.parentNode.replaceChild(newEl, state.el);
                             state.el = newEl;
@@ -104,6 +104,19 @@ type jsRenderState struct {
 
 	// callback stuff is handled by callbackManager
 	callbackManager callbackManager
+
+	// portals encountered during this render, synced after the main output
+	portalQueue []jsPortal
+
+	// true while rendering on top of existing server rendered DOM
+	hydrating bool
+}
+
+// jsPortal is a portal found during render whose children still need to be synced into its target
+type jsPortal struct {
+	bo         *vugu.BuildOut
+	n          *vugu.VGNode
+	positionID []byte
 }
 
 func newJsRenderState() *jsRenderState {
@@ -116,6 +129,13 @@ func newJsRenderState() *jsRenderState {
 type JSRenderer struct {
 	MountPointSelector string
 
+	// Hydrate causes the first render to adopt the DOM already on the page instead of
+	// assuming it is empty, as produced by staticrender with position markers enabled.
+	// Existing elements are kept, event listeners are attached and vg-js-create callbacks
+	// are run against them.  Anything that does not match is corrected, and when built with
+	// the vugudev tag each mismatch is printed.
+	Hydrate bool
+
 	eventWaitCh chan bool          // events send to this and EventWait receives from it
 	eventRWMU   sync.RWMutex       // make sure Render and event handling are not attempted at the same time (not totally sure if this is necessary in terms of the wasm threading model but enforce it with a rwmutex all the same)
 	eventEnv    *vugu.EventEnvImpl // our EventEnv implementation that exposes eventRWMU and eventWaitCh to events in a clean way
@@ -131,6 +151,18 @@ type JSRenderer struct {
 	window js.Value
 
 	jsRenderState *jsRenderState
+
+	// portal targets which had content synced into them during the last render of the main root
+	portalTargets map[string]bool
+
+	// set after the first render, so Hydrate only applies to it
+	rendered bool
+
+	// independent root components, see AddIsland
+	islands []*Island
+
+	// set by PreserveState
+	stateBuildEnv *vugu.BuildEnv
 }
 
 // EventEnv returns an EventEnv that can be used for synchronizing updates.
@@ -147,6 +179,20 @@ func (r *JSRenderer) Release() {
 
 // Render implements Renderer.
 func (r *JSRenderer) render(buildResults *vugu.BuildResults) error {
+	if r.portalTargets == nil {
+		r.portalTargets = make(map[string]bool)
+	}
+	hydrate := r.Hydrate && !r.rendered
+	r.rendered = true
+	r.reportStateMismatches()
+	return r.renderRoot(r.MountPointSelector, []byte("0"), r.portalTargets, hydrate, buildResults, []*vugu.BuildResults{buildResults})
+}
+
+// renderRoot syncs the output of one root component to the DOM.  The CSS from every entry in cssResults
+// is synced (any other CSS tags Vugu created are removed), which allows several independent roots to share the page.
+// If hydrate is true the existing DOM under the mount point is adopted, see JSRenderer.Hydrate.
+func (r *JSRenderer) renderRoot(mountPointSelector string, positionID []byte, portalTargets map[string]bool, hydrate bool,
+	buildResults *vugu.BuildResults, cssResults []*vugu.BuildResults) error {
 
 	bo := buildResults.Out
 
@@ -212,37 +258,54 @@ func (r *JSRenderer) render(buildResults *vugu.BuildResults) error {
 		return nil
 	}
 
-	var walkCSSBuildOut func(buildOut *vugu.BuildOut) error
-	walkCSSBuildOut = func(buildOut *vugu.BuildOut) error {
+	var walkCSSBuildOut func(br *vugu.BuildResults, buildOut *vugu.BuildOut) error
+	walkCSSBuildOut = func(br *vugu.BuildResults, buildOut *vugu.BuildOut) error {
 		err := visitCSSList(buildOut.CSS)
 		if err != nil {
 			return err
 		}
 		for _, c := range buildOut.Components {
 			// nextBuildOut := buildResults.AllOut[c]
-			nextBuildOut := buildResults.ResultFor(c)
+			nextBuildOut := br.ResultFor(c)
 			if nextBuildOut == nil {
 				panic(fmt.Errorf("walkCSSBuildOut nextBuildOut was nil for %#v", c))
 			}
-			err := walkCSSBuildOut(nextBuildOut)
+			err := walkCSSBuildOut(br, nextBuildOut)
 			if err != nil {
 				return err
 			}
 		}
 		return nil
 	}
-	err := walkCSSBuildOut(bo)
+	for _, cssbr := range cssResults {
+		err := walkCSSBuildOut(cssbr, cssbr.Out)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := r.instructionList.writeRemoveOtherCSSTags()
 	if err != nil {
 		return err
 	}
 
-	err = r.instructionList.writeRemoveOtherCSSTags()
+	state.hydrating = hydrate
+	if hydrate {
+		err = r.instructionList.writeSetHydrate(true)
+		if err != nil {
+			return err
+		}
+	}
+
+	// main output
+	state.portalQueue = state.portalQueue[:0]
+	err = r.visitFirst(state, bo, buildResults, bo.Out[0], mountPointSelector, positionID)
 	if err != nil {
 		return err
 	}
 
-	// main output
-	err = r.visitFirst(state, bo, buildResults, bo.Out[0], []byte("0"))
+	// portal content goes into its target elements
+	err = r.visitPortals(state, buildResults, portalTargets)
 	if err != nil {
 		return err
 	}
@@ -250,11 +313,23 @@ func (r *JSRenderer) render(buildResults *vugu.BuildResults) error {
 	// // JS stuff last
 	// // log.Printf("TODO: handle JS")
 
+	if hydrate {
+		state.hydrating = false
+		err = r.instructionList.writeSetHydrate(false)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = r.instructionList.flush()
 	if err != nil {
 		return err
 	}
 
+	if hydrate {
+		r.reportHydrateMismatches()
+	}
+
 	return nil
 
 }
@@ -287,7 +362,7 @@ func (r *JSRenderer) EventWait() (ok bool) {
 // 	}
 // }
 
-func (r *JSRenderer) visitFirst(state *jsRenderState, bo *vugu.BuildOut, br *vugu.BuildResults, n *vugu.VGNode, positionID []byte) error {
+func (r *JSRenderer) visitFirst(state *jsRenderState, bo *vugu.BuildOut, br *vugu.BuildResults, n *vugu.VGNode, mountPointSelector string, positionID []byte) error {
 
 	// log.Printf("TODO: We need to go through and optimize away unneeded calls to create elements, set attributes, set event handlers, etc. for cases where they are the same per hash")
 
@@ -321,7 +396,7 @@ func (r *JSRenderer) visitFirst(state *jsRenderState, bo *vugu.BuildOut, br *vug
 
 			} else if strings.ToLower(nchild.Data) == "body" {
 
-				err := r.visitBody(state, bo, br, nchild, []byte("body"))
+				err := r.visitBody(state, bo, br, nchild, mountPointSelector, []byte("body"))
 				if err != nil {
 					return err
 				}
@@ -336,7 +411,7 @@ func (r *JSRenderer) visitFirst(state *jsRenderState, bo *vugu.BuildOut, br *vug
 	}
 
 	// else, first tag is anything else - try again as the element to be mounted
-	return r.visitMount(state, bo, br, n, positionID)
+	return r.visitMount(state, bo, br, n, mountPointSelector, positionID)
 
 }
 
@@ -362,7 +437,7 @@ func (r *JSRenderer) visitHead(state *jsRenderState, bo *vugu.BuildOut, br *vugu
 	return nil
 }
 
-func (r *JSRenderer) visitBody(state *jsRenderState, bo *vugu.BuildOut, br *vugu.BuildResults, n *vugu.VGNode, positionID []byte) error {
+func (r *JSRenderer) visitBody(state *jsRenderState, bo *vugu.BuildOut, br *vugu.BuildResults, n *vugu.VGNode, mountPointSelector string, positionID []byte) error {
 
 	err := r.instructionList.writeSelectQuery("body")
 	if err != nil {
@@ -377,18 +452,33 @@ func (r *JSRenderer) visitBody(state *jsRenderState, bo *vugu.BuildOut, br *vugu
 		return errors.New("body tag must contain exactly one element child")
 	}
 
-	return r.visitMount(state, bo, br, n.FirstChild, positionID)
+	return r.visitMount(state, bo, br, n.FirstChild, mountPointSelector, positionID)
 }
 
-func (r *JSRenderer) visitMount(state *jsRenderState, bo *vugu.BuildOut, br *vugu.BuildResults, n *vugu.VGNode, positionID []byte) error {
+func (r *JSRenderer) visitMount(state *jsRenderState, bo *vugu.BuildOut, br *vugu.BuildResults, n *vugu.VGNode, mountPointSelector string, positionID []byte) error {
 
 	// log.Printf("visitMount got here")
 
-	err := r.instructionList.writeSelectMountPoint(r.MountPointSelector, n.Data)
+	// the server rendered root element has replaced whatever the selector matched, find it by position instead
+	if state.hydrating {
+		err := r.instructionList.writeHydrateMountPoint(mountPointSelector, positionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := r.instructionList.writeSelectMountPoint(mountPointSelector, n.Data)
 	if err != nil {
 		return err
 	}
 
+	if state.hydrating && hydrateCheck {
+		err = r.instructionList.writeHydrateCheck(positionID)
+		if err != nil {
+			return err
+		}
+	}
+
 	return r.visitSyncElementEtc(state, bo, br, n, positionID)
 
 }
@@ -409,6 +499,16 @@ func (r *JSRenderer) visitSyncNode(state *jsRenderState, bo *vugu.BuildOut, br *
 		return r.visitSyncNode(state, compBuildOut, br, compBuildOut.Out[0], positionID)
 	}
 
+	// check for portal, the children are synced later into the target and a placeholder is output here
+	if n.IsPortal() {
+		state.portalQueue = append(state.portalQueue, jsPortal{
+			bo:         bo,
+			n:          n,
+			positionID: append([]byte(nil), positionID...), // copy since positionID's backing array is reused
+		})
+		return r.instructionList.writeSetComment(vugu.PortalPlaceholder)
+	}
+
 	// check for template (used by vg-template and vg-slot) in which case we process the children directly and ignore n
 	if n.IsTemplate() {
 
@@ -450,6 +550,12 @@ func (r *JSRenderer) visitSyncNode(state *jsRenderState, bo *vugu.BuildOut, br *
 		if err != nil {
 			return err
 		}
+		if state.hydrating && hydrateCheck {
+			err = r.instructionList.writeHydrateCheck(positionID)
+			if err != nil {
+				return err
+			}
+		}
 	case vugu.TextNode:
 		return r.instructionList.writeSetText(n.Data) // no children possible, just return
 	case vugu.CommentNode:
@@ -463,6 +569,98 @@ func (r *JSRenderer) visitSyncNode(state *jsRenderState, bo *vugu.BuildOut, br *
 
 }
 
+// visitPortals syncs the children of each portal found during render into its target element.
+// All portals with the same target are concatenated, in the order they were encountered,
+// and the target's children are managed entirely by Vugu.  Targets which no longer have
+// any portals pointing to them are emptied.
+func (r *JSRenderer) visitPortals(state *jsRenderState, br *vugu.BuildResults, portalTargets map[string]bool) error {
+
+	done := make(map[string]bool, len(state.portalQueue))
+
+	// NOTE: portals can contain other portals, which are appended to portalQueue as we go
+	for i := 0; i < len(state.portalQueue); i++ {
+
+		target := state.portalQueue[i].n.PortalTarget
+		if done[target] {
+			continue
+		}
+		done[target] = true
+
+		err := r.instructionList.writeSelectQuery(target)
+		if err != nil {
+			return err
+		}
+
+		err = r.instructionList.writeMoveToFirstChild()
+		if err != nil {
+			return err
+		}
+
+		hasChildren := false
+		// only look at the portals queued so far, nested ones are found while we visit these
+		end := len(state.portalQueue)
+		for j := i; j < end; j++ {
+			p := state.portalQueue[j]
+			if p.n.PortalTarget != target {
+				continue
+			}
+			childIndex := 1
+			for nchild := p.n.FirstChild; nchild != nil; nchild = nchild.NextSibling {
+
+				childPositionID := append(p.positionID, []byte(fmt.Sprintf("_p_%d", childIndex))...)
+
+				err = r.visitSyncNode(state, p.bo, br, nchild, childPositionID)
+				if err != nil {
+					return err
+				}
+				err = r.instructionList.writeMoveToNextSibling()
+				if err != nil {
+					return err
+				}
+				hasChildren = true
+				childIndex++
+			}
+		}
+
+		// check for a nested portal pointing back to this same target, we can't sync it twice in one pass
+		for j := end; j < len(state.portalQueue); j++ {
+			if state.portalQueue[j].n.PortalTarget == target {
+				return fmt.Errorf("portal target %q is used by a portal nested inside another portal with the same target", target)
+			}
+		}
+
+		err = r.instructionList.writeMoveToParent()
+		if err != nil {
+			return err
+		}
+
+		// if nothing was written, make sure any content from prior renders is gone
+		if !hasChildren {
+			err = r.instructionList.writeRemoveChildrenQuery(target)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// empty any targets from the last render that are now unused
+	for target := range portalTargets {
+		if done[target] {
+			continue
+		}
+		err := r.instructionList.writeRemoveChildrenQuery(target)
+		if err != nil {
+			return err
+		}
+		delete(portalTargets, target)
+	}
+	for target := range done {
+		portalTargets[target] = true
+	}
+
+	return nil
+}
+
 // visitSyncElementEtc syncs the rest of the stuff that only applies to elements
 func (r *JSRenderer) visitSyncElementEtc(state *jsRenderState, bo *vugu.BuildOut, br *vugu.BuildResults, n *vugu.VGNode, positionID []byte) error {
 
@@ -665,6 +863,9 @@ func (r *JSRenderer) handleDOMEvent() {
 	// invoke handler
 	f(domEvent)
 
+	// if this event came from an island, it is the one that needs to be re-rendered
+	r.markIslandForPosition(eventDetail.PositionID)
+
 	r.eventRWMU.Unlock()
 
 	// TODO: Also give this more thought: For now we just do a non-blocking push to the
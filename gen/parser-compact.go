@@ -113,7 +113,7 @@ func isStaticEl(n *html.Node) bool {
 	if strings.Contains(n.Data, ":") {
 		return false
 	}
-	if n.Data == "vg-comp" {
+	if n.Data == "vg-comp" || n.Data == "vg-portal" {
 		return false
 	}
 
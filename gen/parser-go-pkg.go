@@ -123,7 +123,9 @@ func (p *ParserGoPkg) Opts() ParserGoPkgOpts {
 
 // Run does the work and generates the appropriate .go files from .vugu files.
 // It will also create a go.mod file if not present and not SkipGoMod.  Same for main.go and SkipMainGo (will also skip
-// if package already has file with package name something other than main).
+// if package already has file with package name something other than main).  If the package has a
+// func vuguSetupIslands(*domrender.JSRenderer) error then the generated main renders the islands it adds
+// (see domrender.JSRenderer.AddIsland) instead of a single root component.
 // Per-file code generation is performed by ParserGo.
 func (p *ParserGoPkg) Run() error {
 
@@ -200,6 +202,7 @@ func (p *ParserGoPkg) Run() error {
 		// namesToCheck = append(namesToCheck, pg.ComponentType+".NewData")
 		// namesToCheck = append(namesToCheck, pg.DataType)
 		namesToCheck = append(namesToCheck, "vuguSetup")
+		namesToCheck = append(namesToCheck, "vuguSetupIslands")
 
 		// read in source
 		b, err := ioutil.ReadFile(filepath.Join(p.pkgPath, fn))
@@ -238,19 +241,50 @@ func (p *ParserGoPkg) Run() error {
 			// log.Printf("WRITING TO main_wasm.go STUFF")
 			var buf bytes.Buffer
 			t, err := template.New("_main_").Parse(`// +build wasm
-{{$opts := .Parser.Opts}}
+{{$opts := .Parser.Opts}}{{$islands := index .NamesFound "vuguSetupIslands"}}
 package main
 
 import (
 	"fmt"
-{{if not $opts.TinyGo}}
+{{if not (or $opts.TinyGo $islands)}}
 	"flag"
 {{end}}
 
+{{if not $islands}}
 	"github.com/vugu/vugu"
+{{end}}
 	"github.com/vugu/vugu/domrender"
 )
 
+{{if $islands}}
+func main() {
+
+	fmt.Printf("Entering main() with islands\n")
+	{{if not $opts.TinyGo}}defer fmt.Printf("Exiting main()\n")
+{{end}}
+
+	// each island has its own mount point, root component and BuildEnv, see vuguSetupIslands
+	renderer, err := domrender.New("")
+	if err != nil {
+		panic(err)
+	}
+	{{if not $opts.TinyGo}}defer renderer.Release()
+{{end}}
+
+	err = vuguSetupIslands(renderer)
+	if err != nil {
+		panic(err)
+	}
+
+	for ok := true; ok; ok = renderer.EventWait() {
+		err = renderer.RenderIslands()
+		if err != nil {
+			panic(err)
+		}
+	}
+
+}
+{{else}}
 func main() {
 
 {{if $opts.TinyGo}}
@@ -298,6 +332,7 @@ func main() {
 	}
 	
 }
+{{end}}
 `)
 			if err != nil {
 				return err
@@ -142,6 +142,32 @@ func TestRun(t *testing.T) {
 			},
 			build: "default",
 		},
+		{
+			name:      "islands-wasm",
+			opts:      ParserGoPkgOpts{},
+			recursive: false,
+			infiles: map[string]string{
+				"root.vugu":   `<div>root here</div>`,
+				"widget.vugu": `<div>widget here</div>`,
+				"go.mod":      "module testcase\nreplace github.com/vugu/vugu => " + pwd + "\n",
+				"setup.go": `package main
+
+import "github.com/vugu/vugu/domrender"
+
+func vuguSetupIslands(renderer *domrender.JSRenderer) error {
+	if _, err := renderer.AddIsland("#root", &Root{}); err != nil {
+		return err
+	}
+	_, err := renderer.AddIsland("#widget", &Widget{})
+	return err
+}
+`,
+			},
+			out: map[string][]string{
+				"main_wasm.go": {`vuguSetupIslands\(renderer\)`, `renderer\.RenderIslands\(\)`},
+			},
+			build: "wasm",
+		},
 		{
 			name:      "events",
 			opts:      ParserGoPkgOpts{},
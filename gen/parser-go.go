@@ -815,6 +815,8 @@ func (p *ParserGo) visitDefaultByType(state *parseGoState, n *html.Node) error {
 			err = p.visitVGCompTag(state, n)
 		} else if n.Data == "vg-template" {
 			err = p.visitVGTemplateTag(state, n)
+		} else if n.Data == "vg-portal" {
+			err = p.visitVGPortalTag(state, n)
 		} else {
 			err = p.visitNodeElementAndCtrl(state, n)
 		}
@@ -922,6 +924,63 @@ func (p *ParserGo) visitVGTemplateTag(state *parseGoState, n *html.Node) error {
 	return nil
 }
 
+// visitVGPortalTag handles vg-portal
+func (p *ParserGo) visitVGPortalTag(state *parseGoState, n *html.Node) error {
+
+	// vg-for
+	if v, _ := vgForExpr(n); v.expr != "" {
+		if err := p.emitForExpr(state, n); err != nil {
+			return err
+		}
+		defer fmt.Fprintf(&state.buildBuf, "}\n")
+	}
+
+	// vg-if
+	ife := vgIfExpr(n)
+	if ife != "" {
+		fmt.Fprintf(&state.buildBuf, "if %s {\n", ife)
+		defer fmt.Fprintf(&state.buildBuf, "}\n")
+	}
+
+	// target is either a static selector or a Go expression via :target
+	targetExpr := ""
+	for _, a := range n.Attr {
+		switch a.OrigKey {
+		case "target":
+			targetExpr = fmt.Sprintf("%q", a.Val)
+		case ":target":
+			targetExpr = a.Val
+		}
+	}
+	if targetExpr == "" {
+		return fmt.Errorf("vg-portal must have a `target` or `:target` attribute with the selector of the element to render into")
+	}
+
+	// output a node with type Element but empty data, like a template but with PortalTarget set
+	fmt.Fprintf(&state.buildBuf, "vgn = &vugu.VGNode{Type:vugu.VGNodeType(%d),PortalTarget:%s} // <vg-portal>\n", vugu.ElementNode, targetExpr)
+	fmt.Fprintf(&state.buildBuf, "vgparent.AppendChild(vgn)\n")
+
+	if n.FirstChild != nil {
+
+		fmt.Fprintf(&state.buildBuf, "{\n")
+		fmt.Fprintf(&state.buildBuf, "vgparent := vgn; _ = vgparent\n") // vgparent set for this block to vgn
+
+		// iterate over children
+		for childN := n.FirstChild; childN != nil; childN = childN.NextSibling {
+
+			err := p.visitDefaultByType(state, childN)
+			if err != nil {
+				return err
+			}
+		}
+
+		fmt.Fprintf(&state.buildBuf, "}\n")
+
+	}
+
+	return nil
+}
+
 // visitNodeComponentElement handles an element that is a call to a component
 func (p *ParserGo) visitNodeComponentElement(state *parseGoState, n *html.Node) error {
 
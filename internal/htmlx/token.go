@@ -1094,6 +1094,22 @@ func (z *Tokenizer) Raw() []byte {
 	return z.buf[z.raw.start:z.raw.end]
 }
 
+// RawAttrSpans returns the location of each attribute of the current tag token as offsets into Raw:
+// the start and end of the key followed by the start and end of the value, excluding any quotes.
+// Unlike TagAttr it does not consume the attributes, and it can be called after Token.
+func (z *Tokenizer) RawAttrSpans() [][4]int {
+	switch z.tt {
+	case StartTagToken, SelfClosingTagToken:
+	default:
+		return nil
+	}
+	ret := make([][4]int, len(z.attr))
+	for i, x := range z.attr {
+		ret[i] = [4]int{x[0].start - z.raw.start, x[0].end - z.raw.start, x[1].start - z.raw.start, x[1].end - z.raw.start}
+	}
+	return ret
+}
+
 // convertNewlines converts "\r" and "\r\n" in s to "\n".
 // The conversion happens in place, but the resulting slice may be shorter.
 func convertNewlines(s []byte) []byte {
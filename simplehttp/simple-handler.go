@@ -16,6 +16,7 @@ need from its parts.  That said, simplehttp should make it easy to start:
 After creation, some flags are available for tuning, e.g.:
 
 	h.EnableGenerate = true // upon page reload run "go generate ."
+	h.EnableLiveReload = false // do not rebuild and reload the page when files change
 	h.DisableBuildCache = true // do not try to cache build results during development, just rebuild every time
 	h.ParserGoPkgOpts.SkipRegisterComponentTypes = true // do not generate component registration init() stuff
 
@@ -29,6 +30,7 @@ package simplehttp
 import (
 	"bytes"
 	"compress/gzip"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
@@ -44,6 +46,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/vugu/vugu/devutil"
 	"github.com/vugu/vugu/gen"
 )
 
@@ -58,6 +61,9 @@ type SimpleHandler struct {
 	DisableTimestampPreservation bool                 // if true don't try to keep timestamps the same for files that are byte for byte identical (requires EnableBuildAndServe)
 	MainWasmPath                 string               // path to serve main wasm file from, in dev mod defaults to "/main.wasm" (requires EnableBuildAndServe)
 	WasmExecJsPath               string               // path to serve wasm_exec.js from after finding in the local Go installation, in dev mode defaults to "/wasm_exec.js"
+	EnableLiveReload             bool                 // rebuild when files in Dir change and tell open pages to reload once it succeeds (requires EnableBuildAndServe)
+	LiveReloadPath               string               // path of the live reload event stream, in dev mode defaults to devutil.DefaultLiveReloadPath (requires EnableLiveReload)
+	BuildErrorPath               string               // path to serve the last build error from as JSON for the page's error overlay, in dev mode defaults to devutil.DefaultBuildErrorPath (requires EnableBuildAndServe)
 
 	IsPage      func(r *http.Request) bool // func that returns true if PageHandler should serve the request
 	PageHandler http.Handler               // returns the HTML page
@@ -68,10 +74,15 @@ type SimpleHandler struct {
 	wasmExecJsContent []byte
 	wasmExecJsTs      time.Time
 
-	lastBuildTime      time.Time // time of last successful build
-	lastBuildContentGZ []byte    // last successful build gzipped
+	lastBuildTime      time.Time           // time of last successful build
+	lastBuildContentGZ []byte              // last successful build gzipped
+	lastBuildErr       *devutil.BuildError // error from the last build, nil if it succeeded
 
-	mu sync.RWMutex
+	liveReloadOnce sync.Once
+	liveReload     *devutil.LiveReloadHandler
+
+	buildMu sync.Mutex // held while building, builds share Dir and the files generated in it
+	mu      sync.RWMutex
 }
 
 // New returns an SimpleHandler ready to serve using the specified directory.
@@ -90,8 +101,18 @@ func New(dir string, dev bool) *SimpleHandler {
 
 	ret.IsPage = DefaultIsPageFunc
 	ret.PageHandler = &PageHandler{
-		Template:         template.Must(template.New("_page_").Parse(DefaultPageTemplateSource)),
-		TemplateDataFunc: DefaultTemplateDataFunc,
+		Template: template.Must(template.New("_page_").Parse(DefaultPageTemplateSource)),
+		TemplateDataFunc: func(r *http.Request) interface{} {
+			ret.startLiveReload() // the page is the first thing requested, start watching before it loads anything else
+			tmplData := DefaultTemplateDataFunc(r)
+			if m, ok := tmplData.(map[string]interface{}); ok && ret.liveReloadEnabled() {
+				m["LiveReloadScript"] = template.HTML(devutil.LiveReloadScript(ret.LiveReloadPath))
+			}
+			if m, ok := tmplData.(map[string]interface{}); ok && ret.EnableBuildAndServe && ret.BuildErrorPath != "" {
+				m["BuildErrorScript"] = template.HTML(devutil.BuildErrorScript(ret.BuildErrorPath))
+			}
+			return tmplData
+		},
 	}
 
 	ret.StaticHandler = FilteredFileServer(
@@ -103,6 +124,9 @@ func New(dir string, dev bool) *SimpleHandler {
 		ret.ParserGoPkgOpts = &gen.ParserGoPkgOpts{}
 		ret.MainWasmPath = "/main.wasm"
 		ret.WasmExecJsPath = "/wasm_exec.js"
+		ret.EnableLiveReload = true
+		ret.LiveReloadPath = devutil.DefaultLiveReloadPath
+		ret.BuildErrorPath = devutil.DefaultBuildErrorPath
 	}
 
 	return ret
@@ -121,6 +145,20 @@ func (h *SimpleHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	p := path.Clean("/" + r.URL.Path)
 
+	if h.liveReloadEnabled() && h.LiveReloadPath == p {
+		h.startLiveReload()
+		h.liveReload.ServeHTTP(w, r)
+		return
+	}
+
+	if h.EnableBuildAndServe && h.BuildErrorPath == p {
+		h.mu.RLock()
+		be := h.lastBuildErr
+		h.mu.RUnlock()
+		devutil.ServeBuildError(w, be)
+		return
+	}
+
 	if h.EnableBuildAndServe && h.MainWasmPath == p {
 		h.buildAndServe(w, r)
 		return
@@ -182,126 +220,182 @@ func (h *SimpleHandler) buildAndServe(w http.ResponseWriter, r *http.Request) {
 	// 	return
 	// }
 
-	// FIXME: might be useful to make it so only one thread rebuilds at a time and they both use the result
-
 doBuild:
 
 	// log.Printf("GOT HERE")
 
-	{
+	err = h.build(buildDirTs, w.Header())
+	if err != nil {
+		log.Print(err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
 
-		if h.ParserGoPkgOpts != nil {
-			pg := gen.NewParserGoPkg(h.Dir, h.ParserGoPkgOpts)
-			err := pg.Run()
-			if err != nil {
-				msg := fmt.Sprintf("Error from ParserGoPkg: %v", err)
-				log.Print(msg)
-				http.Error(w, msg, 500)
-				return
-			}
-		}
+	h.mu.RLock()
+	lastBuildTime = h.lastBuildTime
+	lastBuildContentGZ = h.lastBuildContentGZ
+	h.mu.RUnlock()
+
+serveBuiltFile:
+
+	w.Header().Set("Content-Type", "application/wasm")
+	// w.Header().Set("Last-Modified", lastBuildTime.Format(http.TimeFormat)) // handled by http.ServeContent
+
+	// if client supports gzip response (the usual case), we just set the gzip header and send back
+	if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("X-Gzipped-Size", fmt.Sprint(len(lastBuildContentGZ)))
+		http.ServeContent(w, r, h.MainWasmPath, lastBuildTime, bytes.NewReader(lastBuildContentGZ))
+		return
+	}
+
+	// no gzip, we decompress internally and send it back
+	gzr, _ := gzip.NewReader(bytes.NewReader(lastBuildContentGZ))
+	_, err = io.Copy(w, gzr)
+	if err != nil {
+		log.Print(err)
+	}
+	return
+
+}
+
+// build runs the generate and build steps and updates the cached build and last build error.
+// buildDirTs is the timestamp of the project directory before building (may be zero),
+// timing information is set on hdr.
+func (h *SimpleHandler) build(buildDirTs time.Time, hdr http.Header) error {
+
+	// only one build at a time, page requests and the live reload watcher can both start one
+	h.buildMu.Lock()
+	defer h.buildMu.Unlock()
+
+	// the build we waited for may already have built what we want
+	h.mu.RLock()
+	done := !h.DisableBuildCache && !buildDirTs.IsZero() && h.lastBuildErr == nil &&
+		len(h.lastBuildContentGZ) > 0 && !buildDirTs.After(h.lastBuildTime)
+	h.mu.RUnlock()
+	if done {
+		return nil
+	}
+
+	err := h.runBuild(buildDirTs, hdr)
+	var be *devutil.BuildError
+	if err != nil && !errors.As(err, &be) {
+		be = devutil.NewBuildError(devutil.BuildPhaseBuild, h.Dir, []byte(err.Error()), err)
+	}
+	h.mu.Lock()
+	h.lastBuildErr = be
+	h.mu.Unlock()
+	return err
+}
 
-		f, err := ioutil.TempFile("", "main_wasm_")
+func (h *SimpleHandler) runBuild(buildDirTs time.Time, hdr http.Header) error {
+
+	if h.ParserGoPkgOpts != nil {
+		pg := gen.NewParserGoPkg(h.Dir, h.ParserGoPkgOpts)
+		err := pg.Run()
 		if err != nil {
-			panic(err)
-		}
-		fpath := f.Name()
-		f.Close()
-		os.Remove(f.Name())
-		defer os.Remove(f.Name())
-
-		var cmd *exec.Cmd
-
-		startTime := time.Now()
-		if h.EnableGenerate {
-			cmd := exec.Command("go", "generate", ".")
-			cmd.Dir = h.Dir
-			cmd.Env = append(cmd.Env, os.Environ()...)
-			b, err := cmd.CombinedOutput()
-			w.Header().Set("X-Go-Generate-Duration", time.Since(startTime).String())
-			if err != nil {
-				msg := fmt.Sprintf("Error from generate: %v; Output:\n%s", err, b)
-				log.Print(msg)
-				http.Error(w, msg, 500)
-				return
-			}
+			msg := fmt.Sprintf("Error from ParserGoPkg: %v", err)
+			return devutil.NewBuildError(devutil.BuildPhaseGenerate, h.Dir, []byte(msg), errors.New(msg))
 		}
+	}
 
-		// GOOS=js GOARCH=wasm go build -o main.wasm .
-		startTime = time.Now()
-		cmd = exec.Command("go", "build", "-o", fpath, ".")
+	f, err := ioutil.TempFile("", "main_wasm_")
+	if err != nil {
+		panic(err)
+	}
+	fpath := f.Name()
+	f.Close()
+	os.Remove(f.Name())
+	defer os.Remove(f.Name())
+
+	var cmd *exec.Cmd
+
+	startTime := time.Now()
+	if h.EnableGenerate {
+		cmd := exec.Command("go", "generate", ".")
 		cmd.Dir = h.Dir
 		cmd.Env = append(cmd.Env, os.Environ()...)
-		cmd.Env = append(cmd.Env, "GOOS=js", "GOARCH=wasm")
 		b, err := cmd.CombinedOutput()
-		w.Header().Set("X-Go-Build-Duration", time.Since(startTime).String())
+		hdr.Set("X-Go-Generate-Duration", time.Since(startTime).String())
 		if err != nil {
-			msg := fmt.Sprintf("Error from compile: %v (out path=%q); Output:\n%s", err, fpath, b)
-			log.Print(msg)
-			http.Error(w, msg, 500)
-			return
-		}
-
-		f, err = os.Open(fpath)
-		if err != nil {
-			msg := fmt.Sprintf("Error opening file after build: %v", err)
-			log.Print(msg)
-			http.Error(w, msg, 500)
-			return
+			return devutil.NewBuildError(devutil.BuildPhaseGenerate, h.Dir, b, fmt.Errorf("Error from generate: %v; Output:\n%s", err, b))
 		}
+	}
 
-		// gzip with max compression
-		var buf bytes.Buffer
-		gzw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
-		n, err := io.Copy(gzw, f)
-		if err != nil {
-			msg := fmt.Sprintf("Error reading and compressing binary: %v", err)
-			log.Print(msg)
-			http.Error(w, msg, 500)
-			return
-		}
-		gzw.Close()
+	// GOOS=js GOARCH=wasm go build -o main.wasm .
+	startTime = time.Now()
+	cmd = exec.Command("go", "build", "-o", fpath, ".")
+	cmd.Dir = h.Dir
+	cmd.Env = append(cmd.Env, os.Environ()...)
+	cmd.Env = append(cmd.Env, "GOOS=js", "GOARCH=wasm")
+	b, err := cmd.CombinedOutput()
+	hdr.Set("X-Go-Build-Duration", time.Since(startTime).String())
+	if err != nil {
+		return devutil.NewBuildError(devutil.BuildPhaseBuild, h.Dir, b, fmt.Errorf("Error from compile: %v (out path=%q); Output:\n%s", err, fpath, b))
+	}
 
-		w.Header().Set("X-Gunzipped-Size", fmt.Sprint(n))
+	f, err = os.Open(fpath)
+	if err != nil {
+		return fmt.Errorf("Error opening file after build: %v", err)
+	}
+	defer f.Close()
 
-		// update cache
+	// gzip with max compression
+	var buf bytes.Buffer
+	gzw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	n, err := io.Copy(gzw, f)
+	if err != nil {
+		return fmt.Errorf("Error reading and compressing binary: %v", err)
+	}
+	gzw.Close()
 
-		if buildDirTs.IsZero() {
-			lastBuildTime = time.Now()
-		} else {
-			lastBuildTime = buildDirTs
-		}
-		lastBuildContentGZ = buf.Bytes()
+	hdr.Set("X-Gunzipped-Size", fmt.Sprint(n))
 
-		// log.Printf("GOT TO UPDATE")
-		h.mu.Lock()
-		h.lastBuildTime = lastBuildTime
-		h.lastBuildContentGZ = lastBuildContentGZ
-		h.mu.Unlock()
+	// update cache
 
+	lastBuildTime := buildDirTs
+	if lastBuildTime.IsZero() {
+		lastBuildTime = time.Now()
 	}
 
-serveBuiltFile:
+	// log.Printf("GOT TO UPDATE")
+	h.mu.Lock()
+	h.lastBuildTime = lastBuildTime
+	h.lastBuildContentGZ = buf.Bytes()
+	h.mu.Unlock()
 
-	w.Header().Set("Content-Type", "application/wasm")
-	// w.Header().Set("Last-Modified", lastBuildTime.Format(http.TimeFormat)) // handled by http.ServeContent
+	return nil
+}
 
-	// if client supports gzip response (the usual case), we just set the gzip header and send back
-	if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-		w.Header().Set("Content-Encoding", "gzip")
-		w.Header().Set("X-Gzipped-Size", fmt.Sprint(len(lastBuildContentGZ)))
-		http.ServeContent(w, r, h.MainWasmPath, lastBuildTime, bytes.NewReader(lastBuildContentGZ))
+func (h *SimpleHandler) liveReloadEnabled() bool {
+	return h.EnableBuildAndServe && h.EnableLiveReload && h.LiveReloadPath != ""
+}
+
+// startLiveReload starts watching Dir the first time it is called with live reload enabled.
+// Changes cause a rebuild, so the page finds the new build in the cache when it reloads.
+func (h *SimpleHandler) startLiveReload() {
+	if !h.liveReloadEnabled() {
 		return
 	}
+	h.liveReloadOnce.Do(func() {
+		h.liveReload = devutil.NewLiveReloadHandler().SetRebuildFunc(func() error {
+			var buildDirTs time.Time
+			if !h.DisableTimestampPreservation {
+				buildDirTs, _ = dirTimestamp(h.Dir)
+			}
+			return h.build(buildDirTs, make(http.Header))
+		})
+		h.liveReload.Watch(h.Dir)
+	})
+}
 
-	// no gzip, we decompress internally and send it back
-	gzr, _ := gzip.NewReader(bytes.NewReader(lastBuildContentGZ))
-	_, err = io.Copy(w, gzr)
-	if err != nil {
-		log.Print(err)
+// Close stops watching Dir for live reload.  It should be called when the handler is no longer used.
+func (h *SimpleHandler) Close() error {
+	h.liveReloadOnce.Do(func() {}) // so it is not started after this
+	if h.liveReload != nil {
+		return h.liveReload.Close()
 	}
-	return
-
+	return nil
 }
 
 func (h *SimpleHandler) serveGoEnvWasmExecJs(w http.ResponseWriter, r *http.Request) {
@@ -395,6 +489,8 @@ var DefaultPageTemplateSource = `<!doctype html>
 {{end}}{{end}}
 <script src="https://cdn.jsdelivr.net/npm/text-encoding@0.7.0/lib/encoding.min.js"></script> <!-- MS Edge polyfill -->
 <script src="/wasm_exec.js"></script>
+{{if .LiveReloadScript}}{{.LiveReloadScript}}{{end}}
+{{if .BuildErrorScript}}{{.BuildErrorScript}}{{end}}
 </head>
 <body>
 <div id="vugu_mount_point">
@@ -411,9 +507,15 @@ if (wasmSupported) {
 			return await WebAssembly.instantiate(source, importObject);
 		};
 	}
-	const go = new Go();
-	WebAssembly.instantiateStreaming(fetch("/main.wasm"), go.importObject).then((result) => {
-		go.run(result.instance);
+	fetch("/main.wasm").then(function(res) {
+		if (res.ok) {
+			const go = new Go();
+			WebAssembly.instantiateStreaming(res, go.importObject).then((result) => {
+				go.run(result.instance);
+			});
+		} else if (window.vuguBuildError) {
+			res.text().then(function(txt) { window.vuguBuildError(txt); });
+		}
 	});
 } else {
 	document.getElementById("vugu_mount_point").innerHTML = 'This application requires WebAssembly support.  Please upgrade your browser.';
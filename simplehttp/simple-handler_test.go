@@ -38,6 +38,7 @@ replace github.com/vugu/vugu => `+vugudir+`
 `), 0644))
 
 	h := New(tmpDir, true)
+	defer h.Close() // stop watching tmpDir before it is removed
 	srv := httptest.NewServer(h)
 	defer srv.Close()
 
@@ -77,6 +78,7 @@ replace github.com/vugu/vugu => `+vugudir+`
 `), 0644))
 
 	h := New(tmpDir, false)
+	defer h.Close()
 	srv := httptest.NewServer(h)
 	defer srv.Close()
 
@@ -101,3 +103,32 @@ func mustGetPage(u string) string {
 	}
 	return string(b)
 }
+
+func TestSimpleHandlerDevScripts(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "TestSimpleHandlerLiveReload")
+	assert.NoError(err)
+	defer os.RemoveAll(tmpDir)
+
+	// production mode has no live reload script or endpoint
+	srv := httptest.NewServer(New(tmpDir, false))
+	assert.NotContains(mustGetPage(srv.URL+"/"), "EventSource")
+	assert.NotContains(mustGetPage(srv.URL+"/"), "window.vuguBuildError = ")
+	assert.Contains(mustGetPage(srv.URL+"/_vugu/live-reload"), "<body") // falls through to the page
+	srv.Close()
+
+	h := New(tmpDir, true)
+	defer h.Close() // stop watching tmpDir before it is removed
+	srv = httptest.NewServer(h)
+	defer srv.Close()
+	assert.Contains(mustGetPage(srv.URL+"/"), `new EventSource("/_vugu/live-reload")`)
+	assert.Contains(mustGetPage(srv.URL+"/"), "window.vuguBuildError = ")
+	assert.Equal("null", mustGetPage(srv.URL+"/_vugu/build-error")) // no build has failed
+
+	res, err := http.Get(srv.URL + "/_vugu/live-reload")
+	assert.NoError(err)
+	res.Body.Close()
+	assert.Equal("text/event-stream", res.Header.Get("Content-Type"))
+}
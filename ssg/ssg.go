@@ -0,0 +1,251 @@
+/*
+Package ssg performs static site generation: each route is built and rendered with staticrender
+to route/index.html in an output directory, static assets are copied alongside and a sitemap is written.
+
+	g := ssg.New("dist")
+	g.Routes = []string{"/", "/about", "/docs/intro"}
+	g.RootFunc = func(route string) (vugu.Builder, error) {
+		return &Root{Route: route}, nil
+	}
+	g.AssetDir = "."
+	g.BaseURL = "https://example.com"
+	err := g.Generate()
+
+The vugussg command generates and runs a program like this for a Vugu package.
+*/
+package ssg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vugu/vugu"
+	"github.com/vugu/vugu/distutil"
+	"github.com/vugu/vugu/staticrender"
+)
+
+// Generator renders a list of routes to static files.
+type Generator struct {
+	OutDir string // output directory, created if needed
+
+	Routes     []string                 // routes to render, e.g. "/", "/about"
+	RoutesFunc func() ([]string, error) // optional, called to generate more routes in addition to Routes
+
+	// RootFunc returns the root component for a route, required.
+	RootFunc func(route string) (vugu.Builder, error)
+
+	// SetupBuildEnv, if set, is called on the BuildEnv of each route before it is used (e.g. to call SetWireFunc).
+	SetupBuildEnv func(buildEnv *vugu.BuildEnv)
+
+	// PositionMarkers enables position markers in the output, see staticrender.StaticRenderer.SetPositionMarkers.
+	PositionMarkers bool
+
+	// RootPositionID is the position ID of the root element when PositionMarkers is set,
+	// see staticrender.StaticRenderer.SetRootPositionID.
+	RootPositionID string
+
+	AssetDir     string         // if set, static files are copied from here to OutDir with distutil.CopyDirFiltered
+	AssetPattern *regexp.Regexp // pattern for CopyDirFiltered, nil means distutil.DefaultFileInclPattern
+
+	// BaseURL is prefixed to each route in the sitemap, e.g. "https://example.com".
+	// Sitemaps require absolute URLs so this should be set for public sites.
+	BaseURL string
+
+	// SitemapName is the name of the sitemap file in OutDir, defaults to "sitemap.xml".  Set to "-" to disable.
+	SitemapName string
+}
+
+// New returns a Generator writing to outDir.
+func New(outDir string) *Generator {
+	return &Generator{OutDir: outDir}
+}
+
+// RouteError is returned by Generate when one or more routes failed to render.
+type RouteError struct {
+	Errors map[string]error // by route
+}
+
+// Error implements error.
+func (e *RouteError) Error() string {
+	var sb strings.Builder
+	routes := make([]string, 0, len(e.Errors))
+	for route := range e.Errors {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	fmt.Fprintf(&sb, "%d route(s) failed:", len(e.Errors))
+	for _, route := range routes {
+		fmt.Fprintf(&sb, "\n\t%s: %v", route, e.Errors[route])
+	}
+	return sb.String()
+}
+
+// Generate copies assets, renders every route and writes the sitemap.
+// A failure in one route (including a panic during Build) does not stop the others from
+// being rendered, all such failures are returned together as a *RouteError.
+func (g *Generator) Generate() error {
+
+	if g.RootFunc == nil {
+		return fmt.Errorf("ssg: RootFunc is required")
+	}
+
+	routes := append([]string(nil), g.Routes...)
+	if g.RoutesFunc != nil {
+		more, err := g.RoutesFunc()
+		if err != nil {
+			return fmt.Errorf("ssg: generating routes: %w", err)
+		}
+		routes = append(routes, more...)
+	}
+
+	err := os.MkdirAll(g.OutDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	// assets first so they can't overwrite rendered pages
+	if g.AssetDir != "" {
+		err = distutil.CopyDirFiltered(g.AssetDir, g.OutDir, g.AssetPattern)
+		if err != nil {
+			return fmt.Errorf("ssg: copying assets: %w", err)
+		}
+	}
+
+	var rendered []string
+	seen := make(map[string]bool, len(routes))
+	routeErr := &RouteError{Errors: make(map[string]error)}
+	for _, route := range routes {
+
+		route = cleanRoute(route)
+		if seen[route] {
+			continue
+		}
+		seen[route] = true
+
+		// a new BuildEnv for each route, so no component instances (and their state) are shared between routes
+		buildEnv, err := vugu.NewBuildEnv()
+		if err != nil {
+			return err
+		}
+		if g.SetupBuildEnv != nil {
+			g.SetupBuildEnv(buildEnv)
+		}
+
+		err = g.renderRoute(buildEnv, route)
+		if err != nil {
+			routeErr.Errors[route] = err
+			continue
+		}
+		rendered = append(rendered, route)
+	}
+
+	if g.SitemapName != "-" {
+		err = g.writeSitemap(rendered)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(routeErr.Errors) > 0 {
+		return routeErr
+	}
+	return nil
+}
+
+// renderRoute builds and renders one route, converting a panic into an error
+func (g *Generator) renderRoute(buildEnv *vugu.BuildEnv, route string) (reterr error) {
+
+	defer func() {
+		if p := recover(); p != nil {
+			reterr = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	root, err := g.RootFunc(route)
+	if err != nil {
+		return err
+	}
+
+	buildResults := buildEnv.RunBuild(root)
+
+	outPath := filepath.Join(g.OutDir, filepath.FromSlash(RouteFile(route)))
+	err = os.MkdirAll(filepath.Dir(outPath), 0755)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	renderer := staticrender.New(f)
+	renderer.SetPositionMarkers(g.PositionMarkers)
+	renderer.SetRootPositionID(g.RootPositionID)
+	err = renderer.Render(buildResults)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// RouteFile returns the output file for a route, relative to the output directory and slash separated,
+// e.g. "/" is "index.html" and "/docs/intro" is "docs/intro/index.html".
+func RouteFile(route string) string {
+	route = cleanRoute(route)
+	if route == "/" {
+		return "index.html"
+	}
+	return strings.TrimPrefix(route, "/") + "/index.html"
+}
+
+// cleanRoute makes route absolute and removes any trailing slash, dot segments cannot escape the root
+func cleanRoute(route string) string {
+	return path.Clean("/" + route)
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc string `xml:"loc"`
+}
+
+func (g *Generator) writeSitemap(routes []string) error {
+
+	name := g.SitemapName
+	if name == "" {
+		name = "sitemap.xml"
+	}
+
+	us := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	base := strings.TrimSuffix(g.BaseURL, "/")
+	for _, route := range routes {
+		loc := route
+		if route != "/" {
+			loc += "/"
+		}
+		us.URLs = append(us.URLs, sitemapURL{Loc: base + loc})
+	}
+
+	b, err := xml.MarshalIndent(us, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append([]byte(xml.Header), b...)
+	b = append(b, '\n')
+
+	return ioutil.WriteFile(filepath.Join(g.OutDir, name), b, 0644)
+}
@@ -0,0 +1,126 @@
+package ssg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vugu/vugu"
+)
+
+type ssgTestPage struct{ route string }
+
+func (c *ssgTestPage) Build(in *vugu.BuildIn) *vugu.BuildOut {
+	if c.route == "/broken" {
+		panic("broken page")
+	}
+	text := &vugu.VGNode{Type: vugu.TextNode, Data: "route " + c.route}
+	return &vugu.BuildOut{Out: []*vugu.VGNode{{Type: vugu.ElementNode, Data: "div", FirstChild: text}}}
+}
+
+func TestGenerate(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "TestGenerate")
+	assert.NoError(err)
+	defer os.RemoveAll(tmpDir)
+
+	assetDir := filepath.Join(tmpDir, "assets")
+	assert.NoError(os.MkdirAll(assetDir, 0755))
+	assert.NoError(ioutil.WriteFile(filepath.Join(assetDir, "style.css"), []byte("body{}"), 0644))
+	assert.NoError(ioutil.WriteFile(filepath.Join(assetDir, "notes.txt"), []byte("not copied"), 0644))
+
+	outDir := filepath.Join(tmpDir, "dist")
+	g := New(outDir)
+	g.Routes = []string{"/", "/about/", "/broken"}
+	g.RoutesFunc = func() ([]string, error) { return []string{"docs/intro", "/about"}, nil }
+	g.RootFunc = func(route string) (vugu.Builder, error) { return &ssgTestPage{route: route}, nil }
+	g.AssetDir = assetDir
+	g.BaseURL = "https://example.com/"
+
+	err = g.Generate()
+	assert.Error(err)
+	if assert.IsType(&RouteError{}, err) {
+		assert.Len(err.(*RouteError).Errors, 1)
+		assert.Contains(err.(*RouteError).Errors["/broken"].Error(), "broken page")
+	}
+
+	readOut := func(name string) string {
+		b, err := ioutil.ReadFile(filepath.Join(outDir, name))
+		assert.NoError(err)
+		return string(b)
+	}
+	assert.Equal("<div>route /</div>", readOut("index.html"))
+	assert.Equal("<div>route /about</div>", readOut("about/index.html"))
+	assert.Equal("<div>route /docs/intro</div>", readOut("docs/intro/index.html"))
+	assert.Equal("body{}", readOut("style.css"))
+	_, err = os.Stat(filepath.Join(outDir, "notes.txt"))
+	assert.True(os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(outDir, "broken/index.html"))
+	assert.True(os.IsNotExist(err))
+
+	sitemap := readOut("sitemap.xml")
+	assert.Contains(sitemap, "<loc>https://example.com/</loc>")
+	assert.Contains(sitemap, "<loc>https://example.com/about/</loc>")
+	assert.Contains(sitemap, "<loc>https://example.com/docs/intro/</loc>")
+	assert.NotContains(sitemap, "broken")
+}
+
+// ssgTestCounter is a child component which counts how often its instance was built
+type ssgTestCounter struct{ builds int }
+
+func (c *ssgTestCounter) Build(in *vugu.BuildIn) *vugu.BuildOut {
+	c.builds++
+	text := &vugu.VGNode{Type: vugu.TextNode, Data: fmt.Sprintf("builds %d", c.builds)}
+	return &vugu.BuildOut{Out: []*vugu.VGNode{{Type: vugu.ElementNode, Data: "span", FirstChild: text}}}
+}
+
+// ssgTestParent builds a ssgTestCounter the way generated code does
+type ssgTestParent struct{}
+
+func (c *ssgTestParent) Build(vgin *vugu.BuildIn) *vugu.BuildOut {
+	vgcompKey := vugu.MakeCompKey(0x1234^vgin.CurrentPositionHash(), nil)
+	vgcomp, _ := vgin.BuildEnv.CachedComponent(vgcompKey).(*ssgTestCounter)
+	if vgcomp == nil {
+		vgcomp = new(ssgTestCounter)
+	}
+	vgin.BuildEnv.UseComponent(vgcompKey, vgcomp)
+	child := &vugu.VGNode{Component: vgcomp}
+	return &vugu.BuildOut{
+		Out:        []*vugu.VGNode{{Type: vugu.ElementNode, Data: "div", FirstChild: child}},
+		Components: []vugu.Builder{vgcomp},
+	}
+}
+
+func TestGenerateNoSharedComponents(t *testing.T) {
+
+	assert := assert.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "TestGenerateNoSharedComponents")
+	assert.NoError(err)
+	defer os.RemoveAll(tmpDir)
+
+	g := New(tmpDir)
+	g.Routes = []string{"/a", "/b"}
+	g.RootFunc = func(route string) (vugu.Builder, error) { return &ssgTestParent{}, nil }
+	g.SitemapName = "-"
+	assert.NoError(g.Generate())
+
+	for _, name := range []string{"a/index.html", "b/index.html"} {
+		b, err := ioutil.ReadFile(filepath.Join(tmpDir, name))
+		assert.NoError(err)
+		assert.Equal("<div><span>builds 1</span></div>", string(b), name)
+	}
+}
+
+func TestRouteFile(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("index.html", RouteFile("/"))
+	assert.Equal("index.html", RouteFile(""))
+	assert.Equal("a/b/index.html", RouteFile("/a/b/"))
+	assert.Equal("x/index.html", RouteFile("/../x"))
+}
@@ -0,0 +1,173 @@
+package staticrender
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/vugu/vugu"
+)
+
+// HTTPStatusCoder can be implemented by a root component to set the HTTP status code of the response.
+// It is called after the build, so the status can depend on what the component found, e.g. a 404 page.
+type HTTPStatusCoder interface {
+	HTTPStatusCode() int
+}
+
+// HTTPHeaderSetter can be implemented by a root component to set response headers.
+// It is called after the build and before anything is written.
+type HTTPHeaderSetter interface {
+	SetHTTPHeader(h http.Header)
+}
+
+// Handler is an http.Handler which renders a root component for each request.
+// Each request gets a new BuildEnv, so no component instances are shared between requests.
+// The page is written with a StreamRenderer and flushed to the client as it is rendered.
+type Handler struct {
+	// RootFunc returns the root component for the request, required.
+	RootFunc func(r *http.Request) (vugu.Builder, error)
+
+	// Timeout, if non-zero, is applied to the request context.  If the context is done
+	// before the build finishes an error is returned instead of the page.
+	Timeout time.Duration
+
+	// SetupBuildEnv, if set, is called on the BuildEnv of each request before it is used (e.g. to call SetWireFunc).
+	SetupBuildEnv func(buildEnv *vugu.BuildEnv)
+
+	// PositionMarkers enables position markers in the output, see StreamRenderer.SetPositionMarkers.
+	PositionMarkers bool
+
+	// RootPositionID is the position ID of the root element when PositionMarkers is set,
+	// see StaticRenderer.SetRootPositionID.  Set it to hydrate the page as an island.
+	RootPositionID string
+
+	// ErrorHandler, if set, is called to write the response when the page cannot be rendered.
+	// The default logs the error and writes the status text with status 500 (or 504 if the context deadline
+	// was exceeded), the error itself is not sent to the client.
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// NewHandler returns a Handler which renders the root component returned by rootFunc.
+func NewHandler(rootFunc func(r *http.Request) (vugu.Builder, error)) *Handler {
+	return &Handler{RootFunc: rootFunc}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+
+	root, err := h.RootFunc(r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	buildEnv, err := h.newBuildEnv()
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	// build in a separate goroutine so we can give up on it when the context is done
+	type buildResult struct {
+		br  *vugu.BuildResults
+		err error
+	}
+	resultCh := make(chan buildResult, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				resultCh <- buildResult{err: fmt.Errorf("panic during build: %v", p)}
+			}
+		}()
+		resultCh <- buildResult{br: buildEnv.RunBuild(root)}
+	}()
+
+	var res buildResult
+	select {
+	case <-ctx.Done():
+		h.handleError(w, r, ctx.Err())
+		return
+	case res = <-resultCh:
+	}
+	if res.err != nil {
+		h.handleError(w, r, res.err)
+		return
+	}
+
+	status := http.StatusOK
+	if sc, ok := root.(HTTPStatusCoder); ok {
+		if c := sc.HTTPStatusCode(); c != 0 {
+			status = c
+		}
+	}
+	if hs, ok := root.(HTTPHeaderSetter); ok {
+		hs.SetHTTPHeader(w.Header())
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+	w.WriteHeader(status)
+
+	// output is written to the response as it is rendered, flushing each buffered chunk
+	var out io.Writer = w
+	if f, ok := w.(http.Flusher); ok {
+		out = flushWriter{w: w, f: f}
+	}
+	renderer := NewStream(out)
+	renderer.SetPositionMarkers(h.PositionMarkers)
+	renderer.SetRootPositionID(h.RootPositionID)
+	err = renderer.Render(res.br)
+	if err != nil {
+		// too late to change the status, all we can do is log it
+		log.Printf("staticrender.Handler: error rendering %q: %v", r.URL.Path, err)
+	}
+}
+
+// newBuildEnv returns a BuildEnv for one request.  BuildEnvs are not reused, the components
+// they cache from one build to the next would carry the state of one request into another.
+func (h *Handler) newBuildEnv() (*vugu.BuildEnv, error) {
+	be, err := vugu.NewBuildEnv()
+	if err != nil {
+		return nil, err
+	}
+	if h.SetupBuildEnv != nil {
+		h.SetupBuildEnv(be)
+	}
+	return be, nil
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	if h.ErrorHandler != nil {
+		h.ErrorHandler(w, r, err)
+		return
+	}
+	code := http.StatusInternalServerError
+	if err == context.DeadlineExceeded {
+		code = http.StatusGatewayTimeout
+	}
+	log.Printf("staticrender.Handler: error for %q: %v", r.URL.Path, err)
+	http.Error(w, http.StatusText(code), code)
+}
+
+// flushWriter flushes the response after each write
+type flushWriter struct {
+	w io.Writer
+	f http.Flusher
+}
+
+func (fw flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	fw.f.Flush()
+	return n, err
+}
@@ -0,0 +1,104 @@
+package staticrender
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vugu/vugu"
+)
+
+type handlerTestPage struct {
+	path  string
+	delay time.Duration
+}
+
+func (c *handlerTestPage) Build(in *vugu.BuildIn) *vugu.BuildOut {
+	time.Sleep(c.delay)
+	text := &vugu.VGNode{Type: vugu.TextNode, Data: "path " + c.path}
+	return &vugu.BuildOut{Out: []*vugu.VGNode{{Type: vugu.ElementNode, Data: "div", FirstChild: text}}}
+}
+
+// handlerTestCounter is a child component which counts how often its instance was built
+type handlerTestCounter struct{ builds int }
+
+func (c *handlerTestCounter) Build(in *vugu.BuildIn) *vugu.BuildOut {
+	c.builds++
+	text := &vugu.VGNode{Type: vugu.TextNode, Data: fmt.Sprintf("builds %d", c.builds)}
+	return &vugu.BuildOut{Out: []*vugu.VGNode{{Type: vugu.ElementNode, Data: "span", FirstChild: text}}}
+}
+
+// handlerTestParent builds a handlerTestCounter the way generated code does
+type handlerTestParent struct{}
+
+func (c *handlerTestParent) Build(vgin *vugu.BuildIn) *vugu.BuildOut {
+	vgcompKey := vugu.MakeCompKey(0x1234^vgin.CurrentPositionHash(), nil)
+	vgcomp, _ := vgin.BuildEnv.CachedComponent(vgcompKey).(*handlerTestCounter)
+	if vgcomp == nil {
+		vgcomp = new(handlerTestCounter)
+	}
+	vgin.BuildEnv.UseComponent(vgcompKey, vgcomp)
+	child := &vugu.VGNode{Component: vgcomp}
+	return &vugu.BuildOut{
+		Out:        []*vugu.VGNode{{Type: vugu.ElementNode, Data: "div", FirstChild: child}},
+		Components: []vugu.Builder{vgcomp},
+	}
+}
+
+type handlerTestNotFound struct{ handlerTestPage }
+
+func (c *handlerTestNotFound) HTTPStatusCode() int { return http.StatusNotFound }
+
+func (c *handlerTestNotFound) SetHTTPHeader(h http.Header) { h.Set("X-Test", "notfound") }
+
+func TestHandler(t *testing.T) {
+
+	assert := assert.New(t)
+
+	h := NewHandler(func(r *http.Request) (vugu.Builder, error) {
+		switch r.URL.Path {
+		case "/missing":
+			return &handlerTestNotFound{handlerTestPage{path: r.URL.Path}}, nil
+		case "/slow":
+			return &handlerTestPage{path: r.URL.Path, delay: 200 * time.Millisecond}, nil
+		case "/error":
+			return nil, errors.New("no root")
+		case "/counter":
+			return &handlerTestParent{}, nil
+		}
+		return &handlerTestPage{path: r.URL.Path}, nil
+	})
+	h.Timeout = 50 * time.Millisecond
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/a", nil))
+	assert.Equal(200, rec.Code)
+	assert.Equal("text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal("<div>path /a</div>", rec.Body.String())
+	assert.True(rec.Flushed)
+
+	for i := 0; i < 2; i++ { // components must not be reused from an earlier request
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/counter", nil))
+		assert.Equal("<div><span>builds 1</span></div>", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/missing", nil))
+	assert.Equal(404, rec.Code)
+	assert.Equal("notfound", rec.Header().Get("X-Test"))
+	assert.Equal("<div>path /missing</div>", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/slow", nil))
+	assert.Equal(http.StatusGatewayTimeout, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/error", nil))
+	assert.Equal(500, rec.Code)
+	assert.NotContains(rec.Body.String(), "no root") // only logged
+}
@@ -0,0 +1,274 @@
+package staticrender
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/vugu/html"
+	"github.com/vugu/vugu"
+)
+
+// OutputMode selects how the HTML output is formatted.
+type OutputMode int
+
+const (
+	// OutputDefault renders the output as is with html.Render.
+	OutputDefault OutputMode = iota
+	// OutputIndent puts each element on its own line, indented according to its depth.
+	// Useful for readable snapshots and diffs in tests.  Note that this changes whitespace
+	// between elements and so may affect layout.
+	OutputIndent
+	// OutputMinify collapses insignificant whitespace, removes comments and omits attribute
+	// quotes where they are optional.  Intended for production pages.
+	OutputMinify
+)
+
+// indentString is the indentation per level for OutputIndent
+const indentString = "  "
+
+// SetOutputMode sets the OutputMode for subsequent calls to Render.
+// In every mode the content of pre, textarea, script and style elements is left untouched.
+func (r *StaticRenderer) SetOutputMode(m OutputMode) {
+	r.outputMode = m
+}
+
+// writeOutput formats and writes the final node tree according to the output mode
+func (r *StaticRenderer) writeOutput(w io.Writer, n *html.Node) error {
+	switch r.outputMode {
+	case OutputIndent:
+		indentTree(n, 0)
+		err := html.Render(w, n)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, "\n")
+		return err
+	case OutputMinify:
+		minifyTree(n)
+		bw := bufio.NewWriter(w)
+		renderMinified(bw, n)
+		return bw.Flush()
+	}
+	return html.Render(w, n)
+}
+
+// preserveContent returns true for elements whose content must not be changed
+func preserveContent(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	switch n.Data {
+	case "pre", "textarea", "script", "style":
+		return true
+	}
+	return false
+}
+
+// indentTree inserts newlines and indentation between the children of elements which contain other elements
+func indentTree(n *html.Node, depth int) {
+
+	if preserveContent(n) {
+		return
+	}
+
+	// whitespace between elements is replaced by our own
+	hasNonText := false
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.TextNode {
+			c.Data = strings.TrimSpace(c.Data)
+			if c.Data == "" {
+				n.RemoveChild(c)
+			}
+		} else {
+			hasNonText = true
+		}
+		c = next
+	}
+
+	// text only content stays on the same line as the element
+	if !hasNonText {
+		return
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		n.InsertBefore(&html.Node{Type: html.TextNode, Data: "\n" + strings.Repeat(indentString, depth+1)}, c)
+		if c.Type == html.ElementNode {
+			indentTree(c, depth+1)
+		}
+	}
+	n.AppendChild(&html.Node{Type: html.TextNode, Data: "\n" + strings.Repeat(indentString, depth)})
+}
+
+// minifyTree removes comments and collapses whitespace
+func minifyTree(n *html.Node) {
+
+	if preserveContent(n) {
+		return
+	}
+
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch c.Type {
+		case html.CommentNode:
+			// the portal placeholder occupies a DOM position which hydration relies on
+			if c.Data != vugu.PortalPlaceholder {
+				n.RemoveChild(c)
+			}
+		case html.TextNode:
+			c.Data = collapseSpace(c.Data)
+		case html.ElementNode:
+			minifyTree(c)
+		}
+		c = next
+	}
+
+	// whitespace-only text next to block boundaries is insignificant
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.TextNode && (c.Data == " " || c.Data == "") {
+			before, after := c.PrevSibling, c.NextSibling
+			if (before == nil && isBlock(n) || before != nil && isBlock(before)) &&
+				(after == nil && isBlock(n) || after != nil && isBlock(after)) {
+				n.RemoveChild(c)
+			}
+		}
+		c = next
+	}
+}
+
+// collapseSpace replaces each run of whitespace with a single space
+func collapseSpace(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	inSpace := false
+	for _, c := range s {
+		switch c {
+		case ' ', '\t', '\n', '\r', '\f':
+			if !inSpace {
+				sb.WriteByte(' ')
+			}
+			inSpace = true
+		default:
+			sb.WriteRune(c)
+			inSpace = false
+		}
+	}
+	return sb.String()
+}
+
+var blockElements = map[string]bool{
+	"html": true, "head": true, "body": true, "title": true, "meta": true, "link": true, "style": true, "script": true,
+	"address": true, "article": true, "aside": true, "blockquote": true, "details": true, "dialog": true, "dd": true,
+	"div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true, "figure": true, "footer": true,
+	"form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hgroup": true,
+	"hr": true, "li": true, "main": true, "nav": true, "ol": true, "p": true, "pre": true, "section": true, "summary": true,
+	"table": true, "caption": true, "colgroup": true, "col": true, "thead": true, "tbody": true, "tfoot": true,
+	"tr": true, "td": true, "th": true, "ul": true, "select": true, "option": true, "optgroup": true,
+	"template": true, "noscript": true,
+}
+
+func isBlock(n *html.Node) bool {
+	return n.Type == html.ElementNode && blockElements[n.Data]
+}
+
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true, "input": true,
+	"keygen": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// elements whose text children are written without escaping, as in html.Render
+var rawTextElements = map[string]bool{
+	"iframe": true, "noembed": true, "noframes": true, "noscript": true, "plaintext": true,
+	"script": true, "style": true, "xmp": true,
+}
+
+// renderMinified writes n like html.Render but with the shortest attribute syntax.
+// Errors are reported by the caller's call to Flush.
+func renderMinified(w *bufio.Writer, n *html.Node) {
+
+	switch n.Type {
+
+	case html.DocumentNode:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			renderMinified(w, c)
+		}
+
+	case html.DoctypeNode:
+		w.WriteString("<!DOCTYPE ")
+		w.WriteString(n.Data)
+		w.WriteString(">")
+
+	case html.CommentNode:
+		w.WriteString("<!--")
+		w.WriteString(n.Data)
+		w.WriteString("-->")
+
+	case html.TextNode:
+		if n.Parent != nil && n.Parent.Type == html.ElementNode && rawTextElements[n.Parent.Data] {
+			w.WriteString(n.Data)
+		} else {
+			w.WriteString(escapeText(n.Data))
+		}
+
+	case html.ElementNode:
+		w.WriteByte('<')
+		w.WriteString(n.Data)
+		for _, a := range n.Attr {
+			w.WriteByte(' ')
+			if a.Namespace != "" {
+				w.WriteString(a.Namespace)
+				w.WriteByte(':')
+			}
+			w.WriteString(a.Key)
+			if a.Val == "" {
+				continue
+			}
+			w.WriteByte('=')
+			if unquotedAttrOK(a.Val) {
+				w.WriteString(a.Val)
+			} else {
+				w.WriteByte('"')
+				w.WriteString(attrEscaper.Replace(a.Val))
+				w.WriteByte('"')
+			}
+		}
+		w.WriteByte('>')
+		if voidElements[n.Data] {
+			return
+		}
+		// a leading newline in these is dropped by the parser, so one is added to keep it (as html.Render does)
+		switch n.Data {
+		case "pre", "listing", "textarea":
+			if c := n.FirstChild; c != nil && c.Type == html.TextNode && strings.HasPrefix(c.Data, "\n") {
+				w.WriteByte('\n')
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			renderMinified(w, c)
+		}
+		w.WriteString("</")
+		w.WriteString(n.Data)
+		w.WriteByte('>')
+	}
+}
+
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#13;")
+
+var attrEscaper = strings.NewReplacer("&", "&amp;", `"`, "&#34;", "\r", "&#13;")
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// unquotedAttrOK returns true if the attribute value can be written without quotes
+func unquotedAttrOK(v string) bool {
+	for i := 0; i < len(v); i++ {
+		switch v[i] {
+		case ' ', '\t', '\n', '\r', '\f', '"', '\'', '=', '<', '>', '`', '&':
+			return false
+		}
+	}
+	return v != ""
+}
@@ -0,0 +1,66 @@
+package staticrender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vugu/vugu"
+)
+
+func TestRendererStaticOutputMode(t *testing.T) {
+
+	assert := assert.New(t)
+
+	// the parser drops the first newline after <pre>, so the second one must be written back out as two
+	innerHTML := "<ul>\n  <li class=\"x y\">one</li>\n  <li>two  <b>bold</b> <i>it</i></li>\n</ul>" +
+		"<!-- note --><pre>\n\n  keep   this\n</pre><textarea>  as\n is </textarea>" +
+		"<script>if (a <  b) {  x() }</script><style>p  { color: red }</style>" +
+		"<p><input type=\"checkbox\" checked=\"\" value=\"a&amp;b\" title=\"t\"></p>"
+	tree := func() *vugu.VGNode {
+		div := &vugu.VGNode{Type: vugu.ElementNode, Data: "div", InnerHTML: &innerHTML}
+		div.Attr = append(div.Attr, vugu.VGAttribute{Key: "id", Val: "main"})
+		return div
+	}
+
+	r := New(nil)
+	r.SetOutputMode(OutputIndent)
+	assert.Equal(`<div id="main">
+  <ul>
+    <li class="x y">one</li>
+    <li>
+      two
+      <b>bold</b>
+      <i>it</i>
+    </li>
+  </ul>
+  <!-- note -->
+  <pre>
+
+  keep   this
+</pre>
+  <textarea>  as
+ is </textarea>
+  <script>if (a <  b) {  x() }</script>
+  <style>p  { color: red }</style>
+  <p>
+    <input type="checkbox" checked="" value="a&amp;b" title="t"/>
+  </p>
+</div>
+`, propsTestRender(t, r, tree()))
+
+	r = New(nil)
+	r.SetOutputMode(OutputMinify)
+	assert.Equal(`<div id=main>`+
+		`<ul><li class="x y">one</li><li>two <b>bold</b> <i>it</i></li></ul>`+
+		"<pre>\n\n  keep   this\n</pre><textarea>  as\n is </textarea>"+
+		`<script>if (a <  b) {  x() }</script><style>p  { color: red }</style>`+
+		`<p><input type=checkbox checked value="a&amp;b" title=t></p>`+
+		`</div>`, propsTestRender(t, r, tree()))
+
+	// portal placeholder is kept by minify
+	portalOut := &vugu.VGNode{Type: vugu.ElementNode, Data: "div"}
+	portal := &vugu.VGNode{Type: vugu.ElementNode, PortalTarget: "#t"}
+	target := &vugu.VGNode{Type: vugu.ElementNode, Data: "div", Attr: []vugu.VGAttribute{{Key: "id", Val: "t"}}}
+	portalOut.FirstChild, portal.NextSibling = portal, target
+	assert.Equal(`<div><!--vg-portal--><div id=t></div></div>`, propsTestRender(t, r, portalOut))
+}
@@ -0,0 +1,200 @@
+package staticrender
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vugu/html"
+)
+
+// PropFunc applies a JS property (VGNode.Prop) to the static output of an element, usually by
+// setting the equivalent attribute.  It is called after the element's attributes and children
+// have been converted.  val is the property value decoded from JSON.
+type PropFunc func(n *html.Node, val interface{}) error
+
+// DefaultPropFuncs maps well-known DOM properties to functions that set their attribute equivalents,
+// so the static output shows the same state as the browser would after the properties are set.
+// Properties not in the map are not rendered.  Use StaticRenderer.SetPropFunc to add or override entries.
+var DefaultPropFuncs = map[string]PropFunc{
+	"value":          propValue,
+	"checked":        propBoolAttr("checked"),
+	"selected":       propBoolAttr("selected"),
+	"disabled":       propBoolAttr("disabled"),
+	"readOnly":       propBoolAttr("readonly"),
+	"required":       propBoolAttr("required"),
+	"multiple":       propBoolAttr("multiple"),
+	"hidden":         propBoolAttr("hidden"),
+	"defaultValue":   propStringAttr("value"),
+	"defaultChecked": propBoolAttr("checked"),
+	"className":      propStringAttr("class"),
+	"htmlFor":        propStringAttr("for"),
+	"id":             propStringAttr("id"),
+	"title":          propStringAttr("title"),
+	"placeholder":    propStringAttr("placeholder"),
+	"textContent":    propText,
+	"innerText":      propText,
+}
+
+// SetPropFunc sets the function used to render the property with the specified key, overriding
+// DefaultPropFuncs.  A nil f means the property is not rendered.
+func (r *StaticRenderer) SetPropFunc(key string, f PropFunc) {
+	if r.propFuncs == nil {
+		r.propFuncs = make(map[string]PropFunc)
+	}
+	r.propFuncs[key] = f
+}
+
+// applyProp decodes the JSON value and calls the PropFunc for key, if any
+func (r *StaticRenderer) applyProp(n *html.Node, key string, jsonVal []byte) error {
+	return applyProp(r.propFuncs, n, key, jsonVal)
+}
+
+// applyProp uses the PropFunc from overrides if present, otherwise from DefaultPropFuncs
+func applyProp(overrides map[string]PropFunc, n *html.Node, key string, jsonVal []byte) error {
+	f, ok := overrides[key]
+	if !ok {
+		f = DefaultPropFuncs[key]
+	}
+	if f == nil {
+		return nil
+	}
+	var val interface{}
+	err := json.Unmarshal(jsonVal, &val)
+	if err != nil {
+		return fmt.Errorf("property %q has invalid JSON value %q: %w", key, jsonVal, err)
+	}
+	return f(n, val)
+}
+
+// propValue sets the value attribute, except for textarea where the value is the content
+// and select where it determines which option is selected.
+func propValue(n *html.Node, val interface{}) error {
+	s := propString(val)
+	switch n.Data {
+	case "textarea":
+		setText(n, s)
+	case "select":
+		walkElements(n, func(o *html.Node) {
+			if o.Data != "option" {
+				return
+			}
+			if optionValue(o) == s {
+				setAttr(o, "selected", "")
+			} else {
+				removeAttr(o, "selected")
+			}
+		})
+	default:
+		setAttr(n, "value", s)
+	}
+	return nil
+}
+
+func propText(n *html.Node, val interface{}) error {
+	setText(n, propString(val))
+	return nil
+}
+
+func propBoolAttr(key string) PropFunc {
+	return func(n *html.Node, val interface{}) error {
+		if propBool(val) {
+			setAttr(n, key, "")
+		} else {
+			removeAttr(n, key)
+		}
+		return nil
+	}
+}
+
+func propStringAttr(key string) PropFunc {
+	return func(n *html.Node, val interface{}) error {
+		setAttr(n, key, propString(val))
+		return nil
+	}
+}
+
+// propString converts a decoded JSON value to a string the same way JS would when assigning to a string property
+func propString(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	b, _ := json.Marshal(val)
+	return string(b)
+}
+
+// propBool converts a decoded JSON value to a bool using JS truthiness
+func propBool(val interface{}) bool {
+	switch v := val.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	}
+	return true
+}
+
+func setAttr(n *html.Node, key, val string) {
+	for i := range n.Attr {
+		if n.Attr[i].Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: val})
+}
+
+func removeAttr(n *html.Node, key string) {
+	for i := range n.Attr {
+		if n.Attr[i].Key == key {
+			n.Attr = append(n.Attr[:i], n.Attr[i+1:]...)
+			return
+		}
+	}
+}
+
+func setText(n *html.Node, s string) {
+	for n.FirstChild != nil {
+		n.RemoveChild(n.FirstChild)
+	}
+	if s != "" {
+		n.AppendChild(&html.Node{Type: html.TextNode, Data: s})
+	}
+}
+
+// optionValue is the value attribute of an option or its text if there is none
+func optionValue(o *html.Node) string {
+	for _, a := range o.Attr {
+		if a.Key == "value" {
+			return a.Val
+		}
+	}
+	var sb strings.Builder
+	for c := o.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			sb.WriteString(c.Data)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func walkElements(n *html.Node, f func(n *html.Node)) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			f(c)
+			walkElements(c, f)
+		}
+	}
+}
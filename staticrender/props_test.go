@@ -0,0 +1,84 @@
+package staticrender
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vugu/html"
+	"github.com/vugu/vugu"
+)
+
+// propsTestComp outputs a fixed tree
+type propsTestComp struct{ out *vugu.VGNode }
+
+func (c *propsTestComp) Build(in *vugu.BuildIn) *vugu.BuildOut {
+	return &vugu.BuildOut{Out: []*vugu.VGNode{c.out}}
+}
+
+func propsTestEl(tag string, props map[string]string, children ...*vugu.VGNode) *vugu.VGNode {
+	n := &vugu.VGNode{Type: vugu.ElementNode, Data: tag}
+	for k, v := range props {
+		n.Prop = append(n.Prop, vugu.VGProperty{Key: k, JSONVal: []byte(v)})
+	}
+	for i := len(children) - 1; i >= 0; i-- {
+		children[i].NextSibling = n.FirstChild
+		n.FirstChild = children[i]
+	}
+	return n
+}
+
+func propsTestRender(t *testing.T, r *StaticRenderer, n *vugu.VGNode) string {
+	be, err := vugu.NewBuildEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	r.SetWriter(&buf)
+	err = r.Render(be.RunBuild(&propsTestComp{out: n}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestRendererStaticProps(t *testing.T) {
+
+	assert := assert.New(t)
+
+	opt := func(v string, props map[string]string) *vugu.VGNode {
+		o := propsTestEl("option", props, &vugu.VGNode{Type: vugu.TextNode, Data: "opt " + v})
+		o.Attr = append(o.Attr, vugu.VGAttribute{Key: "value", Val: v})
+		return o
+	}
+
+	out := propsTestRender(t, New(nil), propsTestEl("form", nil,
+		propsTestEl("input", map[string]string{"value": `"a&b"`, "checked": `true`, "disabled": `false`}),
+		propsTestEl("input", map[string]string{"value": `1.5`}),
+		propsTestEl("textarea", map[string]string{"value": `"some text"`}, &vugu.VGNode{Type: vugu.TextNode, Data: "old"}),
+		propsTestEl("select", map[string]string{"value": `"b"`}, opt("a", nil), opt("b", nil)),
+		propsTestEl("select", nil, opt("c", map[string]string{"selected": `true`})),
+		propsTestEl("div", map[string]string{"unknownProp": `{"x":1}`}),
+	))
+	assert.Equal(`<form>`+
+		`<input value="a&amp;b" checked=""/>`+
+		`<input value="1.5"/>`+
+		`<textarea>some text</textarea>`+
+		`<select><option value="a">opt a</option><option value="b" selected="">opt b</option></select>`+
+		`<select><option value="c" selected="">opt c</option></select>`+
+		`<div></div>`+
+		`</form>`, out)
+
+	// custom mapping and disabling a default one
+	r := New(nil)
+	r.SetPropFunc("unknownProp", func(n *html.Node, val interface{}) error {
+		n.Attr = append(n.Attr, html.Attribute{Key: "data-x", Val: propString(val.(map[string]interface{})["x"])})
+		return nil
+	})
+	r.SetPropFunc("checked", nil)
+	out = propsTestRender(t, r, propsTestEl("div", nil,
+		propsTestEl("span", map[string]string{"unknownProp": `{"x":1}`}),
+		propsTestEl("input", map[string]string{"checked": `true`}),
+	))
+	assert.Equal(`<div><span data-x="1"></span><input/></div>`, out)
+}
@@ -3,6 +3,7 @@ package staticrender
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -27,6 +28,31 @@ func New(w io.Writer) *StaticRenderer {
 // StaticRenderer provides rendering as static HTML to an io.Writer.
 type StaticRenderer struct {
 	w io.Writer
+
+	// portals encountered during the current render, placed into their targets at the end
+	portals []staticPortal
+
+	// emit PositionMarkerAttr on elements
+	positionMarkers bool
+
+	// position ID of the root element, see SetRootPositionID
+	rootPositionID string
+
+	// overrides for DefaultPropFuncs, see SetPropFunc
+	propFuncs map[string]PropFunc
+
+	outputMode OutputMode
+}
+
+// PositionMarkerAttr is the attribute emitted on each element when position markers are enabled.
+// Its value is the same position ID that domrender uses for the element, which allows
+// JSRenderer (with Hydrate set) to take over the server rendered DOM.
+const PositionMarkerAttr = "data-vgpos"
+
+// staticPortal is the converted content of a portal and the selector of where it goes
+type staticPortal struct {
+	target string
+	nodes  []*html.Node
 }
 
 // SetWriter assigns the Writer to be used for subsequent calls to Render.
@@ -35,15 +61,54 @@ func (r *StaticRenderer) SetWriter(w io.Writer) {
 	r.w = w
 }
 
+// SetPositionMarkers enables or disables emitting PositionMarkerAttr on each element.
+// Enable this when the output will be hydrated by domrender in the browser.
+func (r *StaticRenderer) SetPositionMarkers(v bool) {
+	r.positionMarkers = v
+}
+
+// SetRootPositionID sets the position ID of the root element, which the position IDs of all other
+// elements are derived from.  The default (an empty id) is "0", or "html" if the root element is html,
+// which is what JSRenderer.Render uses.  Islands created with JSRenderer.AddIsland are rendered with
+// "i1", "i2" and so on, in the order they were added, so to hydrate an island its server rendered
+// markup must use the same ID.
+func (r *StaticRenderer) SetRootPositionID(id string) {
+	r.rootPositionID = id
+}
+
+// rootID returns the position ID of the root element
+func rootID(id string, bo *vugu.BuildOut) string {
+	if id != "" {
+		return id
+	}
+	// domrender uses fixed positions for a full html document
+	if vgn := bo.Out[0]; vgn.Type == vugu.ElementNode && strings.ToLower(vgn.Data) == "html" {
+		return "html"
+	}
+	return "0"
+}
+
 // Render will perform a static render of the given BuildResults and write it to the writer assigned.
 func (r *StaticRenderer) Render(buildResults *vugu.BuildResults) error {
 
-	n, err := r.renderOne(buildResults, buildResults.Out)
+	r.portals = r.portals[:0]
+
+	bo := buildResults.Out
+	if len(bo.Out) != 1 {
+		return fmt.Errorf("BuildOut must contain exactly one element in Out")
+	}
+
+	n, err := r.renderOne(buildResults, bo, rootID(r.rootPositionID, bo))
+	if err != nil {
+		return err
+	}
+
+	err = r.placePortals(n)
 	if err != nil {
 		return err
 	}
 
-	err = html.Render(r.w, n)
+	err = r.writeOutput(r.w, n)
 	if err != nil {
 		return err
 	}
@@ -52,7 +117,9 @@ func (r *StaticRenderer) Render(buildResults *vugu.BuildResults) error {
 
 }
 
-func (r *StaticRenderer) renderOne(br *vugu.BuildResults, bo *vugu.BuildOut) (*html.Node, error) {
+// renderOne converts the output of one component.  positionID is assigned the same way as
+// in domrender so markers match, an empty positionID means no markers for this subtree.
+func (r *StaticRenderer) renderOne(br *vugu.BuildResults, bo *vugu.BuildOut, positionID string) (*html.Node, error) {
 
 	if len(bo.Out) != 1 {
 		return nil, fmt.Errorf("BuildOut must contain exactly one element in Out")
@@ -60,15 +127,15 @@ func (r *StaticRenderer) renderOne(br *vugu.BuildResults, bo *vugu.BuildOut) (*h
 
 	vgn := bo.Out[0]
 
-	var visit func(vgn *vugu.VGNode) ([]*html.Node, error)
-	visit = func(vgn *vugu.VGNode) ([]*html.Node, error) {
+	var visit func(vgn *vugu.VGNode, positionID string) ([]*html.Node, error)
+	visit = func(vgn *vugu.VGNode, positionID string) ([]*html.Node, error) {
 
 		// log.Printf("vgn: %#v", vgn)
 
 		// if component then look up BuildOut for it and call renderOne again and return
 		if vgn.Component != nil {
 			cbo := br.ResultFor(vgn.Component)
-			retn, err := r.renderOne(br, cbo)
+			retn, err := r.renderOne(br, cbo, positionID)
 			if err != nil {
 				return nil, err
 			}
@@ -79,15 +146,33 @@ func (r *StaticRenderer) renderOne(br *vugu.BuildResults, bo *vugu.BuildOut) (*h
 			// return retn[0], nil
 		}
 
+		// if portal then convert the children to be placed later and output a placeholder here
+		if vgn.IsPortal() {
+			var portalNodes []*html.Node
+			i := 1
+			for vgchild := vgn.FirstChild; vgchild != nil; vgchild = vgchild.NextSibling {
+				nchildren, err := visit(vgchild, childPositionID(positionID, "_p_", i))
+				if err != nil {
+					return nil, err
+				}
+				portalNodes = append(portalNodes, nchildren...)
+				i++
+			}
+			r.portals = append(r.portals, staticPortal{target: vgn.PortalTarget, nodes: portalNodes})
+			return []*html.Node{{Type: html.CommentNode, Data: vugu.PortalPlaceholder}}, nil
+		}
+
 		// if template then just traverse the children directly and return them in a series, omitting vgn
 		if vgn.IsTemplate() {
 			var retn []*html.Node
+			i := 1
 			for vgchild := vgn.FirstChild; vgchild != nil; vgchild = vgchild.NextSibling {
-				nchildren, err := visit(vgchild)
+				nchildren, err := visit(vgchild, childPositionID(positionID, "_t_", i))
 				if err != nil {
 					return nil, err
 				}
 				retn = append(retn, nchildren...)
+				i++
 			}
 			return retn, nil
 		}
@@ -100,6 +185,9 @@ func (r *StaticRenderer) renderOne(br *vugu.BuildResults, bo *vugu.BuildOut) (*h
 		for _, vgattr := range vgn.Attr {
 			n.Attr = append(n.Attr, html.Attribute{Key: vgattr.Key, Val: vgattr.Val})
 		}
+		if r.positionMarkers && positionID != "" && n.Type == html.ElementNode {
+			n.Attr = append(n.Attr, html.Attribute{Key: PositionMarkerAttr, Val: positionID})
+		}
 
 		// handle InnerHTML
 
@@ -115,18 +203,31 @@ func (r *StaticRenderer) renderOne(br *vugu.BuildResults, bo *vugu.BuildOut) (*h
 			// }
 			appendChildren(n, nparts)
 
+			err = r.applyProps(n, vgn)
+			if err != nil {
+				return nil, err
+			}
+
 			// InnerHTML precludes other children
 			return []*html.Node{n}, nil
 		}
 
 		// handle children
+		i := 1
 		for vgchild := vgn.FirstChild; vgchild != nil; vgchild = vgchild.NextSibling {
-			nchildren, err := visit(vgchild)
+			nchildren, err := visit(vgchild, elementChildPositionID(n.Data, vgchild, positionID, i))
 			if err != nil {
 				return nil, err
 			}
 			// n.AppendChild(nchildren)
 			appendChildren(n, nchildren)
+			i++
+		}
+
+		// properties are applied once the children exist, since some affect them (e.g. textarea value)
+		err := r.applyProps(n, vgn)
+		if err != nil {
+			return nil, err
 		}
 
 		// special case for <head>, we need to emit the CSS here as that is separate
@@ -136,7 +237,7 @@ func (r *StaticRenderer) renderOne(br *vugu.BuildResults, bo *vugu.BuildOut) (*h
 			for _, css := range bo.CSS {
 
 				// convert each one
-				nchildren, err := visit(css)
+				nchildren, err := visit(css, "")
 				if err != nil {
 					return nil, err
 				}
@@ -150,7 +251,7 @@ func (r *StaticRenderer) renderOne(br *vugu.BuildResults, bo *vugu.BuildOut) (*h
 			for _, js := range bo.JS {
 
 				// convert each one
-				nchildren, err := visit(js)
+				nchildren, err := visit(js, "")
 				if err != nil {
 					return nil, err
 				}
@@ -162,7 +263,7 @@ func (r *StaticRenderer) renderOne(br *vugu.BuildResults, bo *vugu.BuildOut) (*h
 		return []*html.Node{n}, nil
 	}
 
-	nret, err := visit(vgn)
+	nret, err := visit(vgn, positionID)
 	if err != nil {
 		return nil, err
 	}
@@ -172,6 +273,76 @@ func (r *StaticRenderer) renderOne(br *vugu.BuildResults, bo *vugu.BuildOut) (*h
 	return nret[0], nil
 }
 
+// applyProps renders the properties of vgn onto n
+func (r *StaticRenderer) applyProps(n *html.Node, vgn *vugu.VGNode) error {
+	for _, p := range vgn.Prop {
+		err := r.applyProp(n, p.Key, p.JSONVal)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// childPositionID returns the position of the nth child (starting at 1) with the separator used by domrender
+func childPositionID(positionID, sep string, n int) string {
+	if positionID == "" {
+		return ""
+	}
+	return positionID + sep + strconv.Itoa(n)
+}
+
+// elementChildPositionID returns the position ID for the ith child of an element, following
+// domrender: inside a full document html, head and body have fixed positions, the contents
+// of head are not synced and the single child of body (the mount point) shares its position.
+func elementChildPositionID(tag string, vgchild *vugu.VGNode, positionID string, i int) string {
+	if positionID == "" {
+		return ""
+	}
+	switch {
+	case tag == "html" && positionID == "html":
+		switch strings.ToLower(vgchild.Data) {
+		case "head", "body":
+			return strings.ToLower(vgchild.Data)
+		}
+		return ""
+	case tag == "head" && positionID == "head":
+		return ""
+	case tag == "body" && positionID == "body":
+		return "body"
+	}
+	return positionID + "_" + strconv.Itoa(i)
+}
+
+// placePortals appends the content of each portal to its target element within root.
+// Portal targets may themselves be inside other portals' content, so we keep placing
+// until everything is placed or no more progress can be made.
+func (r *StaticRenderer) placePortals(root *html.Node) error {
+
+	pending := r.portals
+	for len(pending) > 0 {
+		var next []staticPortal
+		for _, p := range pending {
+			sel, err := parseSimpleSelector(p.target)
+			if err != nil {
+				return fmt.Errorf("invalid portal target: %w", err)
+			}
+			target := sel.find(root)
+			if target == nil {
+				next = append(next, p)
+				continue
+			}
+			appendChildren(target, p.nodes)
+		}
+		if len(next) == len(pending) {
+			return fmt.Errorf("portal target %q not found in output", next[0].target)
+		}
+		pending = next
+	}
+
+	return nil
+}
+
 func appendChildren(parent *html.Node, children []*html.Node) {
 	for _, c := range children {
 		parent.AppendChild(c)
@@ -101,6 +101,49 @@ comp1 in the house
 			},
 			outReNotMatch: []string{`vg-template`},
 		},
+		{
+			name:      "vg-portal",
+			opts:      gen.ParserGoPkgOpts{},
+			recursive: false,
+			infiles: map[string]string{
+				"root.vugu":  `<html><body><div><div class="content"><span>example1</span><vg-portal target="#modals"><div class="modal">in the modal</div><main:Comp1/></vg-portal></div><div id="modals"></div></div></body></html>`,
+				"comp1.vugu": `<p>comp1 in the modal</p>`,
+			},
+			outReMatch: []string{
+				`<span>example1</span><!--vg-portal--></div>`,
+				`<div id="modals"><div class="modal">in the modal</div><p>comp1 in the modal</p></div>`,
+			},
+			outReNotMatch: []string{`vg-portal>`},
+		},
+		{
+			name:      "position-markers",
+			opts:      gen.ParserGoPkgOpts{},
+			recursive: false,
+			infiles: map[string]string{
+				"root.vugu":  `<div><span :class='"c"'>a</span><main:Comp1></main:Comp1><vg-template><b :class='"c"'>t1</b><i :class='"c"'>t2</i></vg-template><vg-portal target="#modals"><p :class='"c"'>in portal</p></vg-portal><div id="modals"></div></div>`,
+				"comp1.vugu": `<p>comp1 <em :class='"c"'>x</em></p>`,
+			},
+			bfiles: map[string]string{"main.go": tstMarkersMainGo},
+			outReMatch: []string{
+				`<div data-vgpos="0"><span class="c" data-vgpos="0_1">a</span>`,
+				`<p data-vgpos="0_2">comp1 <em class="c" data-vgpos="0_2_2">x</em></p>`,
+				`<b class="c" data-vgpos="0_3_t_1">t1</b><i class="c" data-vgpos="0_3_t_2">t2</i><!--vg-portal-->`,
+				`<div id="modals" data-vgpos="0_5"><p class="c" data-vgpos="0_4_p_1">in portal</p></div>`,
+			},
+		},
+		{
+			name:      "position-markers-full-html",
+			opts:      gen.ParserGoPkgOpts{},
+			recursive: false,
+			infiles: map[string]string{
+				"root.vugu": `<html><head><title>x</title></head><body><div id="app"><span :class='"c"'>a</span></div></body></html>`,
+			},
+			bfiles: map[string]string{"main.go": tstMarkersMainGo},
+			outReMatch: []string{
+				`<html data-vgpos="html"><head data-vgpos="head"><title>x</title>`,
+				`<body data-vgpos="body"><div id="app" data-vgpos="body"><span class="c" data-vgpos="body_1">a</span>`,
+			},
+		},
 	}
 
 	for _, tc := range tcList {
@@ -212,6 +255,28 @@ func main() {
 
 }
 
+// tstMarkersMainGo is main.go for test cases that render with position markers
+const tstMarkersMainGo = `// +build !wasm
+
+package main
+
+import (
+	"os"
+
+	"github.com/vugu/vugu"
+	"github.com/vugu/vugu/staticrender"
+)
+
+func main() {
+	buildEnv, err := vugu.NewBuildEnv()
+	if err != nil { panic(err) }
+	renderer := staticrender.New(os.Stdout)
+	renderer.SetPositionMarkers(true)
+	err = renderer.Render(buildEnv.RunBuild(&Root{}))
+	if err != nil { panic(err) }
+}
+`
+
 func tstWriteFiles(dir string, m map[string]string) {
 
 	for name, contents := range m {
@@ -0,0 +1,348 @@
+package staticrender
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/vugu/html"
+	"github.com/vugu/vugu"
+)
+
+// NewStream returns a new StreamRenderer.  w may be nil as long as SetWriter is called with a valid value before rendering.
+func NewStream(w io.Writer) *StreamRenderer {
+	return &StreamRenderer{
+		w: w,
+	}
+}
+
+// StreamRenderer renders static HTML like StaticRenderer, but writes it to the io.Writer as it walks
+// the build output instead of first converting the whole page to an html.Node tree.  This uses
+// less memory and the first bytes are written sooner, which matters for large pages.
+//
+// The output is the same as StaticRenderer in OutputDefault mode with these differences:
+// InnerHTML (vg-html and compacted static content) is written as is instead of being parsed and re-rendered,
+// and a portal's target must come after the portal in the document, since content cannot be added to
+// an element that has already been written.
+type StreamRenderer struct {
+	w  io.Writer
+	bw *bufio.Writer
+
+	positionMarkers bool
+	rootPositionID  string
+	propFuncs       map[string]PropFunc
+
+	portals     []streamPortal // portal content waiting for its target element
+	rawText     bool           // writing children of an element whose text is not escaped (script, style, etc.)
+	selectValue *string        // value of the select element whose options are being written, if set by its value property
+}
+
+// streamPortal is rendered portal content waiting for its target to be written
+type streamPortal struct {
+	target  string
+	sel     simpleSelector
+	content []byte
+}
+
+// SetWriter assigns the Writer to be used for subsequent calls to Render.
+func (r *StreamRenderer) SetWriter(w io.Writer) {
+	r.w = w
+}
+
+// SetPositionMarkers enables or disables emitting PositionMarkerAttr on each element, see StaticRenderer.SetPositionMarkers.
+func (r *StreamRenderer) SetPositionMarkers(v bool) {
+	r.positionMarkers = v
+}
+
+// SetRootPositionID sets the position ID of the root element, see StaticRenderer.SetRootPositionID.
+func (r *StreamRenderer) SetRootPositionID(id string) {
+	r.rootPositionID = id
+}
+
+// SetPropFunc sets the function used to render the property with the specified key, see StaticRenderer.SetPropFunc.
+// Only the element's attributes are available to f, except for the value property of select elements which is
+// handled by marking the matching option as selected.
+func (r *StreamRenderer) SetPropFunc(key string, f PropFunc) {
+	if r.propFuncs == nil {
+		r.propFuncs = make(map[string]PropFunc)
+	}
+	r.propFuncs[key] = f
+}
+
+// Render writes the HTML for the given BuildResults to the writer assigned.
+func (r *StreamRenderer) Render(buildResults *vugu.BuildResults) error {
+
+	if r.bw == nil {
+		r.bw = bufio.NewWriter(r.w)
+	} else {
+		r.bw.Reset(r.w)
+	}
+	r.portals = r.portals[:0]
+	r.rawText = false
+	r.selectValue = nil
+
+	bo := buildResults.Out
+	if len(bo.Out) != 1 {
+		return fmt.Errorf("BuildOut must contain exactly one element in Out")
+	}
+
+	err := r.visit(buildResults, bo, bo.Out[0], rootID(r.rootPositionID, bo))
+	if err != nil {
+		return err
+	}
+
+	if len(r.portals) > 0 {
+		return fmt.Errorf("portal target %q not found in output after the portal", r.portals[0].target)
+	}
+
+	return r.bw.Flush()
+}
+
+func (r *StreamRenderer) visit(br *vugu.BuildResults, bo *vugu.BuildOut, vgn *vugu.VGNode, positionID string) error {
+
+	// components output their own BuildOut in place
+	if vgn.Component != nil {
+		cbo := br.ResultFor(vgn.Component)
+		if cbo == nil || len(cbo.Out) != 1 {
+			return fmt.Errorf("BuildOut must contain exactly one element in Out")
+		}
+		return r.visit(br, cbo, cbo.Out[0], positionID)
+	}
+
+	// portal content is rendered to a buffer and written when its target is
+	if vgn.IsPortal() {
+		sel, err := parseSimpleSelector(vgn.PortalTarget)
+		if err != nil {
+			return fmt.Errorf("invalid portal target: %w", err)
+		}
+		var buf bytes.Buffer
+		outer := r.bw
+		r.bw = bufio.NewWriter(&buf)
+		i := 1
+		for vgchild := vgn.FirstChild; vgchild != nil; vgchild = vgchild.NextSibling {
+			err = r.visit(br, bo, vgchild, childPositionID(positionID, "_p_", i))
+			if err != nil {
+				r.bw = outer
+				return err
+			}
+			i++
+		}
+		err = r.bw.Flush()
+		r.bw = outer
+		if err != nil {
+			return err
+		}
+		r.portals = append(r.portals, streamPortal{target: vgn.PortalTarget, sel: sel, content: buf.Bytes()})
+		r.writeComment(vugu.PortalPlaceholder)
+		return nil
+	}
+
+	// templates output just their children
+	if vgn.IsTemplate() {
+		i := 1
+		for vgchild := vgn.FirstChild; vgchild != nil; vgchild = vgchild.NextSibling {
+			err := r.visit(br, bo, vgchild, childPositionID(positionID, "_t_", i))
+			if err != nil {
+				return err
+			}
+			i++
+		}
+		return nil
+	}
+
+	switch vgn.Type {
+	case vugu.TextNode:
+		r.writeText(vgn.Data)
+		return nil
+	case vugu.CommentNode:
+		r.writeComment(vgn.Data)
+		return nil
+	case vugu.ElementNode:
+		return r.visitElement(br, bo, vgn, positionID)
+	}
+
+	return fmt.Errorf("unknown node type %v", vgn.Type)
+}
+
+func (r *StreamRenderer) visitElement(br *vugu.BuildResults, bo *vugu.BuildOut, vgn *vugu.VGNode, positionID string) error {
+
+	// the element without children, used for properties and matching portal targets
+	n := &html.Node{Type: html.ElementNode, Data: vgn.Data}
+	for _, vgattr := range vgn.Attr {
+		n.Attr = append(n.Attr, html.Attribute{Key: vgattr.Key, Val: vgattr.Val})
+	}
+	if r.positionMarkers && positionID != "" {
+		n.Attr = append(n.Attr, html.Attribute{Key: PositionMarkerAttr, Val: positionID})
+	}
+
+	var selectValue *string
+	contentReplaced := false
+	for _, p := range vgn.Prop {
+		if n.Data == "select" && p.Key == "value" {
+			var v interface{}
+			err := json.Unmarshal(p.JSONVal, &v)
+			if err != nil {
+				return fmt.Errorf("property %q has invalid JSON value %q: %w", p.Key, p.JSONVal, err)
+			}
+			s := propString(v)
+			selectValue = &s
+			continue
+		}
+		err := applyProp(r.propFuncs, n, p.Key, p.JSONVal)
+		if err != nil {
+			return err
+		}
+		// these set the content, which can be empty so a check of n.FirstChild is not enough
+		if p.Key == "textContent" || p.Key == "innerText" || (p.Key == "value" && n.Data == "textarea") {
+			contentReplaced = true
+		}
+	}
+
+	if n.Data == "option" && r.selectValue != nil {
+		if streamOptionValue(vgn) == *r.selectValue {
+			setAttr(n, "selected", "")
+		} else {
+			removeAttr(n, "selected")
+		}
+	}
+
+	r.bw.WriteByte('<')
+	r.bw.WriteString(n.Data)
+	for _, a := range n.Attr {
+		r.bw.WriteByte(' ')
+		if a.Namespace != "" {
+			r.bw.WriteString(a.Namespace)
+			r.bw.WriteByte(':')
+		}
+		r.bw.WriteString(a.Key)
+		r.bw.WriteString(`="`)
+		r.bw.WriteString(html.EscapeString(a.Val))
+		r.bw.WriteByte('"')
+	}
+	if voidElements[n.Data] {
+		r.bw.WriteString("/>")
+		return nil
+	}
+	r.bw.WriteByte('>')
+
+	parentRawText, parentSelectValue := r.rawText, r.selectValue
+	r.rawText = rawTextElements[n.Data]
+	if selectValue != nil {
+		r.selectValue = selectValue
+	}
+
+	err := r.visitChildren(br, bo, vgn, n, positionID, contentReplaced || n.FirstChild != nil)
+
+	r.rawText, r.selectValue = parentRawText, parentSelectValue
+	if err != nil {
+		return err
+	}
+
+	// content from portals that target this element goes at the end
+	pending := r.portals[:0]
+	for _, p := range r.portals {
+		if p.sel.match(n) {
+			r.bw.Write(p.content)
+		} else {
+			pending = append(pending, p)
+		}
+	}
+	r.portals = pending
+
+	r.bw.WriteString("</")
+	r.bw.WriteString(n.Data)
+	r.bw.WriteByte('>')
+	return nil
+}
+
+// visitChildren writes the content of an element, which is the children of n instead of vgn if contentReplaced
+func (r *StreamRenderer) visitChildren(br *vugu.BuildResults, bo *vugu.BuildOut, vgn *vugu.VGNode, n *html.Node, positionID string, contentReplaced bool) error {
+
+	// a property replaced the content (e.g. textarea value)
+	if contentReplaced {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			r.writeLeadingNewline(n.Data, c.Data, c == n.FirstChild)
+			r.writeText(c.Data)
+		}
+		return nil
+	}
+
+	if vgn.InnerHTML != nil {
+		r.bw.WriteString(*vgn.InnerHTML)
+		return nil
+	}
+
+	i := 1
+	for vgchild := vgn.FirstChild; vgchild != nil; vgchild = vgchild.NextSibling {
+		if vgchild.Type == vugu.TextNode {
+			r.writeLeadingNewline(n.Data, vgchild.Data, vgchild == vgn.FirstChild)
+		}
+		err := r.visit(br, bo, vgchild, elementChildPositionID(n.Data, vgchild, positionID, i))
+		if err != nil {
+			return err
+		}
+		i++
+	}
+
+	// CSS goes at the end of head and JS at the end of body, as with StaticRenderer
+	var extra []*vugu.VGNode
+	switch n.Data {
+	case "head":
+		extra = bo.CSS
+	case "body":
+		extra = bo.JS
+	}
+	for _, x := range extra {
+		err := r.visit(br, bo, x, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeLeadingNewline writes the extra newline html.Render adds when the content of pre, listing or
+// textarea starts with one, since the parser drops the first newline
+func (r *StreamRenderer) writeLeadingNewline(tag, text string, first bool) {
+	if !first || !strings.HasPrefix(text, "\n") {
+		return
+	}
+	switch tag {
+	case "pre", "listing", "textarea":
+		r.bw.WriteByte('\n')
+	}
+}
+
+func (r *StreamRenderer) writeText(s string) {
+	if r.rawText {
+		r.bw.WriteString(s)
+		return
+	}
+	r.bw.WriteString(html.EscapeString(s))
+}
+
+func (r *StreamRenderer) writeComment(s string) {
+	r.bw.WriteString("<!--")
+	r.bw.WriteString(s)
+	r.bw.WriteString("-->")
+}
+
+// streamOptionValue is the value attribute of an option or its text content if there is none
+func streamOptionValue(vgn *vugu.VGNode) string {
+	for _, a := range vgn.Attr {
+		if a.Key == "value" {
+			return a.Val
+		}
+	}
+	var sb strings.Builder
+	for c := vgn.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == vugu.TextNode {
+			sb.WriteString(c.Data)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
@@ -0,0 +1,176 @@
+package staticrender
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vugu/vugu"
+)
+
+// streamTestComp outputs a fixed tree and builds the listed child components
+type streamTestComp struct {
+	out        *vugu.VGNode
+	css, js    []*vugu.VGNode
+	components []vugu.Builder
+}
+
+func (c *streamTestComp) Build(in *vugu.BuildIn) *vugu.BuildOut {
+	return &vugu.BuildOut{Out: []*vugu.VGNode{c.out}, CSS: c.css, JS: c.js, Components: c.components}
+}
+
+func streamTestText(s string) *vugu.VGNode {
+	return &vugu.VGNode{Type: vugu.TextNode, Data: s}
+}
+
+// streamTestPage builds a full page using most features with rows rows in a table
+func streamTestPage(rows int) *streamTestComp {
+
+	child := &streamTestComp{out: propsTestEl("p", nil, streamTestText("from <child> & co"))}
+
+	tbody := propsTestEl("tbody", nil)
+	var last *vugu.VGNode
+	for i := 0; i < rows; i++ {
+		tr := propsTestEl("tr", nil,
+			propsTestEl("td", nil, streamTestText(fmt.Sprintf("row %d", i))),
+			propsTestEl("td", nil, streamTestText(`"quoted" <value>`)),
+		)
+		tr.Attr = []vugu.VGAttribute{{Key: "class", Val: "row"}, {Key: "data-i", Val: fmt.Sprint(i)}}
+		if last == nil {
+			tbody.FirstChild = tr
+		} else {
+			last.NextSibling = tr
+		}
+		last = tr
+	}
+
+	tmpl := &vugu.VGNode{Type: vugu.ElementNode}
+	tmpl.FirstChild = propsTestEl("b", nil, streamTestText("t1"))
+	tmpl.FirstChild.NextSibling = propsTestEl("i", nil, streamTestText("t2"))
+
+	portal := &vugu.VGNode{Type: vugu.ElementNode, PortalTarget: "#modals"}
+	portal.FirstChild = propsTestEl("div", nil, streamTestText("modal"))
+	modals := propsTestEl("div", nil)
+	modals.Attr = []vugu.VGAttribute{{Key: "id", Val: "modals"}}
+
+	textarea := propsTestEl("textarea", map[string]string{"value": `"\nnew value"`}, streamTestText("old"))
+	opt := func(v string) *vugu.VGNode {
+		o := propsTestEl("option", nil, streamTestText(v))
+		o.Attr = []vugu.VGAttribute{{Key: "value", Val: v}}
+		return o
+	}
+
+	body := propsTestEl("body", nil, propsTestEl("div", nil,
+		propsTestEl("h1", nil, streamTestText("Report")),
+		&vugu.VGNode{Type: vugu.CommentNode, Data: " comment "},
+		&vugu.VGNode{Component: child},
+		tmpl,
+		portal,
+		propsTestEl("pre", nil, streamTestText("\n  indented")),
+		propsTestEl("input", map[string]string{"value": `"v"`, "checked": `true`}),
+		textarea,
+		propsTestEl("select", map[string]string{"value": `"b"`}, opt("a"), opt("b")),
+		propsTestEl("script", nil, streamTestText("if (a < b) {}")),
+		propsTestEl("table", nil, tbody),
+		modals,
+	))
+	html := propsTestEl("html", nil, propsTestEl("head", nil, propsTestEl("title", nil, streamTestText("Report"))), body)
+
+	return &streamTestComp{
+		out:        html,
+		css:        []*vugu.VGNode{propsTestEl("style", nil, streamTestText("td > b { color: red }"))},
+		js:         []*vugu.VGNode{propsTestEl("script", nil, streamTestText("console.log('<ok>')"))},
+		components: []vugu.Builder{child},
+	}
+}
+
+func TestStreamRendererMatchesStaticRenderer(t *testing.T) {
+
+	assert := assert.New(t)
+
+	for _, markers := range []bool{false, true} {
+
+		be, err := vugu.NewBuildEnv()
+		assert.NoError(err)
+		br := be.RunBuild(streamTestPage(3))
+
+		var staticBuf, streamBuf bytes.Buffer
+		sr := New(&staticBuf)
+		sr.SetPositionMarkers(markers)
+		assert.NoError(sr.Render(br))
+
+		str := NewStream(&streamBuf)
+		str.SetPositionMarkers(markers)
+		assert.NoError(str.Render(br))
+
+		assert.Equal(staticBuf.String(), streamBuf.String())
+		assert.Contains(streamBuf.String(), `<div id="modals"`)
+		assert.Contains(streamBuf.String(), `modal</div></div>`)
+	}
+}
+
+func TestStreamRendererPortalBeforeTarget(t *testing.T) {
+
+	portal := &vugu.VGNode{Type: vugu.ElementNode, PortalTarget: "#t"}
+	portal.FirstChild = streamTestText("x")
+	target := propsTestEl("div", nil)
+	target.Attr = []vugu.VGAttribute{{Key: "id", Val: "t"}}
+	root := propsTestEl("div", nil, target, portal)
+
+	be, err := vugu.NewBuildEnv()
+	assert.NoError(t, err)
+	err = NewStream(ioutil.Discard).Render(be.RunBuild(&streamTestComp{out: root}))
+	assert.Error(t, err)
+}
+
+func TestRootPositionID(t *testing.T) {
+
+	assert := assert.New(t)
+
+	root := propsTestEl("div", nil, propsTestEl("span", nil, streamTestText("x")))
+	be, err := vugu.NewBuildEnv()
+	assert.NoError(err)
+	br := be.RunBuild(&streamTestComp{out: root})
+
+	var staticBuf, streamBuf bytes.Buffer
+	sr := New(&staticBuf)
+	sr.SetPositionMarkers(true)
+	sr.SetRootPositionID("i2")
+	assert.NoError(sr.Render(br))
+
+	str := NewStream(&streamBuf)
+	str.SetPositionMarkers(true)
+	str.SetRootPositionID("i2")
+	assert.NoError(str.Render(br))
+
+	assert.Equal(`<div data-vgpos="i2"><span data-vgpos="i2_1">x</span></div>`, streamBuf.String())
+	assert.Equal(staticBuf.String(), streamBuf.String())
+}
+
+func benchmarkRender(b *testing.B, render func(br *vugu.BuildResults) error) {
+	be, err := vugu.NewBuildEnv()
+	if err != nil {
+		b.Fatal(err)
+	}
+	br := be.RunBuild(streamTestPage(5000))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := render(br)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStaticRenderer(b *testing.B) {
+	r := New(ioutil.Discard)
+	benchmarkRender(b, r.Render)
+}
+
+func BenchmarkStreamRenderer(b *testing.B) {
+	r := NewStream(ioutil.Discard)
+	benchmarkRender(b, r.Render)
+}
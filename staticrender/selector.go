@@ -0,0 +1,106 @@
+package staticrender
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vugu/html"
+)
+
+// simpleSelector is a compound CSS selector of the form tag#id.class1.class2 (each part is optional).
+// This is what is supported for locating portal targets during static rendering.
+type simpleSelector struct {
+	tag     string
+	id      string
+	classes []string
+}
+
+func parseSimpleSelector(s string) (ret simpleSelector, err error) {
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ret, fmt.Errorf("empty selector")
+	}
+	if strings.ContainsAny(s, " \t\n>+~,[]:*()") {
+		return ret, fmt.Errorf("selector %q is not supported, only tag, #id and .class are", s)
+	}
+
+	// split into parts each starting with the delimiter, the first may have none (tag name)
+	var kind byte
+	start := 0
+	emit := func(end int) error {
+		part := s[start:end]
+		switch kind {
+		case 0:
+			ret.tag = strings.ToLower(part)
+			return nil
+		}
+		if part == "" {
+			return fmt.Errorf("selector %q has an empty %c part", s, kind)
+		}
+		if kind == '#' {
+			ret.id = part
+		} else {
+			ret.classes = append(ret.classes, part)
+		}
+		return nil
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '#' || s[i] == '.' {
+			if err := emit(i); err != nil {
+				return ret, err
+			}
+			kind = s[i]
+			start = i + 1
+		}
+	}
+	err = emit(len(s))
+	return ret, err
+}
+
+func (sel simpleSelector) match(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if sel.tag != "" && strings.ToLower(n.Data) != sel.tag {
+		return false
+	}
+	if sel.id != "" && attrVal(n, "id") != sel.id {
+		return false
+	}
+	if len(sel.classes) > 0 {
+		classes := strings.Fields(attrVal(n, "class"))
+	classLoop:
+		for _, c := range sel.classes {
+			for _, nc := range classes {
+				if nc == c {
+					continue classLoop
+				}
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// find returns the first node in document order under (and including) n which matches, or nil.
+func (sel simpleSelector) find(n *html.Node) *html.Node {
+	if sel.match(n) {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if ret := sel.find(c); ret != nil {
+			return ret
+		}
+	}
+	return nil
+}
+
+func attrVal(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package staticrender
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vugu/html"
+)
+
+func TestSimpleSelector(t *testing.T) {
+
+	doc, err := html.Parse(strings.NewReader(`<div id="a" class="x y"><p class="y">one</p><section id="b"></section></div>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		sel   string
+		found string // tag name of first match, or empty for none
+	}{
+		{"#a", "div"},
+		{"div#a.x.y", "div"},
+		{".y", "div"},
+		{"p.y", "p"},
+		{"#b", "section"},
+		{"section.x", ""},
+		{"#nope", ""},
+	} {
+		sel, err := parseSimpleSelector(tc.sel)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.sel, err)
+			continue
+		}
+		n := sel.find(doc)
+		found := ""
+		if n != nil {
+			found = n.Data
+		}
+		if found != tc.found {
+			t.Errorf("%q: expected %q but found %q", tc.sel, tc.found, found)
+		}
+	}
+
+	for _, bad := range []string{"", "div p", "#", "a[href]", "div > p"} {
+		if _, err := parseSimpleSelector(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+
+}
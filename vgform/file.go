@@ -0,0 +1,224 @@
+package vgform
+
+import (
+	"errors"
+	"time"
+
+	"github.com/vugu/vugu"
+	js "github.com/vugu/vugu/js"
+)
+
+// File wraps an input element with type="file", inside a div which files can also be dropped on.
+// The div has the class "vgform-file", and also "vgform-file-dragover" while files are dragged
+// over it; DefaultSlot is shown in it, e.g. "Drop files here".
+//
+// When files are chosen or dropped, Files is set to them and FilesChange is called, with their names,
+// sizes and types.  If ReadData is true their contents are then read into their Data
+// asynchronously, and FileProgress is called as that happens.  The multiple attribute allows more than one
+// file, and accept limits which files can be chosen (but not which can be dropped).
+//
+//	<vgform:File multiple accept="image/*" :ReadData='true' :Files='&c.Images' @FileProgress='c.showProgress(event)'>
+//		Drop images here
+//	</vgform:File>
+type File struct {
+	Files        *[]*SelectedFile // if set, receives the selected files
+	ReadData     bool             // read the contents of the files into their Data
+	FilesChange  FilesChangeFunc  // called when files are chosen or dropped, set with @FilesChange
+	FileProgress FileProgressFunc // called as the contents of a file are read, and when that is done or failed, set with @FileProgress
+
+	AttrMap     vugu.AttrMap // regular HTML attributes for the input element, like id, multiple and accept
+	ZoneAttrMap vugu.AttrMap // HTML attributes for the div around it, which files can be dropped on
+	DefaultSlot vugu.Builder // shown in the div
+
+	dragover bool
+	gen      int // incremented for each selection, so reading files of an earlier one does not report progress
+}
+
+// SelectedFile is a file chosen with a File input or dropped on it.
+type SelectedFile struct {
+	Name         string
+	Size         int64
+	Type         string // the MIME type as guessed by the browser, empty if it is not known
+	LastModified time.Time
+
+	Data   []byte // the contents, when Done if File.ReadData is true
+	Loaded int64  // how many bytes were read so far
+	Done   bool   // reading the contents succeeded or failed
+	Err    error  // why reading the contents failed
+
+	JSValue js.Value // the File object in the browser, e.g. to upload it with fetch
+}
+
+// Progress returns the part of the file which was read so far, from 0 to 1.
+func (f *SelectedFile) Progress() float64 {
+	if f.Size == 0 {
+		if f.Done {
+			return 1
+		}
+		return 0
+	}
+	return float64(f.Loaded) / float64(f.Size)
+}
+
+// FilesChangeEvent is passed to File.FilesChange.
+type FilesChangeEvent struct {
+	Files []*SelectedFile
+}
+
+// FilesChangeFunc is the type of File.FilesChange.
+type FilesChangeFunc func(event FilesChangeEvent)
+
+// FileProgressEvent is passed to File.FileProgress.
+type FileProgressEvent struct {
+	File *SelectedFile
+}
+
+// FileProgressFunc is the type of File.FileProgress.
+type FileProgressFunc func(event FileProgressEvent)
+
+// zoneAttrs returns the attributes of the div around the input
+func (c *File) zoneAttrs() vugu.AttrMap {
+	ret := make(vugu.AttrMap, len(c.ZoneAttrMap)+1)
+	for k, v := range c.ZoneAttrMap {
+		ret[k] = v
+	}
+	class := "vgform-file"
+	if c.dragover {
+		class += " vgform-file-dragover"
+	}
+	if s, ok := attrString(c.ZoneAttrMap, "class"); ok && s != "" {
+		class += " " + s
+	}
+	ret["class"] = class
+	return ret
+}
+
+// attrs returns the attributes of the input element
+func (c *File) attrs() vugu.AttrMap {
+	ret := make(vugu.AttrMap, len(c.AttrMap)+1)
+	for k, v := range c.AttrMap {
+		ret[k] = v
+	}
+	ret["type"] = "file"
+	return ret
+}
+
+func (c *File) handleChange(event vugu.DOMEvent) {
+	c.setFiles(event.JSEventTarget().Get("files"), event.EventEnv())
+}
+
+func (c *File) handleDragOver(event vugu.DOMEvent) {
+	// dropping is only allowed if dragover is cancelled
+	event.PreventDefault()
+	c.dragover = true
+}
+
+func (c *File) handleDragLeave(event vugu.DOMEvent) {
+	c.dragover = false
+}
+
+func (c *File) handleDrop(event vugu.DOMEvent) {
+	// otherwise the browser opens the file
+	event.PreventDefault()
+	c.dragover = false
+	c.setFiles(event.JSEvent().Get("dataTransfer").Get("files"), event.EventEnv())
+}
+
+// setFiles sets the selected files from a FileList, and starts reading them if ReadData is set.
+func (c *File) setFiles(list js.Value, ee vugu.EventEnv) {
+
+	if list.IsUndefined() || list.IsNull() {
+		return
+	}
+
+	n := list.Length()
+	if n > 1 && !hasAttr(c.AttrMap, "multiple") {
+		n = 1
+	}
+
+	c.gen++
+	files := make([]*SelectedFile, 0, n)
+	for i := 0; i < n; i++ {
+		f := list.Index(i)
+		files = append(files, &SelectedFile{
+			Name:         f.Get("name").String(),
+			Size:         int64(f.Get("size").Float()),
+			Type:         f.Get("type").String(),
+			LastModified: time.Unix(0, int64(f.Get("lastModified").Float())*int64(time.Millisecond)),
+			JSValue:      f,
+		})
+	}
+
+	if c.Files != nil {
+		*c.Files = files
+	}
+	if c.FilesChange != nil {
+		c.FilesChange(FilesChangeEvent{Files: files})
+	}
+
+	if c.ReadData {
+		for _, sf := range files {
+			c.read(sf, c.gen, ee)
+		}
+	}
+}
+
+// read reads the contents of sf with a FileReader.  The callbacks of the FileReader update sf in
+// a goroutine with the lock of ee, which is held by the renderer while they run.
+func (c *File) read(sf *SelectedFile, gen int, ee vugu.EventEnv) {
+
+	reader := js.Global().Get("FileReader").New()
+	var onProgress, onLoad, onError js.Func
+
+	update := func(f func()) {
+		go func() {
+			ee.Lock()
+			f()
+			if c.gen == gen && c.FileProgress != nil {
+				c.FileProgress(FileProgressEvent{File: sf})
+			}
+			ee.UnlockRender()
+		}()
+	}
+
+	onProgress = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		loaded := int64(args[0].Get("loaded").Float())
+		update(func() { sf.Loaded = loaded })
+		return nil
+	})
+
+	onLoad = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		buf := js.Global().Get("Uint8Array").New(reader.Get("result"))
+		data := make([]byte, buf.Length())
+		js.CopyBytesToGo(data, buf)
+		onProgress.Release()
+		onLoad.Release()
+		onError.Release()
+		update(func() {
+			sf.Data = data
+			sf.Loaded = int64(len(data))
+			sf.Done = true
+		})
+		return nil
+	})
+
+	onError = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		msg := "error reading file"
+		if e := reader.Get("error"); !e.IsUndefined() && !e.IsNull() {
+			msg = e.Get("message").String()
+		}
+		onProgress.Release()
+		onLoad.Release()
+		onError.Release()
+		update(func() {
+			sf.Err = errors.New(msg)
+			sf.Done = true
+		})
+		return nil
+	})
+
+	reader.Call("addEventListener", "progress", onProgress)
+	reader.Call("addEventListener", "load", onLoad)
+	reader.Call("addEventListener", "error", onError)
+	reader.Call("readAsArrayBuffer", sf.JSValue)
+}
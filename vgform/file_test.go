@@ -0,0 +1,56 @@
+package vgform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vugu/vugu"
+)
+
+func TestSelectedFileProgress(t *testing.T) {
+
+	cases := []struct {
+		size, loaded int64
+		done         bool
+		want         float64
+	}{
+		{0, 0, false, 0},
+		{0, 0, true, 1}, // an empty file is read completely at once
+		{100, 0, false, 0},
+		{100, 25, false, 0.25},
+		{100, 100, true, 1},
+		{100, 40, true, 0.4}, // failed part way
+	}
+
+	for _, tc := range cases {
+		f := &SelectedFile{Size: tc.size, Loaded: tc.loaded, Done: tc.done}
+		assert.Equal(t, tc.want, f.Progress(), "%+v", tc)
+	}
+}
+
+func TestFileAttrs(t *testing.T) {
+
+	cases := []struct {
+		zone     vugu.AttrMap
+		dragover bool
+		want     vugu.AttrMap
+	}{
+		{nil, false, vugu.AttrMap{"class": "vgform-file"}},
+		{nil, true, vugu.AttrMap{"class": "vgform-file vgform-file-dragover"}},
+		{vugu.AttrMap{"class": "zone", "id": "z"}, false, vugu.AttrMap{"class": "vgform-file zone", "id": "z"}},
+		{vugu.AttrMap{"class": "zone big"}, true, vugu.AttrMap{"class": "vgform-file vgform-file-dragover zone big"}},
+		{vugu.AttrMap{"class": ""}, false, vugu.AttrMap{"class": "vgform-file"}},
+		{vugu.AttrMap{"title": "Drop here"}, false, vugu.AttrMap{"class": "vgform-file", "title": "Drop here"}},
+	}
+
+	for _, tc := range cases {
+		c := &File{ZoneAttrMap: tc.zone, dragover: tc.dragover}
+		assert.Equal(t, tc.want, c.zoneAttrs(), "%v %v", tc.zone, tc.dragover)
+	}
+
+	// the input element always has type="file", whatever else is set
+	c := &File{AttrMap: vugu.AttrMap{"id": "upload", "multiple": true, "type": "text"}}
+	assert.Equal(t, vugu.AttrMap{"id": "upload", "multiple": true, "type": "file"}, c.attrs())
+	assert.Equal(t, "text", c.AttrMap["type"], "AttrMap must not be modified")
+	assert.Equal(t, vugu.AttrMap{"type": "file"}, (&File{}).attrs())
+}
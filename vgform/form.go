@@ -0,0 +1,240 @@
+package vgform
+
+import (
+	"errors"
+
+	"github.com/vugu/vugu"
+)
+
+// Form wraps an HTML form element and validates the controls in it.  Input, Select, Textarea and the
+// typed inputs inside a Form register with it, by the name attribute (or else the id), and their Rules
+// are checked when their value changes and when the form is submitted.  The results are kept in State,
+// which is usually a field of the component with the form so its template can show the errors:
+//
+//	<vgform:Form :State='&c.Signup' @Submit='c.save()'>
+//		<vgform:Input name="email" required :Value='vgform.StringPtr{&c.Email}'
+//			:Rules='vgform.Rules(vgform.Pattern(`[^@]+@[^@]+`))'></vgform:Input>
+//		<span class="error" vg-content='c.Signup.Message("email")'></span>
+//		<button type="submit">Sign up</button>
+//	</vgform:Form>
+//
+// Submitting the form does not send it to the server, Submit is called instead if all controls
+// are valid.  The form element gets the novalidate attribute, so the browser leaves the
+// validation to the Form.
+type Form struct {
+	State       *FormState                   // the validation state, must not be nil
+	Validate    func(state *FormState) error // optional form-level check, e.g. that two fields match
+	Submit      SubmitFunc                   // called when the form is submitted and valid
+	AttrMap     vugu.AttrMap                 // regular HTML attributes like id and class
+	DefaultSlot vugu.Builder                 // the contents of the form
+}
+
+// SubmitEvent is passed to Form.Submit.
+type SubmitEvent struct {
+	State    *FormState
+	DOMEvent vugu.DOMEvent
+}
+
+// SubmitFunc is the type of Form.Submit.
+type SubmitFunc func(event SubmitEvent)
+
+// formStateKey is the key of the FormState of the enclosing Form in the BuildIn context values
+type formStateKey struct{}
+
+// attrs provides State to the controls in the form and returns the attributes for the form element.
+func (c *Form) attrs(vgin *vugu.BuildIn) vugu.AttrMap {
+
+	if c.State == nil {
+		panic(errors.New("Form.State must not be nil"))
+	}
+	c.State.validate = c.Validate
+	c.State.gen++ // the controls register again while they are built
+	vgin.SetContextValue(formStateKey{}, c.State)
+
+	ret := make(vugu.AttrMap, len(c.AttrMap)+1)
+	ret["novalidate"] = true
+	for k, v := range c.AttrMap {
+		ret[k] = v
+	}
+	return ret
+}
+
+func (c *Form) handleSubmit(event vugu.DOMEvent) {
+	event.PreventDefault()
+	c.State.Submitted = true
+	if c.State.Check() && c.Submit != nil {
+		c.Submit(SubmitEvent{State: c.State, DOMEvent: event})
+	}
+}
+
+// handleFocusOut marks the control losing focus as touched, focusout bubbles up from the controls
+func (c *Form) handleFocusOut(event vugu.DOMEvent) {
+	f := c.State.Field(event.PropString("target", "name"))
+	if f == nil {
+		f = c.State.Field(event.PropString("target", "id"))
+	}
+	if f != nil {
+		f.Touched = true
+	}
+}
+
+// FormState is the validation state of a Form: the state of each of its controls and the form-level error.
+// The zero value is ready to use.
+type FormState struct {
+	Submitted bool  // a submit was attempted
+	Err       error // from Form.Validate
+
+	fields   map[string]*FieldState
+	gen      int // incremented for each build of the Form
+	validate func(state *FormState) error
+}
+
+// FieldState is the validation state of a control in a Form.
+type FieldState struct {
+	Name    string
+	Err     error // the error of the value, nil if it is valid
+	Touched bool  // the control had focus and lost it
+	Dirty   bool  // the value was changed by the user
+
+	form  *FormState
+	check func() error
+	gen   int // the build of the Form the control was last in
+}
+
+// Field returns the state of the control with name, or nil if there is no such control in the form.
+func (s *FormState) Field(name string) *FieldState {
+	f := s.fields[name]
+	if f == nil || f.gen != s.gen || name == "" {
+		return nil
+	}
+	return f
+}
+
+// FieldErr returns the error of the control with name, nil if it is valid or not in the form.
+func (s *FormState) FieldErr(name string) error {
+	if f := s.Field(name); f != nil {
+		return f.Err
+	}
+	return nil
+}
+
+// Message returns the text of the error of the control with name if it should be shown, i.e. if
+// the control was touched or changed or the form was submitted, or else an empty string.
+func (s *FormState) Message(name string) string {
+	f := s.Field(name)
+	if f == nil || f.Err == nil || !f.shown() {
+		return ""
+	}
+	return f.Err.Error()
+}
+
+// Valid returns true if none of the controls in the form nor the form itself has an error.
+// Controls which were not changed are only checked by Check.
+func (s *FormState) Valid() bool {
+	if s.Err != nil {
+		return false
+	}
+	for _, f := range s.fields {
+		if f.gen == s.gen && f.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Check checks all controls in the form and the form itself and returns Valid.
+func (s *FormState) Check() bool {
+	for _, f := range s.fields {
+		if f.gen == s.gen {
+			f.Err = f.check()
+		}
+	}
+	s.checkForm()
+	return s.Valid()
+}
+
+// Reset clears the errors and the touched, dirty and submitted state, e.g. after a form was saved.
+func (s *FormState) Reset() {
+	s.Submitted = false
+	s.Err = nil
+	for _, f := range s.fields {
+		f.Err, f.Touched, f.Dirty = nil, false, false
+	}
+}
+
+// checkForm runs the form-level check
+func (s *FormState) checkForm() {
+	s.Err = nil
+	if s.validate != nil {
+		s.Err = s.validate(s)
+	}
+}
+
+// shown returns true if the error of the field should be shown
+func (f *FieldState) shown() bool {
+	return f.Touched || f.Dirty || f.form.Submitted
+}
+
+// formField is embedded in the controls to take part in the validation of an enclosing Form.
+type formField struct {
+	field *FieldState // nil if the control is not in a Form or has no name
+}
+
+// register adds the control to the state of the enclosing Form, if there is one, by the name or
+// id in attrs.  check returns the error of the value of the control.
+func (ff *formField) register(vgin *vugu.BuildIn, attrs vugu.AttrMap, check func() error) {
+
+	ff.field = nil
+	s, _ := vgin.ContextValue(formStateKey{}).(*FormState)
+	if s == nil {
+		return
+	}
+	name, _ := attrString(attrs, "name")
+	if name == "" {
+		name, _ = attrString(attrs, "id")
+	}
+	if name == "" {
+		return
+	}
+
+	if s.fields == nil {
+		s.fields = make(map[string]*FieldState)
+	}
+	f := s.fields[name]
+	if f == nil {
+		f = &FieldState{Name: name, form: s}
+		s.fields[name] = f
+	}
+	f.check = check
+	f.gen = s.gen
+	ff.field = f
+}
+
+// changed is called when the user changed the value of the control, it is checked again
+func (ff *formField) changed() {
+	f := ff.field
+	if f == nil {
+		return
+	}
+	f.Dirty = true
+	f.Err = f.check()
+	f.form.checkForm()
+}
+
+// invalid returns true if the control has an error which should be shown
+func (ff *formField) invalid() bool {
+	return ff.field != nil && ff.field.Err != nil && ff.field.shown()
+}
+
+// fieldAttrs returns the attributes in m, with aria-invalid added if the control has an error which should be shown
+func (ff *formField) fieldAttrs(m vugu.AttrMap) vugu.AttrMap {
+	if !ff.invalid() {
+		return m
+	}
+	ret := make(vugu.AttrMap, len(m)+1)
+	for k, v := range m {
+		ret[k] = v
+	}
+	ret["aria-invalid"] = "true"
+	return ret
+}
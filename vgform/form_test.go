@@ -0,0 +1,102 @@
+package vgform
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vugu/vugu"
+)
+
+// formControl is implemented by the controls which take part in a Form
+type formControl interface {
+	attrs(vgin *vugu.BuildIn) vugu.AttrMap
+}
+
+func TestFormState(t *testing.T) {
+
+	assert := assert.New(t)
+
+	var state FormState
+	var email, password, password2 string
+	var age int
+	form := &Form{State: &state, Validate: func(s *FormState) error {
+		if password != password2 {
+			return errors.New("passwords do not match")
+		}
+		return nil
+	}}
+	emailInput := &Input{Value: StringPtr{&email}, AttrMap: vugu.AttrMap{"name": "email", "required": true},
+		Rules: Rules(Pattern(`[^@]+@[^@]+`))}
+	passwordInput := &Input{Value: StringPtr{&password}, AttrMap: vugu.AttrMap{"id": "password", "type": "password"},
+		Rules: Rules(Length(8, 0))}
+	password2Input := &Input{Value: StringPtr{&password2}, AttrMap: vugu.AttrMap{"id": "password2", "type": "password"}}
+	ageInput := &InputInt{Value: IntPtr{&age}, AttrMap: vugu.AttrMap{"name": "age", "min": "18"}}
+	noName := &Input{Value: StringPtr{&email}}
+
+	// the controls register with the form while it is built
+	build := func(controls ...formControl) {
+		vgin := &vugu.BuildIn{}
+		attrs := form.attrs(vgin)
+		assert.Equal(true, attrs["novalidate"])
+		for _, c := range controls {
+			c.attrs(vgin)
+		}
+	}
+	build(emailInput, passwordInput, password2Input, ageInput, noName)
+
+	assert.NotNil(state.Field("email"))
+	assert.NotNil(state.Field("password")) // by id, as it has no name
+	assert.Nil(state.Field(""))
+	assert.Nil(state.Field("other"))
+
+	// nothing was checked yet
+	assert.True(state.Valid())
+	assert.Equal("", state.Message("email"))
+
+	assert.False(state.Check())
+	assert.EqualError(state.FieldErr("email"), "a value is required")
+	assert.NoError(state.FieldErr("password")) // empty values are left to required
+	assert.Equal("", state.Message("email"))   // not touched, changed or submitted
+	state.Field("email").Touched = true
+	assert.Equal("a value is required", state.Message("email"))
+
+	// a change checks the control and the form
+	password = "secret"
+	passwordInput.changed()
+	assert.True(state.Field("password").Dirty)
+	assert.Equal("must have at least 8 characters", state.Message("password"))
+	assert.EqualError(state.Err, "passwords do not match")
+	assert.Equal("true", passwordInput.fieldAttrs(nil)["aria-invalid"])
+
+	email, password, password2 = "joe@example.com", "secret123", "secret123"
+	age = 17
+	assert.False(state.Check())
+	assert.EqualError(state.FieldErr("age"), "must be at least 18") // the min attribute applies to values set by the program too
+	assert.Nil(state.Err)
+	age = 18
+	assert.True(state.Check())
+
+	// controls which are not built again are no longer part of the form
+	email = ""
+	build(passwordInput, password2Input, ageInput)
+	assert.Nil(state.Field("email"))
+	assert.True(state.Check())
+
+	state.Submitted = true
+	state.Reset()
+	assert.False(state.Submitted)
+	assert.False(state.Field("password").Dirty)
+	assert.Nil(state.FieldErr("age"))
+
+	assert.Panics(func() { (&Form{}).attrs(&vugu.BuildIn{}) })
+}
+
+func TestFieldNotInForm(t *testing.T) {
+	var s string
+	c := &Input{Value: StringPtr{&s}, AttrMap: vugu.AttrMap{"name": "x", "required": true}}
+	attrs := c.attrs(&vugu.BuildIn{})
+	assert.Nil(t, c.field)
+	assert.Nil(t, attrs["aria-invalid"])
+	c.changed() // no Form, nothing to do
+}
@@ -0,0 +1,61 @@
+package vgform
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/vugu/vugu"
+)
+
+// InputColor is an input element for a color, with type="color".  The browser only provides
+// valid colors, the values are like "#ff8000".
+type InputColor struct {
+	Value   ColorValuer  // get/set the color
+	Err     *error       // if set, receives the validation error after each change, nil if the value is valid
+	Rules   []Validator  // checked when in a Form, after the text entered was parsed
+	AttrMap vugu.AttrMap // regular HTML attributes like id and class
+
+	typedInput
+}
+
+// attrs registers with an enclosing Form and returns the attributes for the element
+func (c *InputColor) attrs(vgin *vugu.BuildIn) vugu.AttrMap {
+	c.register(vgin, c.AttrMap, c.check)
+	return c.inputAttrs(c.AttrMap, "type", "color")
+}
+
+// check returns the error of the value for the Form
+func (c *InputColor) check() error {
+	return c.typedInput.check(c.AttrMap, c.Rules, func() (interface{}, error) {
+		_, err := c.parse(c.format())
+		return c.Value.ColorValue(), err
+	})
+}
+
+func (c *InputColor) format() string {
+	if c.Value == nil {
+		panic(errors.New("InputColor.Value must not be nil"))
+	}
+	v := c.Value.ColorValue()
+	return fmt.Sprintf("#%02x%02x%02x", v.R, v.G, v.B)
+}
+
+func (c *InputColor) parse(text string) (color.RGBA, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(text, "#"))
+	if err != nil || len(b) != 3 || !strings.HasPrefix(text, "#") {
+		return color.RGBA{}, fmt.Errorf("%q is not a valid color", text)
+	}
+	return color.RGBA{R: b[0], G: b[1], B: b[2], A: 0xff}, nil
+}
+
+func (c *InputColor) handleChange(event vugu.DOMEvent) {
+	text := strings.TrimSpace(event.PropString("target", "value"))
+	v, err := c.parse(text)
+	if err == nil {
+		c.Value.SetColorValue(v)
+	}
+	c.setResult(text, err, c.Err)
+}
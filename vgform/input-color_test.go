@@ -0,0 +1,42 @@
+package vgform
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInputColor(t *testing.T) {
+
+	c := &InputColor{}
+
+	cases := []struct {
+		in   string
+		want color.RGBA
+		ok   bool
+	}{
+		{"#ff8000", color.RGBA{R: 0xff, G: 0x80, B: 0x00, A: 0xff}, true},
+		{"#FF8000", color.RGBA{R: 0xff, G: 0x80, B: 0x00, A: 0xff}, true},
+		{"#000000", color.RGBA{A: 0xff}, true},
+		{"ff8000", color.RGBA{}, false},
+		{"#f80", color.RGBA{}, false},
+		{"#ff800080", color.RGBA{}, false},
+		{"#gg8000", color.RGBA{}, false},
+		{"", color.RGBA{}, false},
+	}
+
+	for _, tc := range cases {
+		got, err := c.parse(tc.in)
+		if !tc.ok {
+			assert.Error(t, err, tc.in)
+			continue
+		}
+		assert.NoError(t, err, tc.in)
+		assert.Equal(t, tc.want, got, tc.in)
+	}
+
+	v := color.RGBA{R: 1, G: 0xab, B: 0xff, A: 0x80}
+	c.Value = ColorPtr{&v}
+	assert.Equal(t, "#01abff", c.format())
+}
@@ -0,0 +1,125 @@
+package vgform
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/vugu/vugu"
+)
+
+// InputFloat is an input element for a float64.  The type attribute defaults to "number", "range" works as well.
+// The min, max and step attributes are checked when the value changes; step defaults to "any", i.e.
+// no step.  See typedInput for what happens when the text entered is not valid.
+type InputFloat struct {
+	Value   FloatValuer  // get/set the number
+	Err     *error       // if set, receives the validation error after each change, nil if the value is valid
+	Rules   []Validator  // checked when in a Form, after the text entered was parsed
+	AttrMap vugu.AttrMap // regular HTML attributes like id, class, min, max and step
+
+	typedInput
+}
+
+// attrs registers with an enclosing Form and returns the attributes for the element
+func (c *InputFloat) attrs(vgin *vugu.BuildIn) vugu.AttrMap {
+	c.register(vgin, c.AttrMap, c.check)
+	return c.inputAttrs(c.AttrMap, "type", "number", "step", "any")
+}
+
+// check returns the error of the value for the Form
+func (c *InputFloat) check() error {
+	return c.typedInput.check(c.AttrMap, c.Rules, func() (interface{}, error) {
+		_, err := c.parse(c.format())
+		return c.Value.FloatValue(), err
+	})
+}
+
+func (c *InputFloat) format() string {
+	if c.Value == nil {
+		panic(errors.New("InputFloat.Value must not be nil"))
+	}
+	return formatFloat(c.Value.FloatValue())
+}
+
+func (c *InputFloat) parse(text string) (float64, error) {
+	if text == "" {
+		if hasAttr(c.AttrMap, "required") {
+			return 0, errRequired
+		}
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("%q is not a number", text)
+	}
+	return v, checkNumber(c.AttrMap, v, 0)
+}
+
+func (c *InputFloat) handleChange(event vugu.DOMEvent) {
+	text := strings.TrimSpace(event.PropString("target", "value"))
+	if text == "" && badInput(event) {
+		c.setBadInput(errors.New("not a valid number"), c.Err)
+		return
+	}
+	v, err := c.parse(text)
+	if err == nil {
+		c.Value.SetFloatValue(v)
+	}
+	c.setResult(text, err, c.Err)
+}
+
+// checkNumber checks v against the min, max and step attributes in m, like the browser does.
+// defStep is the step if there is no step attribute, 0 for any.  Attributes which are not numbers are ignored.
+func checkNumber(m vugu.AttrMap, v float64, defStep float64) error {
+
+	min, hasMin := attrFloat(m, "min")
+	if hasMin && v < min {
+		return fmt.Errorf("must be at least %s", formatFloat(min))
+	}
+	if max, ok := attrFloat(m, "max"); ok && v > max {
+		return fmt.Errorf("must be at most %s", formatFloat(max))
+	}
+
+	step := defStep
+	if s, ok := attrString(m, "step"); ok && strings.EqualFold(s, "any") {
+		step = 0
+	} else if f, ok := attrFloat(m, "step"); ok && f > 0 {
+		step = f
+	}
+	if step > 0 {
+		// steps are counted from min if there is one
+		base := 0.0
+		if hasMin {
+			base = min
+		}
+		n := (v - base) / step
+		if math.Abs(n-math.Round(n)) > 1e-9 {
+			if base != 0 {
+				return fmt.Errorf("must be %s plus a multiple of %s", formatFloat(base), formatFloat(step))
+			}
+			return fmt.Errorf("must be a multiple of %s", formatFloat(step))
+		}
+	}
+
+	return nil
+}
+
+// attrFloat returns the attribute key of m as a number, if it is set to one.
+func attrFloat(m vugu.AttrMap, key string) (float64, bool) {
+	s, ok := attrString(m, key)
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+// formatFloat formats f with as few digits as needed, the way it is parsed by the browser
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,75 @@
+package vgform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vugu/vugu"
+)
+
+func TestCheckNumber(t *testing.T) {
+
+	cases := []struct {
+		attrs   vugu.AttrMap
+		v       float64
+		defStep float64
+		err     string // empty for no error
+	}{
+		{nil, 3, 1, ""},
+		{nil, 3.5, 1, "must be a multiple of 1"},
+		{nil, 3.5, 0, ""},
+		{vugu.AttrMap{"min": "1", "max": "10"}, 1, 1, ""},
+		{vugu.AttrMap{"min": "1", "max": "10"}, 10, 1, ""},
+		{vugu.AttrMap{"min": "1", "max": "10"}, 0, 1, "must be at least 1"},
+		{vugu.AttrMap{"min": "1", "max": "10"}, 11, 1, "must be at most 10"},
+		{vugu.AttrMap{"min": 1.5, "max": 2.5}, 3, 0, "must be at most 2.5"}, // non-string attributes
+		{vugu.AttrMap{"min": "x", "max": ""}, -5, 0, ""},                    // attributes which are not numbers are ignored
+		// steps are counted from min
+		{vugu.AttrMap{"step": "5"}, 15, 1, ""},
+		{vugu.AttrMap{"step": "5"}, 16, 1, "must be a multiple of 5"},
+		{vugu.AttrMap{"min": "1", "step": "5"}, 16, 1, ""},
+		{vugu.AttrMap{"min": "1", "step": "5"}, 15, 1, "must be 1 plus a multiple of 5"},
+		{vugu.AttrMap{"step": "0.1"}, 0.3, 0, ""}, // not exact in binary floating point
+		{vugu.AttrMap{"step": "0.1"}, 0.35, 0, "must be a multiple of 0.1"},
+		{vugu.AttrMap{"step": "ANY"}, 0.35, 1, ""},
+		{vugu.AttrMap{"step": "-1"}, 0.5, 1, "must be a multiple of 1"}, // an invalid step is the default
+	}
+
+	for _, tc := range cases {
+		err := checkNumber(tc.attrs, tc.v, tc.defStep)
+		if tc.err == "" {
+			assert.NoError(t, err, "%v %v", tc.attrs, tc.v)
+		} else if assert.Error(t, err, "%v %v", tc.attrs, tc.v) {
+			assert.Equal(t, tc.err, err.Error())
+		}
+	}
+}
+
+func TestInputNumberParse(t *testing.T) {
+
+	ii := &InputInt{AttrMap: vugu.AttrMap{"max": "10"}}
+	v, err := ii.parse("7")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, v)
+	_, err = ii.parse("7.5")
+	assert.EqualError(t, err, `"7.5" is not a whole number`)
+	_, err = ii.parse("11")
+	assert.EqualError(t, err, "must be at most 10")
+	v, err = ii.parse("")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, v)
+	ii.AttrMap["required"] = true
+	_, err = ii.parse("")
+	assert.Equal(t, errRequired, err)
+
+	fi := &InputFloat{}
+	f, err := fi.parse("-2.25")
+	assert.NoError(t, err)
+	assert.Equal(t, -2.25, f)
+	for _, s := range []string{"abc", "NaN", "Inf"} {
+		_, err = fi.parse(s)
+		assert.Error(t, err, s)
+	}
+	assert.Equal(t, "0.1", formatFloat(0.1))
+	assert.Equal(t, "100000000", formatFloat(1e8))
+}
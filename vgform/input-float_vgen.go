@@ -0,0 +1,35 @@
+package vgform
+
+// DO NOT EDIT: This file was generated by vugu. Please regenerate instead of editing or add additional code in a separate file.
+
+import "fmt"
+import "reflect"
+import "github.com/vugu/vjson"
+import "github.com/vugu/vugu"
+import js "github.com/vugu/vugu/js"
+
+func (c *InputFloat) Build(vgin *vugu.BuildIn) (vgout *vugu.BuildOut) {
+
+	vgout = &vugu.BuildOut{}
+
+	var vgiterkey interface{}
+	_ = vgiterkey
+	var vgn *vugu.VGNode
+	vgn = &vugu.VGNode{Type: vugu.VGNodeType(3), Namespace: "", Data: "input", Attr: []vugu.VGAttribute(nil)}
+	vgout.Out = append(vgout.Out, vgn)	// root for output
+	vgn.AddAttrInterface("value", c.valueText(c.format))
+	vgn.AddAttrList(c.attrs(vgin))
+	vgn.JSPopulateHandler = vugu.JSValueFunc(func(value js.Value) { c.populate(value, c.valueText(c.format)) })
+	vgn.DOMEventHandlerSpecList = append(vgn.DOMEventHandlerSpecList, vugu.DOMEventHandlerSpec{
+		EventType:	"change",
+		Func:		func(event vugu.DOMEvent) { c.handleChange(event) },
+		// TODO: implement capture, etc. mostly need to decide syntax
+	})
+	return vgout
+}
+
+// 'fix' unused imports
+var _ fmt.Stringer
+var _ reflect.Type
+var _ vjson.RawMessage
+var _ js.Value
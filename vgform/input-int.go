@@ -0,0 +1,70 @@
+package vgform
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vugu/vugu"
+)
+
+// InputInt is an input element for an int.  The type attribute defaults to "number", "range" works as well.
+// The min, max and step attributes (step defaults to 1) are checked when the value changes, see typedInput
+// for what happens when the text entered is not valid.
+type InputInt struct {
+	Value   IntValuer    // get/set the number
+	Err     *error       // if set, receives the validation error after each change, nil if the value is valid
+	Rules   []Validator  // checked when in a Form, after the text entered was parsed
+	AttrMap vugu.AttrMap // regular HTML attributes like id, class, min and max
+
+	typedInput
+}
+
+// attrs registers with an enclosing Form and returns the attributes for the element
+func (c *InputInt) attrs(vgin *vugu.BuildIn) vugu.AttrMap {
+	c.register(vgin, c.AttrMap, c.check)
+	return c.inputAttrs(c.AttrMap, "type", "number")
+}
+
+// check returns the error of the value for the Form
+func (c *InputInt) check() error {
+	return c.typedInput.check(c.AttrMap, c.Rules, func() (interface{}, error) {
+		_, err := c.parse(c.format())
+		return c.Value.IntValue(), err
+	})
+}
+
+func (c *InputInt) format() string {
+	if c.Value == nil {
+		panic(errors.New("InputInt.Value must not be nil"))
+	}
+	return strconv.Itoa(c.Value.IntValue())
+}
+
+func (c *InputInt) parse(text string) (int, error) {
+	if text == "" {
+		if hasAttr(c.AttrMap, "required") {
+			return 0, errRequired
+		}
+		return 0, nil
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a whole number", text)
+	}
+	return v, checkNumber(c.AttrMap, float64(v), 1)
+}
+
+func (c *InputInt) handleChange(event vugu.DOMEvent) {
+	text := strings.TrimSpace(event.PropString("target", "value"))
+	if text == "" && badInput(event) {
+		c.setBadInput(errors.New("not a valid whole number"), c.Err)
+		return
+	}
+	v, err := c.parse(text)
+	if err == nil {
+		c.Value.SetIntValue(v)
+	}
+	c.setResult(text, err, c.Err)
+}
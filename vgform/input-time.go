@@ -0,0 +1,183 @@
+package vgform
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vugu/vugu"
+)
+
+// InputTime is an input element for a time.Time.  The type attribute selects what is entered:
+// "date" (the default), "time", "datetime-local", "month" or "week".  Times are in the location
+// of the current value, or in the local time zone if it is the zero time, and for type="time" the
+// date of the current value is kept.  An empty input is the zero time.  The min and max attributes,
+// in the format of the type, are checked when the value changes; see typedInput for what happens
+// when the text entered is not valid.
+type InputTime struct {
+	Value   TimeValuer   // get/set the time
+	Err     *error       // if set, receives the validation error after each change, nil if the value is valid
+	Rules   []Validator  // checked when in a Form, after the text entered was parsed
+	AttrMap vugu.AttrMap // regular HTML attributes like id, class, min and max
+
+	typedInput
+}
+
+// attrs registers with an enclosing Form and returns the attributes for the element
+func (c *InputTime) attrs(vgin *vugu.BuildIn) vugu.AttrMap {
+	c.register(vgin, c.AttrMap, c.check)
+	return c.inputAttrs(c.AttrMap, "type", "date")
+}
+
+// check returns the error of the value for the Form
+func (c *InputTime) check() error {
+	return c.typedInput.check(c.AttrMap, c.Rules, func() (interface{}, error) {
+		_, err := c.parse(c.format())
+		return c.Value.TimeValue(), err
+	})
+}
+
+// timeLayouts are the layouts of the values of the input types handled by InputTime, the first one
+// is used for formatting.  Fractional seconds are accepted after seconds by time.Parse.
+// The values of week inputs, like "2020-W05", are handled by formatWeek and parseWeek.
+var timeLayouts = map[string][]string{
+	"date":           {"2006-01-02"},
+	"time":           {"15:04", "15:04:05"},
+	"datetime-local": {"2006-01-02T15:04", "2006-01-02T15:04:05"},
+	"month":          {"2006-01"},
+}
+
+func (c *InputTime) typ() string {
+	typ := inputType(c.AttrMap, "date")
+	if _, ok := timeLayouts[typ]; !ok && typ != "week" {
+		panic(fmt.Errorf("InputTime does not support type=%q", typ))
+	}
+	return typ
+}
+
+func (c *InputTime) format() string {
+	if c.Value == nil {
+		panic(errors.New("InputTime.Value must not be nil"))
+	}
+	t := c.Value.TimeValue()
+	if t.IsZero() {
+		return ""
+	}
+	return formatTime(c.typ(), t)
+}
+
+func (c *InputTime) parse(text string) (time.Time, error) {
+
+	typ := c.typ()
+	cur := c.Value.TimeValue()
+	loc := time.Local
+	if !cur.IsZero() {
+		loc = cur.Location()
+	}
+
+	if text == "" {
+		if hasAttr(c.AttrMap, "required") {
+			return time.Time{}, errRequired
+		}
+		return time.Time{}, nil
+	}
+
+	t, err := parseTime(typ, text, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a valid %s", text, typ)
+	}
+
+	if s, ok := attrString(c.AttrMap, "min"); ok {
+		if min, err := parseTime(typ, s, loc); err == nil && t.Before(min) {
+			return time.Time{}, fmt.Errorf("must not be before %s", s)
+		}
+	}
+	if s, ok := attrString(c.AttrMap, "max"); ok {
+		if max, err := parseTime(typ, s, loc); err == nil && t.After(max) {
+			return time.Time{}, fmt.Errorf("must not be after %s", s)
+		}
+	}
+
+	if typ == "time" && !cur.IsZero() {
+		y, m, d := cur.Date()
+		t = time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+	}
+
+	return t, nil
+}
+
+func (c *InputTime) handleChange(event vugu.DOMEvent) {
+	text := strings.TrimSpace(event.PropString("target", "value"))
+	if text == "" && badInput(event) {
+		c.setBadInput(fmt.Errorf("not a valid %s", c.typ()), c.Err)
+		return
+	}
+	v, err := c.parse(text)
+	if err == nil {
+		c.Value.SetTimeValue(v)
+	}
+	c.setResult(text, err, c.Err)
+}
+
+// formatTime formats t as the value of an input of type typ
+func formatTime(typ string, t time.Time) string {
+	if typ == "week" {
+		return formatWeek(t)
+	}
+	layout := timeLayouts[typ][0]
+	if typ == "time" || typ == "datetime-local" {
+		switch {
+		case t.Nanosecond() != 0:
+			layout += ":05.999"
+		case t.Second() != 0:
+			layout += ":05"
+		}
+	}
+	return t.Format(layout)
+}
+
+// parseTime parses the value of an input of type typ
+func parseTime(typ, s string, loc *time.Location) (t time.Time, err error) {
+	if typ == "week" {
+		return parseWeek(s, loc)
+	}
+	for _, layout := range timeLayouts[typ] {
+		t, err = time.ParseInLocation(layout, s, loc)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return t, err
+}
+
+// formatWeek formats the ISO 8601 week of t, like "2020-W05"
+func formatWeek(t time.Time) string {
+	y, w := t.ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", y, w)
+}
+
+// parseWeek parses an ISO 8601 week, like "2020-W05", and returns the start of its Monday
+func parseWeek(s string, loc *time.Location) (time.Time, error) {
+
+	if len(s) != 8 || s[4:6] != "-W" {
+		return time.Time{}, fmt.Errorf("invalid week %q", s)
+	}
+	y, err1 := strconv.Atoi(s[:4])
+	w, err2 := strconv.Atoi(s[6:])
+	if err1 != nil || err2 != nil || w < 1 {
+		return time.Time{}, fmt.Errorf("invalid week %q", s)
+	}
+
+	// January 4th is always in week 1
+	jan4 := time.Date(y, time.January, 4, 0, 0, 0, 0, loc)
+	t := jan4.AddDate(0, 0, -(int(jan4.Weekday())+6)%7+(w-1)*7)
+
+	// week 53 only exists in some years
+	if _, tw := t.ISOWeek(); tw != w {
+		return time.Time{}, fmt.Errorf("invalid week %q", s)
+	}
+
+	return t, nil
+}
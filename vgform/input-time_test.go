@@ -0,0 +1,105 @@
+package vgform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vugu/vugu"
+)
+
+func TestWeek(t *testing.T) {
+
+	cases := []struct {
+		week   string
+		monday string // empty if the week is not valid
+	}{
+		{"2020-W01", "2019-12-30"},
+		{"2020-W05", "2020-01-27"},
+		{"2020-W53", "2020-12-28"}, // 2020 has 53 weeks
+		{"2021-W01", "2021-01-04"},
+		{"2021-W52", "2021-12-27"},
+		{"2021-W53", ""}, // 2021 does not
+		{"2021-W00", ""},
+		{"2021-W1", ""},
+		{"2021W01", ""},
+		{"abcd-W01", ""},
+	}
+
+	for _, tc := range cases {
+		got, err := parseWeek(tc.week, time.UTC)
+		if tc.monday == "" {
+			assert.Error(t, err, tc.week)
+			continue
+		}
+		if assert.NoError(t, err, tc.week) {
+			assert.Equal(t, tc.monday, got.Format("2006-01-02"), tc.week)
+			assert.Equal(t, time.Monday, got.Weekday(), tc.week)
+			assert.Equal(t, tc.week, formatWeek(got), tc.week)
+			assert.Equal(t, tc.week, formatWeek(got.AddDate(0, 0, 6)), tc.week) // the Sunday is in the same week
+		}
+	}
+}
+
+func TestParseFormatTime(t *testing.T) {
+
+	loc := time.FixedZone("test", 3600)
+
+	cases := []struct {
+		typ, in string
+		want    time.Time
+		out     string // as formatted, if different from in
+	}{
+		{"date", "2020-02-29", time.Date(2020, 2, 29, 0, 0, 0, 0, loc), ""},
+		{"time", "13:45", time.Date(0, 1, 1, 13, 45, 0, 0, loc), ""},
+		{"time", "13:45:30", time.Date(0, 1, 1, 13, 45, 30, 0, loc), ""},
+		{"time", "13:45:30.25", time.Date(0, 1, 1, 13, 45, 30, 250000000, loc), ""},
+		{"time", "13:45:00", time.Date(0, 1, 1, 13, 45, 0, 0, loc), "13:45"},
+		{"datetime-local", "2020-02-29T13:45", time.Date(2020, 2, 29, 13, 45, 0, 0, loc), ""},
+		{"datetime-local", "2020-02-29T13:45:01", time.Date(2020, 2, 29, 13, 45, 1, 0, loc), ""},
+		{"month", "2020-02", time.Date(2020, 2, 1, 0, 0, 0, 0, loc), ""},
+		{"week", "2020-W09", time.Date(2020, 2, 24, 0, 0, 0, 0, loc), ""},
+	}
+
+	for _, tc := range cases {
+		got, err := parseTime(tc.typ, tc.in, loc)
+		if assert.NoError(t, err, "%s %s", tc.typ, tc.in) {
+			assert.True(t, tc.want.Equal(got), "%s %s: got %v", tc.typ, tc.in, got)
+			out := tc.out
+			if out == "" {
+				out = tc.in
+			}
+			assert.Equal(t, out, formatTime(tc.typ, got), "%s %s", tc.typ, tc.in)
+		}
+	}
+
+	for _, bad := range [][2]string{{"date", "2020-02-30"}, {"date", "29.02.2020"}, {"time", "25:00"}, {"month", "2020-13"}, {"datetime-local", "2020-02-29"}} {
+		_, err := parseTime(bad[0], bad[1], loc)
+		assert.Error(t, err, "%v", bad)
+	}
+}
+
+func TestInputTimeParse(t *testing.T) {
+
+	cur := time.Date(2020, 3, 4, 10, 0, 0, 0, time.UTC)
+	c := &InputTime{Value: TimePtr{&cur}, AttrMap: vugu.AttrMap{"type": "time", "min": "08:00", "max": "18:00"}}
+
+	// the date of the current value is kept
+	got, err := c.parse("12:30")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 3, 4, 12, 30, 0, 0, time.UTC), got)
+
+	_, err = c.parse("07:59")
+	assert.EqualError(t, err, "must not be before 08:00")
+	_, err = c.parse("18:01")
+	assert.EqualError(t, err, "must not be after 18:00")
+	_, err = c.parse("noon")
+	assert.EqualError(t, err, `"noon" is not a valid time`)
+
+	got, err = c.parse("")
+	assert.NoError(t, err)
+	assert.True(t, got.IsZero())
+
+	c.AttrMap = vugu.AttrMap{"type": "datetime"}
+	assert.Panics(t, func() { c.parse("2020-01-01") })
+}
@@ -1,23 +1,116 @@
 package vgform
 
-import "github.com/vugu/vugu"
+import (
+	"errors"
+
+	"github.com/vugu/vugu"
+)
 
 // Input corresponds to an input HTML element.
 // What you provide for the `type` attribute can trigger behavioral differences appropriate
 // for specific input types.
 //
-// type="checkbox"
-// type="radio"
-// (list them out)
+// type="checkbox" binds the checked state to Checked, or, for a checkbox which is
+// part of a group, adds or removes Option in Values.  E.g.
+// `<vgform:Input type="checkbox" :Checked="vgform.BoolPtr{&c.Agree}"></vgform:Input>` or
+// `<vgform:Input type="checkbox" :Values="vgform.StringSlicePtr{&c.Colors}" Option="red"></vgform:Input>`
+//
+// type="radio" sets Value to Option when selected, all radio inputs in a group share the same Value.  E.g.
+// `<vgform:Input type="radio" name="size" :Value="vgform.StringPtr{&c.Size}" Option="large"></vgform:Input>`
+//
+// Other types bind the text of the input to Value.  For numbers, dates, times and colors see
+// InputInt, InputFloat, InputTime and InputColor, which bind typed values.
 type Input struct {
-	Value   StringValuer // get/set the currently selected value
+	Value   StringValuer      // get/set the current value; for type="radio" the value of the selected input in the group
+	Checked BoolValuer        // for type="checkbox": get/set whether it is checked
+	Values  StringSliceValuer // for type="checkbox" in a group: the set of options which are checked, used instead of Checked
+	Option  string            // for type="radio" and checkboxes in a group: the value of this input
+	Rules   []Validator       // checked when in a Form, see Form and Validator for the type of the value
 	AttrMap vugu.AttrMap
+
+	formField
+}
+
+// attrs registers with an enclosing Form and returns the attributes for the element
+func (c *Input) attrs(vgin *vugu.BuildIn) vugu.AttrMap {
+	c.register(vgin, c.AttrMap, c.check)
+	return c.fieldAttrs(c.AttrMap)
+}
+
+// check returns the error of the value for the Form
+func (c *Input) check() error {
+	var v interface{}
+	switch {
+	case c.inputType() == "checkbox" && c.Values != nil:
+		v = c.Values.StringSliceValue()
+	case c.inputType() == "checkbox":
+		v = c.Checked.BoolValue()
+	default:
+		v = c.Value.StringValue()
+	}
+	return checkRules(c.AttrMap, c.Rules, v)
+}
+
+// inputType returns the type attribute in lower case
+func (c *Input) inputType() string {
+	return inputType(c.AttrMap, "")
+}
+
+// valueProp returns the value property of the element
+func (c *Input) valueProp() string {
+	switch c.inputType() {
+	case "checkbox", "radio":
+		return c.Option
+	}
+	if c.Value == nil {
+		panic(errors.New("Input.Value must not be nil"))
+	}
+	return c.Value.StringValue()
+}
+
+// isChecked returns the checked property of the element
+func (c *Input) isChecked() bool {
+	switch c.inputType() {
+	case "checkbox":
+		if c.Values != nil {
+			return sliceHas(c.Values.StringSliceValue(), c.Option)
+		}
+		if c.Checked == nil {
+			panic(errors.New("Input.Checked or Input.Values must be set for type=checkbox"))
+		}
+		return c.Checked.BoolValue()
+	case "radio":
+		if c.Value == nil {
+			panic(errors.New("Input.Value must not be nil"))
+		}
+		return c.Value.StringValue() == c.Option
+	}
+	return false
 }
 
 func (c *Input) handleChange(event vugu.DOMEvent) {
 
-	newVal := event.PropString("target", "value")
-	// c.curVal = newVal // why not
-	c.Value.SetStringValue(newVal)
+	switch c.inputType() {
+
+	case "checkbox":
+		checked := event.PropBool("target", "checked")
+		if c.Values != nil {
+			c.Values.SetStringSliceValue(sliceSet(c.Values.StringSliceValue(), c.Option, checked))
+		} else {
+			c.Checked.SetBoolValue(checked)
+		}
+
+	case "radio":
+		// only the newly selected input of a group gets a change event
+		if event.PropBool("target", "checked") {
+			c.Value.SetStringValue(c.Option)
+		}
+
+	default:
+		newVal := event.PropString("target", "value")
+		// c.curVal = newVal // why not
+		c.Value.SetStringValue(newVal)
+	}
 
+	c.changed()
 }
@@ -17,9 +17,16 @@ func (c *Input) Build(vgin *vugu.BuildIn) (vgout *vugu.BuildOut) {
 	var vgn *vugu.VGNode
 	vgn = &vugu.VGNode{Type: vugu.VGNodeType(3), Namespace: "", Data: "input", Attr: []vugu.VGAttribute(nil)}
 	vgout.Out = append(vgout.Out, vgn)	// root for output
-	vgn.AddAttrList(c.AttrMap)
+	vgn.AddAttrList(c.attrs(vgin))
 	{
-		b, err := vjson.Marshal(c.Value.StringValue())
+		b, err := vjson.Marshal(c.isChecked())
+		if err != nil {
+			panic(err)
+		}
+		vgn.Prop = append(vgn.Prop, vugu.VGProperty{Key: "checked", JSONVal: vjson.RawMessage(b)})
+	}
+	{
+		b, err := vjson.Marshal(c.valueProp())
 		if err != nil {
 			panic(err)
 		}
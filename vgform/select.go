@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/vugu/vugu"
+	js "github.com/vugu/vugu/js"
 )
 
 // Select wraps an HTML select element.
@@ -17,22 +18,30 @@ import (
 // The Value field is a StringValuer which is used to get the current
 // value upon render, and update it when changed.
 // The StringPtr type provides a simple adapter for *string.
-//
-// NOTE: When slots are supported this will be updated to support
-// providing your own option tags so you can do things like
-// option groups.
+// With the multiple attribute the Values field is used instead, with
+// the keys of all selected options; StringSlicePtr adapts a *[]string.
+// Selected values which are not among the current options are kept,
+// so options can be filtered or loaded later without losing them.
 //
 // For the common case of simple lists of texts, set the Options
 // as appropriate.  See SliceOptions and MapOptions
-// for convenient adapters for []string and map[string]string.
+// for convenient adapters for []string and map[string]string,
+// and GroupOptions for options in optgroup elements (options
+// which are not in a group are shown first).
+// DisabledOptions disables some of the options, e.g. with DisabledKeys.
 type Select struct {
 	Value StringValuer // get/set the currently selected value
 	// TODO: should we also just make a ValuePtr *string - which would let people
 	// do :ValuePtr="&c.SomeRegularString" - seems like some people will want the convenience
-	// TODO: multiple (will need a new field and a new type, StringSliceValuer?)
+
+	Values StringSliceValuer // get/set the selected values, with the multiple attribute
 
 	Options Options // provide KeyLister and TextMapper in one
 
+	DisabledOptions KeyDisabler // optional, the options it returns true for are disabled
+
+	Rules []Validator // checked when in a Form
+
 	AttrMap vugu.AttrMap // regular HTML attributes like id and class
 	// TODO: might make sense to refactor this AttrMap thing to work well
 	// with SetAttributeInterface and AttributeLister/vg-attr - perhaps a slice of attributes
@@ -41,67 +50,127 @@ type Select struct {
 	// logic but works on an list of attributes.)  A type AttributeList []VGAttribute
 	// might be in order.  Refactor to separate vgnode package might be appropriate...
 
-	// el     js.Value
-	keys   []string
-	curVal string
+	curVal  string
+	curVals []string
+	groups  []OptionGroup // the groups with a label, shown after the other options
+
+	formField
 }
 
-func (c *Select) buildKeys() []string {
+// attrs registers with an enclosing Form and returns the attributes for the element
+func (c *Select) attrs(vgin *vugu.BuildIn) vugu.AttrMap {
+	c.register(vgin, c.AttrMap, c.check)
+	return c.fieldAttrs(c.AttrMap)
+}
+
+// check returns the error of the value for the Form
+func (c *Select) check() error {
+	if c.multiple() {
+		return checkRules(c.AttrMap, c.Rules, c.Values.StringSliceValue())
+	}
+	return checkRules(c.AttrMap, c.Rules, c.Value.StringValue())
+}
+
+func (c *Select) multiple() bool {
+	return hasAttr(c.AttrMap, "multiple")
+}
 
-	// if c.el.IsUndefined() {
-	// 	panic(errors.New("Select should have c.el set"))
-	// }
-	if c.Value == nil {
-		panic(errors.New("Select.Value must not be nil"))
+// buildOptions reads the current value and the options, and returns those not in an optgroup
+// element: all of them, unless Options is an OptionGrouper.  (A vg-template can not be
+// used in the select element to tell these apart, as the HTML parser drops it there.)
+func (c *Select) buildOptions() []selectOption {
+
+	if c.multiple() {
+		if c.Values == nil {
+			panic(errors.New("Select.Values must not be nil with the multiple attribute"))
+		}
+		c.curVals = c.Values.StringSliceValue()
+	} else {
+		if c.Value == nil {
+			panic(errors.New("Select.Value must not be nil"))
+		}
+		c.curVal = c.Value.StringValue()
 	}
 	if c.Options == nil {
-		panic(errors.New("Select.Options must not be nil (TODO: when slots are supported that will be allowed instead of Options)"))
+		panic(errors.New("Select.Options must not be nil"))
 	}
 
-	c.keys = c.Options.KeyList()
-	c.curVal = c.Value.StringValue()
+	groups := []OptionGroup{{Options: c.Options}}
+	if g, ok := c.Options.(OptionGrouper); ok {
+		groups = g.OptionGroups()
+	}
+
+	var ret []selectOption
+	c.groups = c.groups[:0]
+	for _, g := range groups {
+		if g.Label != "" {
+			c.groups = append(c.groups, g)
+			continue
+		}
+		for _, k := range g.Options.KeyList() {
+			ret = append(ret, selectOption{key: k, text: g.Options.TextMap(k), disabled: g.Disabled})
+		}
+	}
 
-	return c.keys
+	return ret
+}
+
+// selectOption is an option of a Select which is not in an optgroup element
+type selectOption struct {
+	key      string
+	text     string
+	disabled bool // the group is disabled
 }
 
 func (c *Select) isOptSelected(k string) bool {
+	if c.multiple() {
+		return sliceHas(c.curVals, k)
+	}
 	return c.curVal == k
 }
 
-func (c *Select) optText(k string) string {
-	return c.Options.TextMap(k)
+func (c *Select) isOptDisabled(k string) bool {
+	return c.DisabledOptions != nil && c.DisabledOptions.KeyDisabled(k)
 }
 
-func (c *Select) handlePopulate() {
-
-	// var buf bytes.Buffer
-	// buf.Grow(len(`<option value=""></option>`) * len(c.keys) * 2)
-	// for _, k := range c.keys {
-	// 	buf.WriteString(`<option value="`)
-	// 	buf.WriteString(html.EscapeString(k))
-	// 	buf.WriteString(`">`)
-	// 	buf.WriteString(html.EscapeString(c.Options.TextMap(k)))
-	// 	buf.WriteString(`</option>`)
-	// }
-	// c.el.Set("innerHTML", buf.String())
-
-	// see if we need this...
-	// v := c.curVal
-	// idx := -1
-	// for i, k := range c.keys {
-	// 	if k == v {
-	// 		idx = i
-	// 	}
-	// }
-
-	// c.el.Set("selectedIndex", idx)
-
+// handlePopulate selects the options for the current value after each render.  The selected
+// attribute only sets the initial state of an option, once the user changed the selection
+// (or options were added or removed) it is the selected property which counts.
+func (c *Select) handlePopulate(el js.Value) {
+	if el.IsUndefined() || el.IsNull() {
+		return
+	}
+	opts := el.Get("options")
+	n := opts.Length()
+	for i := 0; i < n; i++ {
+		opt := opts.Index(i)
+		opt.Set("selected", c.isOptSelected(opt.Get("value").String()))
+	}
 }
 
 func (c *Select) handleChange(event vugu.DOMEvent) {
 
+	if c.multiple() {
+		// only the options shown are changed, selected values which are not among them stay
+		vals := c.Values.StringSliceValue()
+		opts := event.JSEventTarget().Get("options")
+		n := opts.Length()
+		for i := 0; i < n; i++ {
+			opt := opts.Index(i)
+			k, sel := opt.Get("value").String(), opt.Get("selected").Bool()
+			if sel != sliceHas(vals, k) {
+				vals = sliceSet(vals, k, sel)
+			}
+		}
+		c.curVals = vals
+		c.Values.SetStringSliceValue(vals)
+		c.changed()
+		return
+	}
+
 	newVal := event.PropString("target", "value")
 	c.curVal = newVal // why not
 	c.Value.SetStringValue(newVal)
 
+	c.changed()
 }
@@ -12,7 +12,7 @@ import js "github.com/vugu/vugu/js"
 
 VARIOUS OLD NOTES:
 
-// vg-js-create='c.el=value' vg-js-populate='c.handlePopulate()'
+// vg-js-create='c.el=value'
 //  vg-attr='vugu.VGAttributeListerFunc(c.makeAttrs)'
 
 Things to figure out:
@@ -166,7 +166,8 @@ func (c *Select) Build(vgin *vugu.BuildIn) (vgout *vugu.BuildOut) {
 	var vgn *vugu.VGNode
 	vgn = &vugu.VGNode{Type: vugu.VGNodeType(3), Namespace: "", Data: "select", Attr: []vugu.VGAttribute(nil)}
 	vgout.Out = append(vgout.Out, vgn)	// root for output
-	vgn.AddAttrList(c.AttrMap)
+	vgn.AddAttrList(c.attrs(vgin))
+	vgn.JSPopulateHandler = vugu.JSValueFunc(func(value js.Value) { c.handlePopulate(value) })
 	vgn.DOMEventHandlerSpecList = append(vgn.DOMEventHandlerSpecList, vugu.DOMEventHandlerSpec{
 		EventType:	"change",
 		Func:		func(event vugu.DOMEvent) { c.handleChange(event) },
@@ -177,16 +178,49 @@ func (c *Select) Build(vgin *vugu.BuildIn) (vgout *vugu.BuildOut) {
 		_ = vgparent
 		vgn = &vugu.VGNode{Type: vugu.VGNodeType(1), Data: "\n    "}
 		vgparent.AppendChild(vgn)
-		for vgiterkeyt, k := range c.buildKeys() {
+		for vgiterkeyt, o := range c.buildOptions() {
 			var vgiterkey interface{} = vgiterkeyt
 			_ = vgiterkey
-			k := k
-			_ = k
+			o := o
+			_ = o
 			vgn = &vugu.VGNode{Type: vugu.VGNodeType(3), Namespace: "", Data: "option", Attr: []vugu.VGAttribute(nil)}
 			vgparent.AppendChild(vgn)
-			vgn.AddAttrInterface("selected", c.isOptSelected(k))
-			vgn.AddAttrInterface("value", k)
-			vgn.SetInnerHTML(c.optText(k))
+			vgn.AddAttrInterface("disabled", o.disabled || c.isOptDisabled(o.key))
+			vgn.AddAttrInterface("selected", c.isOptSelected(o.key))
+			vgn.AddAttrInterface("value", o.key)
+			vgn.SetInnerHTML(o.text)
+		}
+		vgn = &vugu.VGNode{Type: vugu.VGNodeType(1), Data: "\n    "}
+		vgparent.AppendChild(vgn)
+		for vgiterkeyt, g := range c.groups {
+			var vgiterkey interface{} = vgiterkeyt
+			_ = vgiterkey
+			g := g
+			_ = g
+			vgn = &vugu.VGNode{Type: vugu.VGNodeType(3), Namespace: "", Data: "optgroup", Attr: []vugu.VGAttribute(nil)}
+			vgparent.AppendChild(vgn)
+			vgn.AddAttrInterface("disabled", g.Disabled)
+			vgn.AddAttrInterface("label", g.Label)
+			{
+				vgparent := vgn
+				_ = vgparent
+				vgn = &vugu.VGNode{Type: vugu.VGNodeType(1), Data: "\n        "}
+				vgparent.AppendChild(vgn)
+				for vgiterkeyt, k := range g.Options.KeyList() {
+					var vgiterkey interface{} = vgiterkeyt
+					_ = vgiterkey
+					k := k
+					_ = k
+					vgn = &vugu.VGNode{Type: vugu.VGNodeType(3), Namespace: "", Data: "option", Attr: []vugu.VGAttribute(nil)}
+					vgparent.AppendChild(vgn)
+					vgn.AddAttrInterface("disabled", c.isOptDisabled(k))
+					vgn.AddAttrInterface("selected", c.isOptSelected(k))
+					vgn.AddAttrInterface("value", k)
+					vgn.SetInnerHTML(g.Options.TextMap(k))
+				}
+				vgn = &vugu.VGNode{Type: vugu.VGNodeType(1), Data: "\n    "}
+				vgparent.AppendChild(vgn)
+			}
 		}
 		vgn = &vugu.VGNode{Type: vugu.VGNodeType(1), Data: "\n"}
 		vgparent.AppendChild(vgn)
@@ -5,7 +5,21 @@ import "github.com/vugu/vugu"
 // Textarea corresponds to a textarea HTML element.
 type Textarea struct {
 	Value   StringValuer // get/set the currently selected value
+	Rules   []Validator  // checked when in a Form
 	AttrMap vugu.AttrMap
+
+	formField
+}
+
+// attrs registers with an enclosing Form and returns the attributes for the element
+func (c *Textarea) attrs(vgin *vugu.BuildIn) vugu.AttrMap {
+	c.register(vgin, c.AttrMap, c.check)
+	return c.fieldAttrs(c.AttrMap)
+}
+
+// check returns the error of the value for the Form
+func (c *Textarea) check() error {
+	return checkRules(c.AttrMap, c.Rules, c.Value.StringValue())
 }
 
 func (c *Textarea) handleChange(event vugu.DOMEvent) {
@@ -14,4 +28,5 @@ func (c *Textarea) handleChange(event vugu.DOMEvent) {
 	// c.curVal = newVal // why not
 	c.Value.SetStringValue(newVal)
 
+	c.changed()
 }
@@ -17,7 +17,7 @@ func (c *Textarea) Build(vgin *vugu.BuildIn) (vgout *vugu.BuildOut) {
 	var vgn *vugu.VGNode
 	vgn = &vugu.VGNode{Type: vugu.VGNodeType(3), Namespace: "", Data: "textarea", Attr: []vugu.VGAttribute(nil)}
 	vgout.Out = append(vgout.Out, vgn)	// root for output
-	vgn.AddAttrList(c.AttrMap)
+	vgn.AddAttrList(c.attrs(vgin))
 	{
 		b, err := vjson.Marshal(c.Value.StringValue())
 		if err != nil {
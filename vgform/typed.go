@@ -0,0 +1,143 @@
+package vgform
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vugu/vugu"
+	js "github.com/vugu/vugu/js"
+)
+
+// typedInput is what the inputs for typed values (InputInt, InputFloat, InputTime and InputColor)
+// have in common.  Text which can not be parsed, or is out of range, is not set on the value;
+// instead it stays in the input and the error is the validation state of the input: the element
+// gets aria-invalid="true" and a custom validity message (so the :invalid CSS pseudo-class applies
+// and the browser does not submit the form), and the error is stored in Err if it is set.  In a Form
+// it is the error of the control, ahead of its Rules.
+type typedInput struct {
+	text     string // what was entered, if it is not valid
+	err      error
+	badInput bool // the browser could not parse what was entered, and reported it as "" (validity.badInput)
+
+	formField
+}
+
+// setResult records the result of a change of the text of the input, and reports it to errp if set.
+func (t *typedInput) setResult(text string, err error, errp *error) {
+	t.err = err
+	t.badInput = false
+	t.text = ""
+	if err != nil {
+		t.text = text
+	}
+	if errp != nil {
+		*errp = err
+	}
+	t.changed()
+}
+
+// setBadInput records that the browser could not parse what was entered.  The value is not changed,
+// and the text in the input is left alone, since the browser does not tell what it is.
+func (t *typedInput) setBadInput(err error, errp *error) {
+	t.setResult("", err, errp)
+	t.badInput = true
+}
+
+// check returns the error for the Form: the error of the text entered, or else of the value.  value
+// returns the value and the error of parsing it as formatted, so values which were not entered but
+// set by the program are checked against the attributes (like min and max) as well.
+func (t *typedInput) check(attrs vugu.AttrMap, rules []Validator, value func() (interface{}, error)) error {
+	if t.err != nil {
+		return t.err
+	}
+	v, err := value()
+	if err != nil {
+		return err
+	}
+	return checkRules(attrs, rules, v)
+}
+
+// valueText returns the text to show in the input: what was entered if it is not valid, otherwise
+// the value as formatted by format.
+func (t *typedInput) valueText(format func() string) string {
+	if t.err != nil {
+		return t.text
+	}
+	return format()
+}
+
+// inputAttrs returns the attributes for the input element: those in m, with defaults added for the
+// ones which are not in m, and aria-invalid if the value is not valid.
+func (t *typedInput) inputAttrs(m vugu.AttrMap, defaults ...string) vugu.AttrMap {
+	ret := make(vugu.AttrMap, len(m)+len(defaults)/2+1)
+	for i := 0; i+1 < len(defaults); i += 2 {
+		ret[defaults[i]] = defaults[i+1]
+	}
+	for k, v := range m {
+		ret[k] = v
+	}
+	if t.err != nil || t.invalid() {
+		ret["aria-invalid"] = "true"
+	}
+	return ret
+}
+
+// populate sets the value of the element to text, unless it holds text the browser could not parse,
+// and sets its custom validity message to the error, or clears it.  The value is set here rather than
+// with a .value property, as that would also replace text which was not parsed.
+func (t *typedInput) populate(el js.Value, text string) {
+	if el.IsUndefined() || el.IsNull() {
+		return
+	}
+	if !t.badInput && el.Get("value").String() != text {
+		el.Set("value", text)
+	}
+	msg := ""
+	if t.err != nil {
+		msg = t.err.Error()
+	}
+	el.Call("setCustomValidity", msg)
+}
+
+// badInput returns true if the browser could not parse what was entered in the target of event, e.g.
+// "1e" in a number input.  It then reports the value as "", so this is only meaningful for an empty value.
+func badInput(event vugu.DOMEvent) bool {
+	v := event.JSEventTarget().Get("validity")
+	return !v.IsUndefined() && !v.IsNull() && v.Get("badInput").Bool()
+}
+
+// errRequired is the error for an empty input with the required attribute
+var errRequired = errors.New("a value is required")
+
+// attrString returns the attribute key of m as a string, if it is set.
+func attrString(m vugu.AttrMap, key string) (string, bool) {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return "", false
+	}
+	if s, ok := v.(string); ok {
+		return s, true
+	}
+	return fmt.Sprint(v), true
+}
+
+// hasAttr returns true if m has the boolean attribute key, like required
+func hasAttr(m vugu.AttrMap, key string) bool {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return false
+	}
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	return true
+}
+
+// inputType returns the type attribute of m in lower case, or def if it is not set
+func inputType(m vugu.AttrMap, def string) string {
+	if t, ok := attrString(m, "type"); ok && t != "" {
+		return strings.ToLower(t)
+	}
+	return def
+}
@@ -37,15 +37,77 @@ var SimpleTitle = TextMapperFunc(func(key string) string {
 
 // Options is an interface with KeyList and TextMap.
 // It is used to express the options for a select element.
-// It intentionally does not support option groups or other
-// advanced behavior as that can be accomplished using slots (TO BE IMPLEMENTED).
 // Options is provided to make it easy for the common case of
 // adapting a slice or map to be used as select options.
+// Options which also implement OptionGrouper are shown in
+// option groups, see GroupOptions.
 type Options interface {
 	KeyLister
 	TextMapper
 }
 
+// OptionGrouper is implemented by Options which put their options in groups.
+// A Select shows each group in an optgroup element, in order, instead of
+// the options of KeyList.
+type OptionGrouper interface {
+	OptionGroups() []OptionGroup
+}
+
+// OptionGroup is a group of options, shown in an optgroup element with Label as its label.
+// The options of a group with an empty Label are shown without an optgroup element.
+type OptionGroup struct {
+	Label    string
+	Options  Options
+	Disabled bool // disables all options in the group
+}
+
+// GroupOptions implements Options and OptionGrouper on a slice of option groups.
+//
+//	vgform.GroupOptions{
+//		{Label: "Fruit", Options: vgform.SliceOptions{"apple", "pear"}.Title()},
+//		{Label: "Vegetables", Options: vgform.SliceOptions{"carrot", "leek"}.Title()},
+//	}
+type GroupOptions []OptionGroup
+
+// KeyList implements KeyLister by returning the keys of all groups.
+func (g GroupOptions) KeyList() []string {
+	var s []string
+	for _, og := range g {
+		s = append(s, og.Options.KeyList()...)
+	}
+	return s
+}
+
+// TextMap implements TextMapper by returning the text of the first group with key.
+func (g GroupOptions) TextMap(key string) string {
+	for _, og := range g {
+		if sliceHas(og.Options.KeyList(), key) {
+			return og.Options.TextMap(key)
+		}
+	}
+	return key
+}
+
+// OptionGroups implements OptionGrouper.
+func (g GroupOptions) OptionGroups() []OptionGroup { return g }
+
+// KeyDisabler tells which keys are for disabled options.
+type KeyDisabler interface {
+	KeyDisabled(key string) bool
+}
+
+// KeyDisablerFunc implements KeyDisabler as a function.
+type KeyDisablerFunc func(key string) bool
+
+// KeyDisabled implements the KeyDisabler interface.
+func (f KeyDisablerFunc) KeyDisabled(key string) bool { return f(key) }
+
+// DisabledKeys implements KeyDisabler on a []string, the options with these keys are disabled.
+type DisabledKeys []string
+
+// KeyDisabled implements KeyDisabler by returning true if key is in the slice.
+func (s DisabledKeys) KeyDisabled(key string) bool { return sliceHas(s, key) }
+
 // MapOptions implements the Options interface on a map[string]string.
 // The keys will be returned in alphanumeric sequence (using sort.Strings),
 // or you can call SortFunc to assign a custom sort function.
@@ -0,0 +1,152 @@
+package vgform
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"reflect"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vugu/vugu"
+)
+
+// Validator checks the value of a form control and returns an error describing what is wrong with it,
+// or nil if it is valid.  The value is a string for Input, Textarea and Select, except a bool for
+// a checkbox bound to Checked and a []string for a checkbox group, and an int, float64, time.Time
+// or color.RGBA for InputInt, InputFloat, InputTime and InputColor.
+type Validator interface {
+	Validate(value interface{}) error
+}
+
+// ValidatorFunc implements Validator as a function, for custom validators.
+type ValidatorFunc func(value interface{}) error
+
+// Validate implements Validator.
+func (f ValidatorFunc) Validate(value interface{}) error { return f(value) }
+
+// Rules returns its arguments, for setting the rules of a control in an attribute:
+// `:Rules='vgform.Rules(vgform.Required, vgform.Length(2, 50))'`.  The rules are checked in order,
+// the first error is the error of the control.  Except Required, the built-in rules accept empty
+// values, so fields which are not required can be left empty.
+func Rules(v ...Validator) []Validator { return v }
+
+// Required rejects empty values: an empty string or slice, false, and the zero time.
+// For InputInt and InputFloat use the required attribute instead, which rejects an empty input.
+var Required Validator = ValidatorFunc(func(value interface{}) error {
+	if isEmpty(value) {
+		return errRequired
+	}
+	return nil
+})
+
+// Length requires strings to have at least min and at most max characters, and slices to have
+// that many elements.  A max of 0 means no maximum.
+func Length(min, max int) Validator {
+	return ValidatorFunc(func(value interface{}) error {
+		var n int
+		unit := "characters"
+		switch v := value.(type) {
+		case string:
+			n = utf8.RuneCountInString(v)
+		case []string:
+			n = len(v)
+			unit = "selected"
+		default:
+			panic(fmt.Errorf("vgform.Length can not check a %T", value))
+		}
+		switch {
+		case n == 0:
+			return nil
+		case n < min:
+			return fmt.Errorf("must have at least %d %s", min, unit)
+		case max > 0 && n > max:
+			return fmt.Errorf("must have at most %d %s", max, unit)
+		}
+		return nil
+	})
+}
+
+// Pattern requires strings to match the regular expression expr as a whole, like the pattern attribute.
+// It panics if expr does not compile.
+func Pattern(expr string) Validator {
+	re := regexp.MustCompile(`^(?:` + expr + `)$`)
+	return ValidatorFunc(func(value interface{}) error {
+		s, ok := value.(string)
+		if !ok {
+			panic(fmt.Errorf("vgform.Pattern can not check a %T", value))
+		}
+		if s != "" && !re.MatchString(s) {
+			return errors.New("is not in the expected format")
+		}
+		return nil
+	})
+}
+
+// Range requires numbers to be at least min and at most max.
+func Range(min, max float64) Validator {
+	return ValidatorFunc(func(value interface{}) error {
+		var f float64
+		switch v := value.(type) {
+		case int:
+			f = float64(v)
+		case float64:
+			f = v
+		default:
+			panic(fmt.Errorf("vgform.Range can not check a %T", value))
+		}
+		if f < min || f > max {
+			return fmt.Errorf("must be between %s and %s", formatFloat(min), formatFloat(max))
+		}
+		return nil
+	})
+}
+
+// WithMessage returns a Validator which returns an error with msg instead of the error from v.
+func WithMessage(v Validator, msg string) Validator {
+	return ValidatorFunc(func(value interface{}) error {
+		if v.Validate(value) != nil {
+			return errors.New(msg)
+		}
+		return nil
+	})
+}
+
+// isEmpty returns true for the values rejected by Required
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case time.Time:
+		return v.IsZero()
+	case color.RGBA, int, float64:
+		return false
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
+
+// checkRules returns the first error of rules for value.  If attrs has the required attribute,
+// Required is checked first.
+func checkRules(attrs vugu.AttrMap, rules []Validator, value interface{}) error {
+	if hasAttr(attrs, "required") {
+		if err := Required.Validate(value); err != nil {
+			return err
+		}
+	}
+	for _, r := range rules {
+		if err := r.Validate(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package vgform
+
+import (
+	"errors"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vugu/vugu"
+)
+
+func TestValidators(t *testing.T) {
+
+	cases := []struct {
+		v     Validator
+		value interface{}
+		err   string // empty for no error
+	}{
+		{Required, "", "a value is required"},
+		{Required, "x", ""},
+		{Required, false, "a value is required"},
+		{Required, true, ""},
+		{Required, []string(nil), "a value is required"},
+		{Required, []string{"a"}, ""},
+		{Required, time.Time{}, "a value is required"},
+		{Required, time.Now(), ""},
+		{Required, 0, ""}, // numbers are never empty, see the required attribute
+		{Required, 0.0, ""},
+		{Required, color.RGBA{}, ""},
+		{Required, nil, "a value is required"},
+
+		{Length(2, 4), "", ""}, // empty values are left to Required
+		{Length(2, 4), "a", "must have at least 2 characters"},
+		{Length(2, 4), "ab", ""},
+		{Length(2, 4), "äöüß", ""}, // characters, not bytes
+		{Length(2, 4), "abcde", "must have at most 4 characters"},
+		{Length(2, 0), "abcdefghijk", ""},
+		{Length(2, 3), []string{"a"}, "must have at least 2 selected"},
+		{Length(2, 3), []string{"a", "b", "c", "d"}, "must have at most 3 selected"},
+
+		{Pattern(`[a-z]+\d`), "abc1", ""},
+		{Pattern(`[a-z]+\d`), "abc1x", "is not in the expected format"}, // the whole value must match
+		{Pattern(`[a-z]+\d`), "xabc", "is not in the expected format"},
+		{Pattern(`a|b`), "ab", "is not in the expected format"},
+		{Pattern(`a|b`), "", ""},
+
+		{Range(1, 10), 1, ""},
+		{Range(1, 10), 10.0, ""},
+		{Range(1, 10), 0, "must be between 1 and 10"},
+		{Range(0.5, 1.5), 1.75, "must be between 0.5 and 1.5"},
+
+		{WithMessage(Length(3, 0), "too short"), "ab", "too short"},
+		{WithMessage(Length(3, 0), "too short"), "abc", ""},
+	}
+
+	for _, tc := range cases {
+		err := tc.v.Validate(tc.value)
+		if tc.err == "" {
+			assert.NoError(t, err, "%#v", tc.value)
+		} else if assert.Error(t, err, "%#v", tc.value) {
+			assert.Equal(t, tc.err, err.Error(), "%#v", tc.value)
+		}
+	}
+
+	assert.Panics(t, func() { Length(1, 2).Validate(42) })
+	assert.Panics(t, func() { Pattern(`x`).Validate(42) })
+	assert.Panics(t, func() { Range(1, 2).Validate("1") })
+	assert.Panics(t, func() { Pattern(`(`) })
+}
+
+func TestCheckRules(t *testing.T) {
+
+	notBob := ValidatorFunc(func(value interface{}) error {
+		if value == "bob" {
+			return errors.New("not bob")
+		}
+		return nil
+	})
+	rules := Rules(Length(2, 0), notBob)
+
+	assert.NoError(t, checkRules(nil, rules, ""))
+	assert.EqualError(t, checkRules(vugu.AttrMap{"required": true}, rules, ""), "a value is required")
+	assert.NoError(t, checkRules(vugu.AttrMap{"required": false}, rules, ""))
+	assert.EqualError(t, checkRules(nil, rules, "a"), "must have at least 2 characters") // the first error
+	assert.EqualError(t, checkRules(nil, rules, "bob"), "not bob")
+	assert.NoError(t, checkRules(nil, rules, "alice"))
+}
@@ -1,6 +1,10 @@
 package vgform
 
-import "errors"
+import (
+	"errors"
+	"image/color"
+	"time"
+)
 
 // StringValuer is a string that can be gotten and set.
 type StringValuer interface {
@@ -57,3 +61,192 @@ func (s StringPtr) SetStringValue(v string) {
 	}
 	*s.Value = v
 }
+
+// BoolValuer is a bool that can be gotten and set.
+type BoolValuer interface {
+	BoolValue() bool
+	SetBoolValue(bool)
+}
+
+// BoolPtr implements BoolValuer on a bool pointer.
+type BoolPtr struct {
+	Value *bool
+}
+
+// BoolValue implements BoolValuer
+func (s BoolPtr) BoolValue() bool {
+	if s.Value == nil {
+		panic(errors.New("BoolPtr must not have a nil pointer"))
+	}
+	return *s.Value
+}
+
+// SetBoolValue implements BoolValuer
+func (s BoolPtr) SetBoolValue(v bool) {
+	if s.Value == nil {
+		panic(errors.New("BoolPtr must not have a nil pointer"))
+	}
+	*s.Value = v
+}
+
+// IntValuer is an int that can be gotten and set.
+type IntValuer interface {
+	IntValue() int
+	SetIntValue(int)
+}
+
+// IntPtr implements IntValuer on an int pointer.
+type IntPtr struct {
+	Value *int
+}
+
+// IntValue implements IntValuer
+func (s IntPtr) IntValue() int {
+	if s.Value == nil {
+		panic(errors.New("IntPtr must not have a nil pointer"))
+	}
+	return *s.Value
+}
+
+// SetIntValue implements IntValuer
+func (s IntPtr) SetIntValue(v int) {
+	if s.Value == nil {
+		panic(errors.New("IntPtr must not have a nil pointer"))
+	}
+	*s.Value = v
+}
+
+// FloatValuer is a float64 that can be gotten and set.
+type FloatValuer interface {
+	FloatValue() float64
+	SetFloatValue(float64)
+}
+
+// FloatPtr implements FloatValuer on a float64 pointer.
+type FloatPtr struct {
+	Value *float64
+}
+
+// FloatValue implements FloatValuer
+func (s FloatPtr) FloatValue() float64 {
+	if s.Value == nil {
+		panic(errors.New("FloatPtr must not have a nil pointer"))
+	}
+	return *s.Value
+}
+
+// SetFloatValue implements FloatValuer
+func (s FloatPtr) SetFloatValue(v float64) {
+	if s.Value == nil {
+		panic(errors.New("FloatPtr must not have a nil pointer"))
+	}
+	*s.Value = v
+}
+
+// TimeValuer is a time.Time that can be gotten and set.
+type TimeValuer interface {
+	TimeValue() time.Time
+	SetTimeValue(time.Time)
+}
+
+// TimePtr implements TimeValuer on a time.Time pointer.
+type TimePtr struct {
+	Value *time.Time
+}
+
+// TimeValue implements TimeValuer
+func (s TimePtr) TimeValue() time.Time {
+	if s.Value == nil {
+		panic(errors.New("TimePtr must not have a nil pointer"))
+	}
+	return *s.Value
+}
+
+// SetTimeValue implements TimeValuer
+func (s TimePtr) SetTimeValue(v time.Time) {
+	if s.Value == nil {
+		panic(errors.New("TimePtr must not have a nil pointer"))
+	}
+	*s.Value = v
+}
+
+// ColorValuer is a color that can be gotten and set.
+// The alpha channel is not used, colors in HTML inputs are opaque.
+type ColorValuer interface {
+	ColorValue() color.RGBA
+	SetColorValue(color.RGBA)
+}
+
+// ColorPtr implements ColorValuer on a color.RGBA pointer.
+type ColorPtr struct {
+	Value *color.RGBA
+}
+
+// ColorValue implements ColorValuer
+func (s ColorPtr) ColorValue() color.RGBA {
+	if s.Value == nil {
+		panic(errors.New("ColorPtr must not have a nil pointer"))
+	}
+	return *s.Value
+}
+
+// SetColorValue implements ColorValuer
+func (s ColorPtr) SetColorValue(v color.RGBA) {
+	if s.Value == nil {
+		panic(errors.New("ColorPtr must not have a nil pointer"))
+	}
+	*s.Value = v
+}
+
+// StringSliceValuer is a list of strings that can be gotten and set.
+// It is used as a set, e.g. the values of the checked checkboxes in a group.
+type StringSliceValuer interface {
+	StringSliceValue() []string
+	SetStringSliceValue([]string)
+}
+
+// StringSlicePtr implements StringSliceValuer on a string slice pointer.
+type StringSlicePtr struct {
+	Value *[]string
+}
+
+// StringSliceValue implements StringSliceValuer
+func (s StringSlicePtr) StringSliceValue() []string {
+	if s.Value == nil {
+		panic(errors.New("StringSlicePtr must not have a nil pointer"))
+	}
+	return *s.Value
+}
+
+// SetStringSliceValue implements StringSliceValuer
+func (s StringSlicePtr) SetStringSliceValue(v []string) {
+	if s.Value == nil {
+		panic(errors.New("StringSlicePtr must not have a nil pointer"))
+	}
+	*s.Value = v
+}
+
+// sliceHas returns true if v is in s
+func sliceHas(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// sliceSet returns s with v added if on is true, or with v removed if not.
+// A new slice is returned, s is not modified.
+func sliceSet(s []string, v string, on bool) []string {
+	ret := make([]string, 0, len(s)+1)
+	for _, e := range s {
+		if e != v {
+			ret = append(ret, e)
+		}
+	}
+	if on {
+		ret = append(ret, v)
+	}
+	return ret
+}
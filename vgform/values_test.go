@@ -0,0 +1,32 @@
+package vgform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSliceSet(t *testing.T) {
+
+	cases := []struct {
+		s    []string
+		v    string
+		on   bool
+		want []string
+	}{
+		{nil, "a", true, []string{"a"}},
+		{nil, "a", false, []string{}},
+		{[]string{"a", "b"}, "c", true, []string{"a", "b", "c"}},
+		{[]string{"a", "b"}, "a", false, []string{"b"}},
+		{[]string{"a", "b"}, "c", false, []string{"a", "b"}},
+		{[]string{"a", "b", "a"}, "a", true, []string{"b", "a"}}, // duplicates are removed
+	}
+
+	for _, tc := range cases {
+		orig := append([]string(nil), tc.s...)
+		got := sliceSet(tc.s, tc.v, tc.on)
+		assert.Equal(t, tc.want, got, "%v %q %v", tc.s, tc.v, tc.on)
+		assert.Equal(t, orig, tc.s, "s must not be modified")
+		assert.Equal(t, tc.on, sliceHas(got, tc.v))
+	}
+}
@@ -27,19 +27,22 @@ package vgform
 /*
 Components:
 
-Select
+Form - validates the controls in it, see Validator for the rules
+Select - binds a StringValuer, or a StringSliceValuer with the multiple attribute
 Input
 	Text
 	Password
 	Email
-    Checkbox - the only differnce here is the bool, might be better to just find a good way to bind a bool value
-	Color
-	Number
-	Radio
-	Range
+	Checkbox - binds a BoolValuer, or a StringSliceValuer for a group of checkboxes
+	Color - InputColor binds a ColorValuer
+	Number - InputInt and InputFloat bind an IntValuer or FloatValuer, checking min, max and step
+	Radio - the inputs of a group share a StringValuer, each has its own Option
+	Range - as Number
 	Search
 	Tel
 	Url
+	Date, Datetime-local, Month, Time, Week - InputTime binds a TimeValuer
+	File - File provides the selected or dropped files, optionally reading their contents
 
 Textarea
 
@@ -50,13 +53,7 @@ Button
 Output
 
 Probably should add but needs more thought:
-File
-Date
-Datetime-local
 hidden
 image
-month
-time
-week
 
 */
@@ -52,6 +52,10 @@ type VGProperty struct {
 // (i.e. <vg-template>) and its children will be "flattened" into the DOM in position of this element
 // and attributes, events, etc. ignored.
 //
+// When PortalTarget is set (Type is ElementNode and Data is empty, as with a template) the node is a "portal":
+// a placeholder comment is rendered in its position and its children are rendered into the element
+// matching the PortalTarget selector instead (i.e. <vg-portal target="#modals">).
+//
 // Prop contains JavaScript property values to be assigned during render. InnerHTML provides alternate
 // HTML content instead of children.  DOMEventHandlerSpecList specifies DOM handlers to register.
 // And the JS...Handler fields are used to register callbacks to obtain information at JS render-time.
@@ -83,6 +87,9 @@ type VGNode struct {
 	JSCreateHandler JSValueHandler
 	// if not-nil, called after children have been visited
 	JSPopulateHandler JSValueHandler
+
+	// if not empty, the children of this node are rendered into the element matching this selector
+	PortalTarget string
 }
 
 // IsComponent returns true if this is a component (Component != nil).
@@ -91,15 +98,24 @@ func (n *VGNode) IsComponent() bool {
 	return n.Component != nil
 }
 
-// IsTemplate returns true if this is a template (Type is ElementNode and Data is an empty string and not a Component).
+// IsTemplate returns true if this is a template (Type is ElementNode and Data is an empty string and not a Component or portal).
 // Templates have their children flattened into the output DOM instead of being processed directly.
 func (n *VGNode) IsTemplate() bool {
-	if n.Type == ElementNode && n.Data == "" && n.Component == nil {
+	if n.Type == ElementNode && n.Data == "" && n.Component == nil && n.PortalTarget == "" {
 		return true
 	}
 	return false
 }
 
+// IsPortal returns true if this is a portal (Type is ElementNode and Data is an empty string and PortalTarget is set).
+// Portals have their children rendered into the element matched by PortalTarget instead of in place.
+func (n *VGNode) IsPortal() bool {
+	return n.Type == ElementNode && n.Data == "" && n.Component == nil && n.PortalTarget != ""
+}
+
+// PortalPlaceholder is the content of the comment node rendered in place of a portal.
+const PortalPlaceholder = "vg-portal"
+
 // InsertBefore inserts newChild as a child of n, immediately before oldChild
 // in the sequence of n's children. oldChild may be nil, in which case newChild
 // is appended to the end of n's children.
@@ -0,0 +1,154 @@
+package vugufmt
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// tabWidth is the number of columns a tab counts as when measuring a line against AttrRules.MaxWidth.
+const tabWidth = 4
+
+// AttrRules says how the attributes of start tags are laid out.  The zero value keeps
+// them in the order and on the lines they were written.
+type AttrRules struct {
+	// Sort puts the attributes of each tag in a canonical order: vg-for, vg-if, vg-key, the other
+	// vg- directives, static attributes, :dynamic attributes, .properties and @events.  Attributes in
+	// the same group keep their order.
+	Sort bool
+	// MaxWidth, if greater than 0, is the width of a line in columns (a tab counts as 4).  Tags which
+	// end within it are written on one line with a single space between attributes, longer tags get
+	// one attribute per line, indented one level more than the tag.
+	MaxWidth int
+}
+
+// UseAttrRules sets the rules for laying out the attributes of start tags.
+func UseAttrRules(rules AttrRules) func(*Formatter) {
+	return func(f *Formatter) {
+		f.AttrRules = rules
+	}
+}
+
+// UseIndent sets the string written once for each level of nesting at the start of a line.
+// The default is a tab.
+func UseIndent(indent string) func(*Formatter) {
+	return func(f *Formatter) {
+		f.Indent = indent
+	}
+}
+
+// indent returns the indentation for one level of nesting.
+func (f *Formatter) indent() string {
+	if f.Indent == "" {
+		return "\t"
+	}
+	return f.Indent
+}
+
+// attrRank returns the position of the group the attribute with key belongs to in the canonical order.
+func attrRank(key string) int {
+	switch {
+	case key == "vg-for", strings.HasPrefix(key, "vg-for."):
+		return 0
+	case key == "vg-if":
+		return 1
+	case key == "vg-key":
+		return 2
+	case strings.HasPrefix(key, "vg-"):
+		return 3
+	case strings.HasPrefix(key, ":"):
+		return 5
+	case strings.HasPrefix(key, "."):
+		return 6
+	case strings.HasPrefix(key, "@"):
+		return 7
+	}
+	return 4
+}
+
+// tagLayout is a start tag split up so its attributes can be moved around.  Concatenating
+// name, then sep and text of each attribute, then end gives the tag as written.
+type tagLayout struct {
+	name  string // "<" and the tag name
+	keys  []string
+	seps  []string // what comes before each attribute, usually a space
+	texts []string // each attribute: key, and "=" and the value if it has one
+	end   string   // what comes after the last attribute, e.g. ">" or " />"
+}
+
+// layout returns the tag according to f.AttrRules.  level is the nesting level of the tag and col
+// the column of the output at which it starts, counting from 0.
+func (f *Formatter) layout(t *tagLayout, level, col int) string {
+
+	order := make([]int, len(t.texts))
+	for i := range order {
+		order[i] = i
+	}
+	if f.AttrRules.Sort {
+		sort.SliceStable(order, func(i, j int) bool {
+			return attrRank(t.keys[order[i]]) < attrRank(t.keys[order[j]])
+		})
+	}
+
+	var sb strings.Builder
+
+	if f.AttrRules.MaxWidth <= 0 || len(t.texts) == 0 {
+		sb.WriteString(t.name)
+		for i, o := range order {
+			sb.WriteString(t.seps[i])
+			sb.WriteString(t.texts[o])
+		}
+		sb.WriteString(t.end)
+		return sb.String()
+	}
+
+	// the end is ">" or "/>", keeping a space before the slash if there was one
+	end := strings.TrimLeft(t.end, " \t\r\n\f")
+	if end != ">" && len(end) < len(t.end) {
+		end = " " + end
+	}
+
+	sb.WriteString(t.name)
+	for _, o := range order {
+		sb.WriteString(" ")
+		sb.WriteString(t.texts[o])
+	}
+	sb.WriteString(end)
+	line := sb.String()
+	if !strings.Contains(line, "\n") && col+textWidth(line) <= f.AttrRules.MaxWidth {
+		return line
+	}
+
+	indent := strings.Repeat(f.indent(), level+1)
+	sb.Reset()
+	sb.WriteString(t.name)
+	for _, o := range order {
+		sb.WriteString("\n")
+		sb.WriteString(indent)
+		sb.WriteString(t.texts[o])
+	}
+	sb.WriteString(end)
+	return sb.String()
+}
+
+// textWidth returns the number of columns s takes up on a line.
+func textWidth(s string) int {
+	return utf8.RuneCountInString(s) + strings.Count(s, "\t")*(tabWidth-1)
+}
+
+// colWriter passes writes on to w and keeps track of the column the next byte is written at.
+type colWriter struct {
+	w   io.Writer
+	col int
+}
+
+func (cw *colWriter) Write(p []byte) (int, error) {
+	s := string(p)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		cw.col = textWidth(s[i+1:])
+	} else {
+		cw.col += textWidth(s)
+	}
+	return cw.w.Write(p)
+}
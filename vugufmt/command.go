@@ -0,0 +1,242 @@
+package vugufmt
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Command runs the vugufmt command line tool: the flags are defined on fs and parsed from args,
+// and the files and directories named by the remaining arguments are formatted (or standard input,
+// if there are none).  Settings are read with FindConfig from the directory of each file (the current
+// directory for standard input) and flags override them.  The exit code is returned.  It is used by
+// both the vugufmt command and `vugu fmt`.
+func Command(fs *flag.FlagSet, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+
+	c := &command{stdout: stdout, stderr: stderr, formatters: make(map[string]*Formatter)}
+
+	c.list = fs.Bool("l", false, "list files whose formatting differs from vugufmt's")
+	c.write = fs.Bool("w", false, "write result to (source) file instead of stdout")
+	c.doDiff = fs.Bool("d", false, "display diffs instead of rewriting files")
+	simplifyAST := fs.Bool("s", false, "simplify code")
+	imports := fs.Bool("i", false, "run goimports instead of gofmt")
+	sortAttrs := fs.Bool("sort-attrs", false, "put attributes in canonical order (directives, static, :dynamic, .prop, @event)")
+	maxWidth := fs.Int("width", 0, "put each attribute on its own line in tags wider than this (default from "+ConfigFileName+", 0 for no limit)")
+	indent := fs.Int("indent", 0, "indent nested elements with this many spaces instead of a tab (default from "+ConfigFileName+")")
+	css := fs.Bool("css", false, "format style blocks")
+	js := fs.Bool("js", false, "re-indent JavaScript blocks")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	// settings from the project file are overridden by flags
+	c.override = func(cfg *Config) {
+		cfg.Simplify = cfg.Simplify || *simplifyAST
+		cfg.Imports = cfg.Imports || *imports
+		cfg.SortAttrs = cfg.SortAttrs || *sortAttrs
+		cfg.CSS = cfg.CSS || *css
+		cfg.JS = cfg.JS || *js
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "width":
+				cfg.MaxWidth = *maxWidth
+			case "indent":
+				cfg.IndentSpaces = *indent
+			}
+		})
+	}
+
+	// If no file paths given, we are reading from stdin.
+	if fs.NArg() == 0 {
+		if err := c.processFile("<standard input>", stdin); err != nil {
+			c.report(err)
+		}
+		return c.exitCode
+	}
+
+	// Otherwise, we need to read a bunch of files
+	for _, path := range fs.Args() {
+		switch dir, err := os.Stat(path); {
+		case err != nil:
+			c.report(err)
+		case dir.IsDir():
+			filepath.Walk(path, c.visitFile)
+		default:
+			if err := c.processFile(path, nil); err != nil {
+				c.report(err)
+			}
+		}
+	}
+
+	return c.exitCode
+}
+
+// command is the state of one run of Command
+type command struct {
+	list, write, doDiff *bool
+
+	override       func(cfg *Config)     // applies the flags to the settings from a project file
+	formatters     map[string]*Formatter // by directory of the files formatted
+	stdout, stderr io.Writer
+	exitCode       int
+}
+
+// formatter returns the formatter for files in dir, with the settings of the project dir is in
+func (c *command) formatter(dir string) (*Formatter, error) {
+	if f := c.formatters[dir]; f != nil {
+		return f, nil
+	}
+	cfg, _, err := FindConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	c.override(cfg)
+	f := NewFormatter(cfg.Options()...)
+	c.formatters[dir] = f
+	return f, nil
+}
+
+func (c *command) visitFile(path string, f os.FileInfo, err error) error {
+	if err == nil && isVuguFile(f) {
+		err = c.processFile(path, nil)
+	}
+
+	// Don't complain if a file was deleted in the meantime (i.e.
+	// the directory changed concurrently while running gofmt).
+	if err != nil && !os.IsNotExist(err) {
+		c.report(err)
+	}
+	return nil
+}
+
+func isVuguFile(f os.FileInfo) bool {
+	// ignore non-Vugu files (except html)
+	name := f.Name()
+	return !f.IsDir() &&
+		!strings.HasPrefix(name, ".") &&
+		(strings.HasSuffix(name, ".vugu") || (strings.HasSuffix(name, ".html")))
+}
+
+func (c *command) report(err error) {
+	fmt.Fprintf(c.stderr, "%s\n", strings.TrimSpace(err.Error()))
+	c.exitCode = 2
+}
+
+func (c *command) processFile(filename string, in io.Reader) error {
+
+	// standard input is formatted with the settings of the current directory
+	dir := "."
+	if in == nil {
+		dir = filepath.Dir(filename)
+	}
+	formatter, err := c.formatter(dir)
+	if err != nil {
+		return err
+	}
+
+	var perm os.FileMode = 0644
+	// open the file if needed
+	if in == nil {
+		f, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		fi, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		in = f
+		perm = fi.Mode().Perm()
+	}
+
+	src, err := ioutil.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	var resBuff bytes.Buffer
+
+	if !*c.list && !*c.doDiff {
+		if err := formatter.FormatHTML(filename, bytes.NewReader(src), &resBuff); err != nil {
+			return err
+		}
+		res := resBuff.Bytes()
+
+		if *c.write {
+			if bytes.Equal(src, res) {
+				return nil
+			}
+			// make a temporary backup before overwriting original
+			bakname, err := backupFile(filename+".", src, perm)
+			if err != nil {
+				return err
+			}
+			err = ioutil.WriteFile(filename, res, perm)
+			if err != nil {
+				os.Rename(bakname, filename)
+				return err
+			}
+			err = os.Remove(bakname)
+			if err != nil {
+				return err
+			}
+		} else {
+			// just write to stdout
+			_, err = c.stdout.Write(res)
+		}
+	} else {
+		different, err := formatter.Diff(filename, bytes.NewReader(src), &resBuff)
+		if err != nil {
+			return fmt.Errorf("computing diff: %s", err)
+		}
+		if *c.list {
+			if different {
+				fmt.Fprintln(c.stdout, filename)
+			}
+		} else if *c.doDiff {
+			c.stdout.Write(resBuff.Bytes())
+		}
+	}
+
+	return nil
+}
+
+const chmodSupported = runtime.GOOS != "windows"
+
+// backupFile writes data to a new file named filename<number> with permissions perm,
+// with <number randomly chosen such that the file name is unique. backupFile returns
+// the chosen file name.
+func backupFile(filename string, data []byte, perm os.FileMode) (string, error) {
+
+	// create backup file
+	f, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename))
+	if err != nil {
+		return "", err
+	}
+
+	bakname := f.Name()
+
+	if chmodSupported {
+		err = f.Chmod(perm)
+		if err != nil {
+			f.Close()
+			os.Remove(bakname)
+			return bakname, err
+		}
+	}
+
+	// write data to backup file
+	_, err = f.Write(data)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	return bakname, err
+}
@@ -0,0 +1,92 @@
+package vugufmt
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommand(t *testing.T) {
+
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "vugufmt-command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the config is found in a parent of the current directory
+	sub := filepath.Join(dir, "app")
+	assert.NoError(os.MkdirAll(sub, 0755))
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, ConfigFileName), []byte(`{"fmt": {"indent_spaces": 2}}`), 0644))
+	const src, want = "<div>\n<p>x</p>\n</div>", "<div>\n  <p>x</p>\n</div>"
+	assert.NoError(ioutil.WriteFile(filepath.Join(sub, "root.vugu"), []byte(src), 0600))
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	assert.NoError(os.Chdir(sub))
+
+	run := func(stdin string, args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := Command(flag.NewFlagSet("vugufmt", flag.ContinueOnError), args, strings.NewReader(stdin), &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	code, out, _ := run(src)
+	assert.Equal(0, code)
+	assert.Equal(want, out)
+
+	code, out, _ = run("", "-l", ".")
+	assert.Equal(0, code)
+	assert.Equal("root.vugu\n", out)
+
+	// the flag overrides the config
+	code, out, _ = run(src, "-indent", "4")
+	assert.Equal(0, code)
+	assert.Equal("<div>\n    <p>x</p>\n</div>", out)
+
+	code, out, _ = run("", "-w", "root.vugu")
+	assert.Equal(0, code)
+	assert.Equal("", out)
+	b, err := ioutil.ReadFile("root.vugu")
+	assert.NoError(err)
+	assert.Equal(want, string(b))
+	fi, err := os.Stat("root.vugu")
+	assert.NoError(err)
+	assert.Equal(os.FileMode(0600), fi.Mode().Perm())
+	files, err := ioutil.ReadDir(".")
+	assert.NoError(err)
+	assert.Len(files, 1) // the backup was removed
+
+	code, _, errOut := run("<div>", "-l", "missing.vugu")
+	assert.Equal(2, code)
+	assert.Contains(errOut, "missing.vugu")
+
+	// files elsewhere are formatted with the settings of their own project, not those of the current directory
+	other := filepath.Join(dir, "other")
+	assert.NoError(os.MkdirAll(other, 0755))
+	assert.NoError(ioutil.WriteFile(filepath.Join(other, ConfigFileName), []byte(`{"fmt": {"indent_spaces": 3}}`), 0644))
+	assert.NoError(ioutil.WriteFile(filepath.Join(other, "page.vugu"), []byte(src), 0644))
+	code, out, _ = run("", filepath.Join(other, "page.vugu"))
+	assert.Equal(0, code)
+	assert.Equal("<div>\n   <p>x</p>\n</div>", out)
+	code, out, _ = run("", "../other")
+	assert.Equal(0, code)
+	assert.Equal("<div>\n   <p>x</p>\n</div>", out)
+
+	// and a typo in a project file is reported
+	assert.NoError(ioutil.WriteFile(filepath.Join(other, ConfigFileName), []byte(`{"fmt": {"indent_spacse": 3}}`), 0644))
+	code, _, errOut = run("", filepath.Join(other, "page.vugu"))
+	assert.Equal(2, code)
+	assert.Contains(errOut, "indent_spacse")
+}
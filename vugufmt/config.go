@@ -0,0 +1,110 @@
+package vugufmt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConfigFileName is the name of the project file which holds formatting settings in its "fmt" section.
+const ConfigFileName = "vugu.json"
+
+// Config holds formatting settings, as found in the "fmt" section of a project's vugu.json:
+//
+//	{
+//		"fmt": {"simplify": true, "sort_attrs": true, "max_width": 100, "indent_spaces": 2, "css": true}
+//	}
+type Config struct {
+	Imports      bool `json:"imports"`       // run goimports instead of gofmt
+	Simplify     bool `json:"simplify"`      // simplify code, like gofmt -s
+	SortAttrs    bool `json:"sort_attrs"`    // put attributes in canonical order, see AttrRules
+	MaxWidth     int  `json:"max_width"`     // one attribute per line for tags wider than this, 0 for no limit
+	IndentSpaces int  `json:"indent_spaces"` // indent nested elements with this many spaces instead of a tab
+	CSS          bool `json:"css"`           // format style blocks, see UseCSSFmt
+	JS           bool `json:"js"`            // re-indent JavaScript blocks, see UseJSFmt
+}
+
+// Options returns the options for NewFormatter that apply c.
+func (c *Config) Options() []func(*Formatter) {
+	var opts []func(*Formatter)
+	if c.Imports {
+		opts = append(opts, UseGoImports)
+	} else {
+		opts = append(opts, UseGoFmt(c.Simplify))
+	}
+	opts = append(opts, UseAttrRules(AttrRules{Sort: c.SortAttrs, MaxWidth: c.MaxWidth}))
+	if c.IndentSpaces > 0 {
+		opts = append(opts, UseIndent(strings.Repeat(" ", c.IndentSpaces)))
+	}
+	if c.CSS {
+		opts = append(opts, UseCSSFmt)
+	}
+	if c.JS {
+		opts = append(opts, UseJSFmt)
+	}
+	return opts
+}
+
+// FindConfig reads the "fmt" section of the vugu.json in dir, or else in the nearest parent directory
+// which has one, and returns it with the path of the file.  If there is no such file the zero Config
+// and an empty path are returned.  Unknown settings in the "fmt" section are an error, like they are
+// for the vugu command, so typos don't go unnoticed.
+func FindConfig(dir string) (*Config, string, error) {
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for {
+		p := filepath.Join(dir, ConfigFileName)
+		b, err := ioutil.ReadFile(p)
+		if err == nil {
+			cfg, err := parseConfig(b)
+			if err != nil {
+				return nil, p, fmt.Errorf("error reading %s: %w", p, err)
+			}
+			return cfg, p, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, p, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return &Config{}, "", nil
+		}
+		dir = parent
+	}
+}
+
+// parseConfig returns the "fmt" section of the contents of a vugu.json.  The other sections are
+// not checked, they are read by the vugu command.
+func parseConfig(b []byte) (*Config, error) {
+
+	var v struct {
+		Fmt json.RawMessage `json:"fmt"`
+	}
+	err := json.Unmarshal(b, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if len(v.Fmt) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(v.Fmt))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("fmt: %w", err)
+		}
+	}
+	if cfg.MaxWidth < 0 || cfg.IndentSpaces < 0 {
+		return nil, errors.New("fmt: max_width and indent_spaces must not be negative")
+	}
+	return cfg, nil
+}
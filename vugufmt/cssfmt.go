@@ -0,0 +1,243 @@
+package vugufmt
+
+import (
+	"bytes"
+	"strings"
+)
+
+// UseCSSFmt sets the formatter to format the CSS in style blocks: one declaration per line with
+// "prop: value;", rules indented by nesting, and whitespace around selectors, commas and combinators
+// normalized.  Strings, comments and the inside of parentheses (e.g. url(...)) are kept as they are.
+func UseCSSFmt(f *Formatter) {
+	f.StyleFormatter = func(input []byte) ([]byte, *FmtError) {
+		return formatCSS(input, f.indent())
+	}
+}
+
+// formatCSS formats a style sheet, indenting by indent for each level of nesting.  Input that is
+// only whitespace is returned as it is.  The Line and Column of a returned FmtError are relative to
+// the start of in, starting at 1.
+func formatCSS(in []byte, indent string) ([]byte, *FmtError) {
+
+	if len(bytes.TrimSpace(in)) == 0 {
+		return in, nil
+	}
+
+	var out bytes.Buffer
+	out.WriteByte('\n')
+
+	var item strings.Builder // the selector, declaration or at-rule being read, whitespace collapsed
+	depth := 0               // nesting of blocks
+	parens := 0              // nesting of parentheses in item
+	space := false           // whitespace was skipped since the last byte of item
+	newlines := 0            // newlines since the end of the last thing written
+	blank := false           // a blank line goes before the current item
+	blockStart := true       // nothing has been written in the current block yet
+
+	// start notes where a new item or standalone comment starts
+	start := func() {
+		blank = newlines > 1 && !blockStart
+		newlines = 0
+	}
+
+	writeLine := func(s string) {
+		if blank {
+			out.WriteByte('\n')
+		}
+		out.WriteString(strings.Repeat(indent, depth))
+		out.WriteString(s)
+		out.WriteByte('\n')
+		blank, blockStart, newlines = false, false, 0
+	}
+
+	// flush writes item, ending it with end (one of "{", ";" or "")
+	flush := func(end string) {
+		s := strings.TrimSpace(item.String())
+		item.Reset()
+		space = false
+		if s == "" {
+			return
+		}
+		switch {
+		case end == "{":
+			writeLine(normalizeCSS(s, !strings.HasPrefix(s, "@")) + " {")
+		case strings.HasPrefix(s, "@"):
+			writeLine(normalizeCSS(s, false) + end)
+		default:
+			writeLine(normalizeCSSDecl(s) + end)
+		}
+	}
+
+	for i := 0; i < len(in); i++ {
+		c := in[i]
+
+		switch {
+
+		case c == '/' && i+1 < len(in) && in[i+1] == '*':
+			end := bytes.Index(in[i+2:], []byte("*/"))
+			if end < 0 {
+				return in, cssError(in, i, "comment not terminated")
+			}
+			comment := string(in[i : i+2+end+2])
+			if strings.TrimSpace(item.String()) == "" {
+				// a comment on its own
+				start()
+				writeLine(comment)
+			} else {
+				if space {
+					item.WriteByte(' ')
+				}
+				item.WriteString(comment)
+				space = false
+			}
+			i += 2 + end + 1
+
+		case c == '"' || c == '\'':
+			end := cssStringEnd(in, i)
+			if end < 0 {
+				return in, cssError(in, i, "string not terminated")
+			}
+			if item.Len() == 0 {
+				start()
+			} else if space {
+				item.WriteByte(' ')
+			}
+			item.Write(in[i : end+1])
+			space = false
+			i = end
+
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			if c == '\n' {
+				newlines++
+			}
+			space = true
+
+		case c == '{' && parens == 0:
+			if strings.TrimSpace(item.String()) == "" {
+				return in, cssError(in, i, "missing selector before {")
+			}
+			flush("{")
+			depth++
+			blockStart = true
+
+		case c == ';' && parens == 0:
+			flush(";")
+
+		case c == '}' && parens == 0:
+			if depth == 0 {
+				return in, cssError(in, i, "unexpected }")
+			}
+			flush(";")
+			depth--
+			blank = false
+			writeLine("}")
+
+		default:
+			switch c {
+			case '(':
+				parens++
+			case ')':
+				if parens == 0 {
+					return in, cssError(in, i, "unexpected )")
+				}
+				parens--
+			}
+			if item.Len() == 0 {
+				start()
+			} else if space {
+				item.WriteByte(' ')
+			}
+			item.WriteByte(c)
+			space = false
+		}
+	}
+
+	if parens > 0 {
+		return in, cssError(in, len(in), "missing )")
+	}
+	if depth > 0 {
+		return in, cssError(in, len(in), "missing }")
+	}
+	flush("")
+
+	return out.Bytes(), nil
+}
+
+// normalizeCSSDecl normalizes a declaration to "prop: value"
+func normalizeCSSDecl(s string) string {
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return normalizeCSS(s, false)
+	}
+	return strings.TrimSpace(s[:i]) + ": " + normalizeCSS(strings.TrimSpace(s[i+1:]), false)
+}
+
+// normalizeCSS puts a single space after commas, and around combinators if selector is true, outside
+// of strings, comments, parentheses and brackets.  s must have its whitespace collapsed already.
+func normalizeCSS(s string, selector bool) string {
+
+	var sb strings.Builder
+	nest := 0
+
+	trimSpace := func() {
+		t := strings.TrimRight(sb.String(), " ")
+		sb.Reset()
+		sb.WriteString(t)
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\'':
+			end := cssStringEnd([]byte(s), i)
+			sb.WriteString(s[i : end+1])
+			i = end
+			continue
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			end := strings.Index(s[i+2:], "*/") + i + 4
+			sb.WriteString(s[i:end])
+			i = end - 1
+			continue
+		case c == '(' || c == '[':
+			nest++
+		case c == ')' || c == ']':
+			nest--
+		case nest == 0 && (c == ',' || selector && (c == '>' || c == '+' || c == '~')):
+			trimSpace()
+			if c != ',' && sb.Len() > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteByte(c)
+			sb.WriteByte(' ')
+			for i+1 < len(s) && s[i+1] == ' ' {
+				i++
+			}
+			continue
+		}
+		sb.WriteByte(c)
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// cssStringEnd returns the index of the quote which ends the string starting at in[i], or -1
+func cssStringEnd(in []byte, i int) int {
+	q := in[i]
+	for j := i + 1; j < len(in); j++ {
+		switch in[j] {
+		case '\\':
+			j++
+		case '\n':
+			return -1
+		case q:
+			return j
+		}
+	}
+	return -1
+}
+
+// cssError returns a FmtError with msg at offset i in in
+func cssError(in []byte, i int, msg string) *FmtError {
+	line, col := advancePosition(1, 1, in[:i])
+	return &FmtError{Msg: msg, Line: line, Column: col}
+}
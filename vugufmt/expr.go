@@ -0,0 +1,139 @@
+package vugufmt
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// goAttrKind says how the value of an attribute is parsed as Go code.
+type goAttrKind int
+
+const (
+	goAttrNone  goAttrKind = iota // not Go code
+	goAttrExpr                    // an expression: :attr, .prop, vg-if, vg-key
+	goAttrStmts                   // one or more statements: @event
+	goAttrFor                     // the clause of a for statement: vg-for
+)
+
+// goAttrKindFor returns the kind of Go code in the value of the attribute with the given key.
+func goAttrKindFor(key string) goAttrKind {
+	switch {
+	case key == "vg-if", key == "vg-key":
+		return goAttrExpr
+	case key == "vg-for", strings.HasPrefix(key, "vg-for."):
+		return goAttrFor
+	case strings.HasPrefix(key, ":"), strings.HasPrefix(key, "."):
+		return goAttrExpr
+	case strings.HasPrefix(key, "@"):
+		return goAttrStmts
+	}
+	return goAttrNone
+}
+
+// formatGoAttr formats the Go code in an attribute value with go/format.  Values with comments,
+// and values which would need more than one line, are returned unchanged.  The Line and Column
+// of a returned FmtError are relative to the start of val, starting at 1.
+func formatGoAttr(kind goAttrKind, val string) (string, *FmtError) {
+
+	fset := token.NewFileSet()
+
+	var node interface{}
+	var prefix, suffix string // stripped from the output
+
+	switch kind {
+
+	case goAttrExpr:
+		expr, err := parser.ParseExprFrom(fset, "", val, 0)
+		if err != nil {
+			return val, goParseError(err, val, 0, 0)
+		}
+		node = expr
+
+	case goAttrStmts, goAttrFor:
+		// wrap in a function so it can be parsed as a file
+		src := "package p\nfunc _() {\n" + val + "\n}\n"
+		colOffset := 0
+		if kind == goAttrFor {
+			src = "package p\nfunc _() {\nfor " + val + " {\n}\n}\n"
+			colOffset = len("for ")
+			prefix, suffix = "for ", " {\n}"
+		}
+		f, err := parser.ParseFile(fset, "", src, 0)
+		if err != nil {
+			return val, goParseError(err, val, 2, colOffset)
+		}
+		body := f.Decls[0].(*ast.FuncDecl).Body.List
+		if kind == goAttrFor {
+			if len(body) != 1 {
+				return val, &FmtError{Msg: "expected a single for clause", Line: 1, Column: 1}
+			}
+			node = body[0]
+			break
+		}
+		if len(body) == 0 {
+			return val, nil
+		}
+		// statements are formatted one by one and joined on one line
+		var parts []string
+		for _, stmt := range body {
+			var buf bytes.Buffer
+			if format.Node(&buf, fset, stmt) != nil {
+				return val, nil
+			}
+			parts = append(parts, buf.String())
+		}
+		return finishGoAttr(val, strings.Join(parts, "; "))
+	}
+
+	var buf bytes.Buffer
+	if format.Node(&buf, fset, node) != nil {
+		return val, nil
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, prefix) || !strings.HasSuffix(out, suffix) {
+		return val, nil
+	}
+	return finishGoAttr(val, strings.TrimSuffix(strings.TrimPrefix(out, prefix), suffix))
+}
+
+// finishGoAttr returns out, or val if out cannot be used in its place
+func finishGoAttr(val, out string) (string, *FmtError) {
+	// comments are not kept by formatting a node without them, and a value spread over several lines is
+	// not what anyone wants in an attribute, so leave those alone
+	if strings.Contains(val, "//") || strings.Contains(val, "/*") || strings.Contains(out, "\n") {
+		return val, nil
+	}
+	return out, nil
+}
+
+// goParseError converts an error from go/parser into a FmtError, adjusting the position for lines
+// and columns (on the first line) that were added around val to parse it.
+func goParseError(err error, val string, addedLines, addedCols int) *FmtError {
+	el, ok := err.(scanner.ErrorList)
+	if !ok || len(el) == 0 {
+		return &FmtError{Msg: err.Error(), Line: 1, Column: 1}
+	}
+	e := el[0]
+	line, col := e.Pos.Line-addedLines, e.Pos.Column
+	if line == 1 {
+		col -= addedCols
+	}
+	// errors found in the wrapping after val (e.g. a missing closing parenthesis)
+	// are reported at the end of val
+	lines := strings.Split(val, "\n")
+	if line > len(lines) {
+		line, col = len(lines), len(lines[len(lines)-1])+1
+	}
+	if line < 1 {
+		line, col = 1, 1
+	}
+	if col < 1 {
+		col = 1
+	}
+	return &FmtError{Msg: e.Msg, Line: line, Column: col}
+}
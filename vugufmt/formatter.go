@@ -27,6 +27,10 @@ type Formatter struct {
 	ScriptFormatters map[string]func([]byte) ([]byte, *FmtError)
 	// StyleFormatter handles CSS blocks.
 	StyleFormatter func([]byte) ([]byte, *FmtError)
+	// AttrRules controls the order and line layout of attributes, see UseAttrRules.
+	AttrRules AttrRules
+	// Indent is written once for each level of nesting at the start of a line, a tab if empty.
+	Indent string
 }
 
 // NewFormatter creates a new formatter.
@@ -80,15 +84,23 @@ func breaks(input string) int {
 	return numBreaks
 }
 
-// FormatHTML formats script and css nodes.
+// FormatHTML formats script and css nodes, and the Go code in :attr, .prop, @event, vg-if, vg-for
+// and vg-key attributes.  Go code in an attribute which does not parse results in a *FmtError.
 func (f *Formatter) FormatHTML(filename string, in io.Reader, out io.Writer) error {
 	izer := htmlx.NewTokenizer(in)
 	ts := tokenStack{}
 
 	curTok := htmlx.Token{}
 
+	cw := &colWriter{w: out}
+	out = cw
+
 	previousLineBreak := false
 
+	// position in the input of the start of the next token, the tokenizer's own
+	// column is not reliable after it looks ahead
+	line, col := 1, 1
+
 loop:
 	for {
 		curTokType := izer.Next()
@@ -125,7 +137,12 @@ loop:
 			}
 		}
 
+		// Raw must be copied before calling Token, which lower-cases tag and attribute names in place
+		raw := append([]byte(nil), izer.Raw()...)
+		attrSpans := izer.RawAttrSpans()
 		curTok := izer.Token()
+		tokLine, tokCol := line, col
+		line, col = advancePosition(line, col, raw)
 
 		// do indentation if we broke the line before this token.
 		if previousLineBreak {
@@ -133,19 +150,26 @@ loop:
 			if curTokType == htmlx.EndTagToken && indentLevel > 0 {
 				indentLevel--
 			}
-			for i := 0; i < indentLevel; i++ {
-				out.Write([]byte{'\t'})
-			}
+			out.Write([]byte(strings.Repeat(f.indent(), indentLevel)))
 		}
 		previousLineBreak = false
 
-		raw := izer.Raw()
 		raws := string(raw)
 		// add or remove tokens from the stack
 		switch curTokType {
-		case htmlx.StartTagToken:
-			ts.push(&curTok)
-			out.Write(raw)
+		case htmlx.StartTagToken, htmlx.SelfClosingTagToken:
+			t, err := f.splitTag(filename, &curTok, tokLine, tokCol, raw, attrSpans)
+			if err != nil {
+				return err
+			}
+			if t == nil {
+				out.Write(raw)
+			} else {
+				out.Write([]byte(f.layout(t, len(ts), cw.col)))
+			}
+			if curTokType == htmlx.StartTagToken {
+				ts.push(&curTok)
+			}
 		case htmlx.EndTagToken:
 			lastPushed := ts.pop()
 			if lastPushed.DataAtom != curTok.DataAtom {
@@ -195,11 +219,9 @@ loop:
 				// hey we are in a CSS text node
 				fmtr, err := f.FormatStyle(raw)
 				if err != nil {
-					return &FmtError{
-						Msg:    err.Error(),
-						Line:   curTok.Line,
-						Column: curTok.Column,
-					}
+					err.Line += curTok.Line
+					err.FileName = filename
+					return err
 				}
 				out.Write(fmtr)
 			} else {
@@ -212,6 +234,105 @@ loop:
 	}
 }
 
+// splitTag splits up a start tag for layout, formatting the Go code in its attributes (see goAttrKindFor).
+// raw is the tag as written, starting at line and col in the file, and spans is the location of each
+// attribute in it, from htmlx.Tokenizer.RawAttrSpans.  A value which does not parse results in a *FmtError
+// with its position in the file.  If the tag has no attributes, or they can't be told apart, nil is returned
+// and the tag is written as it is.
+func (f *Formatter) splitTag(filename string, tok *htmlx.Token, line, col int, raw []byte, spans [][4]int) (*tagLayout, error) {
+
+	if len(spans) == 0 || len(spans) != len(tok.Attr) {
+		return nil, nil
+	}
+
+	nameEnd := 1
+	for nameEnd < len(raw) && !isTagSpace(raw[nameEnd]) && raw[nameEnd] != '/' && raw[nameEnd] != '>' {
+		nameEnd++
+	}
+
+	t := &tagLayout{name: string(raw[:nameEnd])}
+	prev := nameEnd
+
+	for i, sp := range spans {
+		if sp[0] < prev {
+			return nil, nil
+		}
+
+		// the attribute ends after its key if there is no "=", otherwise after the value and its closing quote
+		quote := byte(0)
+		end := sp[1]
+		if bytes.IndexByte(raw[sp[1]:sp[2]], '=') >= 0 {
+			end = sp[3]
+			if sp[2] > 0 && (raw[sp[2]-1] == '"' || raw[sp[2]-1] == '\'') {
+				quote = raw[sp[2]-1]
+				if end < len(raw) && raw[end] == quote {
+					end++
+				}
+			}
+		}
+
+		attr := tok.Attr[i]
+		t.keys = append(t.keys, attr.Key)
+		t.seps = append(t.seps, string(raw[prev:sp[0]]))
+		t.texts = append(t.texts, string(raw[sp[0]:end]))
+		prev = end
+
+		kind := goAttrKindFor(attr.Key)
+		if kind == goAttrNone {
+			continue
+		}
+
+		fval, ferr := formatGoAttr(kind, attr.Val)
+		if ferr != nil {
+			vline, vcol := advancePosition(line, col, raw[:sp[2]])
+			if ferr.Line == 1 {
+				vcol += ferr.Column - 1
+			} else {
+				vcol = ferr.Column
+			}
+			return nil, &FmtError{
+				Msg:      fmt.Sprintf("%s: %s", attr.Key, ferr.Msg),
+				FileName: filename,
+				Line:     vline + ferr.Line - 1,
+				Column:   vcol,
+			}
+		}
+
+		// skip if unchanged, or if the value has character references that we would lose
+		if fval == attr.Val || string(raw[sp[2]:sp[3]]) != attr.Val {
+			continue
+		}
+
+		switch {
+		case quote != 0 && strings.IndexByte(fval, quote) >= 0:
+			continue
+		case quote == 0 && strings.ContainsAny(fval, " \t\n\"'=<>`"):
+			if strings.IndexByte(fval, '"') >= 0 {
+				continue
+			}
+			fval = `"` + fval + `"`
+		}
+
+		t.texts[i] = string(raw[sp[0]:sp[2]]) + fval + string(raw[sp[3]:end])
+	}
+
+	t.end = string(raw[prev:])
+	return t, nil
+}
+
+// isTagSpace returns true for the characters that separate a tag name from its attributes
+func isTagSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// advancePosition returns the line and column after b, starting from line and col
+func advancePosition(line, col int, b []byte) (int, int) {
+	if n := bytes.Count(b, []byte{'\n'}); n > 0 {
+		return line + n, len(b) - bytes.LastIndexByte(b, '\n')
+	}
+	return line, col + len(b)
+}
+
 // Diff will show differences between input and what
 // Format() would do. It will return (true, nil) if there
 // is a difference, (false, nil) if there is no difference,
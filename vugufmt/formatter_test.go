@@ -2,6 +2,7 @@ package vugufmt
 
 import (
 	"bytes"
+	"flag"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -92,8 +93,9 @@ func TestUncompilableGo(t *testing.T) {
 		assert.NoError(t, err, f)
 		// run gofmt on it
 		var buf bytes.Buffer
-		ferr := formatter.FormatHTML("oknow", strings.NewReader(testFileString), &buf)
-		assert.NotNil(t, ferr, f)
+		err = formatter.FormatHTML("oknow", strings.NewReader(testFileString), &buf)
+		ferr, ok := err.(*FmtError)
+		assert.True(t, ok, f)
 		// confirm the offset is correct!
 		assert.Equal(t, 46, ferr.Line, f)
 		assert.Equal(t, 22, ferr.Column, f)
@@ -124,3 +126,194 @@ func TestBadHTML(t *testing.T) {
 	prettyVersion := buf.String()
 	assert.NotEqual(t, testCode, prettyVersion)
 }
+
+func TestGoAttrs(t *testing.T) {
+
+	formatter := NewFormatter(UseGoFmt(false))
+
+	cases := []struct {
+		in, out string
+	}{
+		{`<div vg-if='len(c.Items)>0'></div>`, `<div vg-if='len(c.Items) > 0'></div>`},
+		{`<div :class="c.Class( 1,2 )" .value="c.V+1"></div>`, `<div :class="c.Class(1, 2)" .value="c.V + 1"></div>`},
+		{`<li vg-for="_,item:=range c.Items" vg-key="item.ID"></li>`, `<li vg-for="_, item := range c.Items" vg-key="item.ID"></li>`},
+		{`<li vg-for.noshadow='c.Items'></li>`, `<li vg-for.noshadow='c.Items'></li>`},
+		{`<button @click="c.Count++;c.Save( event )">x</button>`, `<button @click="c.Count++; c.Save(event)">x</button>`},
+		{`<main:Widget :Value="c.A+c.B"/>`, `<main:Widget :Value="c.A + c.B"/>`},
+		{`<div vg-if=c.A&&c.B></div>`, `<div vg-if="c.A && c.B"></div>`},
+		{`<div vg-if="c.A &amp;&amp;c.B"></div>`, `<div vg-if="c.A &amp;&amp;c.B"></div>`}, // character references are kept
+		{`<div @click="func() { c.X=1; c.Y=2 }()"></div>`, `<div @click="func() { c.X = 1; c.Y = 2 }()"></div>`},
+		{"<div @click=\"func() {\n\tc.X=1\n}()\"></div>", "<div @click=\"func() {\n\tc.X=1\n}()\"></div>"}, // would need several lines
+		{`<div class="a  b" title='x+y'></div>`, `<div class="a  b" title='x+y'></div>`},                   // not Go
+	}
+	for _, tc := range cases {
+		var buf bytes.Buffer
+		assert.NoError(t, formatter.FormatHTML("", strings.NewReader(tc.in), &buf), tc.in)
+		assert.Equal(t, tc.out, buf.String(), tc.in)
+	}
+
+	errCases := []struct {
+		in           string
+		line, column int
+	}{
+		{"<div>\n\t<span vg-if='c.A +'></span>\n</div>", 2, 20},
+		{"<div>\n\t<span\n\t\t:title=\"c.Title(\"></span>\n</div>", 3, 19},
+		{"<div @click=\"c.A(); c.B(\n\tc.C]\"></div>", 2, 5},
+		{"<li vg-for='x := range'></li>", 1, 24},
+	}
+	for _, tc := range errCases {
+		err := formatter.FormatHTML("test.vugu", strings.NewReader(tc.in), ioutil.Discard)
+		ferr, ok := err.(*FmtError)
+		if assert.True(t, ok, tc.in) {
+			assert.Equal(t, "test.vugu", ferr.FileName, tc.in)
+			assert.Equal(t, tc.line, ferr.Line, tc.in)
+			assert.Equal(t, tc.column, ferr.Column, tc.in)
+		}
+	}
+}
+
+func TestAttrRules(t *testing.T) {
+
+	cases := []struct {
+		rules   AttrRules
+		indent  string
+		in, out string
+	}{
+		// the zero value keeps attributes where they are
+		{AttrRules{}, "", "<div @click='c.X()'\n     class=\"a\" vg-if=\"c.A\"></div>", "<div @click='c.X()'\n     class=\"a\" vg-if=\"c.A\"></div>"},
+		// sorting keeps the space between attributes
+		{AttrRules{Sort: true}, "", "<div @click='c.X()'\n     class=\"a\" vg-if=\"c.A\"></div>", "<div vg-if=\"c.A\"\n     class=\"a\" @click='c.X()'></div>"},
+		{AttrRules{Sort: true}, "", `<li .value="c.V" :id="c.ID" id2="b" vg-key="c.K" vg-content="c.T" vg-for="c.Items" hidden></li>`,
+			`<li vg-for="c.Items" vg-key="c.K" vg-content="c.T" id2="b" hidden :id="c.ID" .value="c.V"></li>`},
+		{AttrRules{Sort: true}, "", `<main:W @change="c.X()" B="1" :A="c.A" C="2"/>`, `<main:W B="1" C="2" :A="c.A" @change="c.X()"/>`},
+		// tags that fit are put on one line
+		{AttrRules{MaxWidth: 40}, "", "<div\n\tclass=\"a\"   id=\"b\" >\n</div>", "<div class=\"a\" id=\"b\">\n</div>"},
+		{AttrRules{MaxWidth: 40}, "", `<br   class="a" />`, `<br class="a" />`},
+		// longer ones get one attribute per line, indented one level more than the tag
+		{AttrRules{MaxWidth: 40}, "", "<div>\n\t<span class=\"first\" :title=\"c.Title(1,2)\" @click=\"c.X()\">x</span>\n</div>",
+			"<div>\n\t<span\n\t\tclass=\"first\"\n\t\t:title=\"c.Title(1, 2)\"\n\t\t@click=\"c.X()\">x</span>\n</div>"},
+		{AttrRules{Sort: true, MaxWidth: 30}, "  ", "<div>\n<main:W @change=\"c.X()\" :A=\"c.A\" B=\"1\" />\n</div>",
+			"<div>\n  <main:W\n    B=\"1\"\n    :A=\"c.A\"\n    @change=\"c.X()\" />\n</div>"},
+		// the width includes the indentation, a tab counts as 4
+		{AttrRules{MaxWidth: 27}, "", "<div>\n\t<span class=\"a\" id=\"b\"></span>\n</div>", "<div>\n\t<span class=\"a\" id=\"b\"></span>\n</div>"},
+		{AttrRules{MaxWidth: 26}, "", "<div>\n\t<span class=\"a\" id=\"b\"></span>\n</div>", "<div>\n\t<span\n\t\tclass=\"a\"\n\t\tid=\"b\"></span>\n</div>"},
+		// nested elements are indented consistently
+		{AttrRules{}, "  ", "<div>\n\t\t<p>\n <b>x</b>\n\t</p>\n</div>", "<div>\n  <p>\n    <b>x</b>\n  </p>\n</div>"},
+	}
+	for _, tc := range cases {
+		formatter := NewFormatter(UseGoFmt(false), UseAttrRules(tc.rules), UseIndent(tc.indent))
+		var buf bytes.Buffer
+		assert.NoError(t, formatter.FormatHTML("", strings.NewReader(tc.in), &buf), tc.in)
+		assert.Equal(t, tc.out, buf.String(), tc.in)
+	}
+}
+
+func TestFindConfig(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "vugufmt-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg, p, err := FindConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "", p)
+	assert.Equal(t, Config{}, *cfg)
+
+	sub := filepath.Join(dir, "a", "b")
+	assert.NoError(t, os.MkdirAll(sub, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, ConfigFileName),
+		[]byte(`{"compiler": "go", "fmt": {"sort_attrs": true, "max_width": 80, "indent_spaces": 2}}`), 0644))
+
+	cfg, p, err = FindConfig(sub)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ConfigFileName), p)
+	assert.Equal(t, Config{SortAttrs: true, MaxWidth: 80, IndentSpaces: 2}, *cfg)
+
+	f := NewFormatter(cfg.Options()...)
+	assert.Equal(t, AttrRules{Sort: true, MaxWidth: 80}, f.AttrRules)
+	assert.Equal(t, "  ", f.Indent)
+	assert.NotNil(t, f.ScriptFormatters["application/x-go"])
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(sub, ConfigFileName), []byte(`{"fmt": {"max_width": -1}}`), 0644))
+	_, _, err = FindConfig(sub)
+	assert.Error(t, err)
+
+	// a typo in the fmt section is reported, other sections are left to the vugu command
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(sub, ConfigFileName), []byte(`{"serve": {"x": 1}, "fmt": {"max_widht": 100}}`), 0644))
+	_, p, err = FindConfig(sub)
+	assert.Equal(t, filepath.Join(sub, ConfigFileName), p)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "max_widht")
+	}
+}
+
+var update = flag.Bool("update", false, "update the .golden files")
+
+func TestGolden(t *testing.T) {
+
+	formatter := NewFormatter(UseGoFmt(false), UseCSSFmt, UseJSFmt)
+
+	files, err := filepath.Glob("testdata/golden/*.vugu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEmpty(t, files)
+
+	for _, in := range files {
+		src, err := ioutil.ReadFile(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if !assert.NoError(t, formatter.FormatHTML(in, bytes.NewReader(src), &buf), in) {
+			continue
+		}
+
+		golden := in + ".golden"
+		if *update {
+			if err := ioutil.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, string(want), buf.String(), in)
+
+		// formatting again changes nothing
+		var buf2 bytes.Buffer
+		assert.NoError(t, formatter.FormatHTML(in, bytes.NewReader(buf.Bytes()), &buf2), in)
+		assert.Equal(t, buf.String(), buf2.String(), in)
+	}
+}
+
+func TestCSSJSErrors(t *testing.T) {
+
+	formatter := NewFormatter(UseGoFmt(false), UseCSSFmt, UseJSFmt)
+
+	cases := []struct {
+		in           string
+		msg          string
+		line, column int
+	}{
+		{"<div></div>\n<style>\n.a { color: red; }}\n</style>", "unexpected }", 3, 19},
+		{"<div></div>\n<style>\n.a { color: red;\n</style>", "missing }", 4, 1},
+		{"<div></div>\n<style>\n.a { content: \"x; }\n</style>", "string not terminated", 3, 15},
+		{"<div></div>\n<script>\nif (a) {\n\tb(]\n}\n</script>", "unexpected ]", 4, 4},
+		{"<div></div>\n<script>\nif (a) {\n\tb()\n</script>", "{ not closed", 3, 8},
+		{"<div></div>\n<script>\nvar s = `abc\n</script>", "template literal not terminated", 3, 9},
+	}
+	for _, tc := range cases {
+		err := formatter.FormatHTML("test.vugu", strings.NewReader(tc.in), ioutil.Discard)
+		ferr, ok := err.(*FmtError)
+		if assert.True(t, ok, tc.in) {
+			assert.Equal(t, "test.vugu", ferr.FileName, tc.in)
+			assert.Equal(t, tc.msg, ferr.Msg, tc.in)
+			assert.Equal(t, tc.line, ferr.Line, tc.in)
+			assert.Equal(t, tc.column, ferr.Column, tc.in)
+		}
+	}
+}
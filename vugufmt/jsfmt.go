@@ -0,0 +1,283 @@
+package vugufmt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// jsScriptTypes are the script types formatted by the formatter set with UseJSFmt.
+var jsScriptTypes = []string{"", "text/javascript", "application/javascript", "module"}
+
+// UseJSFmt sets the formatter to re-indent JavaScript blocks.  This is conservative: only the
+// indentation of lines (one level for each line with unclosed brackets) and blank lines at the start
+// and end change.  Lines inside comments and template literals are kept as they are.
+func UseJSFmt(f *Formatter) {
+	for _, t := range jsScriptTypes {
+		f.ScriptFormatters[t] = func(input []byte) ([]byte, *FmtError) {
+			return formatJS(input, f.indent())
+		}
+	}
+}
+
+// jsOpen is an open bracket, or the start of a template literal or a ${ in one
+type jsOpen struct {
+	c         byte // one of { [ ( ` $
+	indent    int  // indent of the line it is on
+	line, col int
+}
+
+// jsLexer follows a script line by line, far enough to know what brackets are open.
+type jsLexer struct {
+	stack   []jsOpen
+	comment bool   // in a /* comment */
+	quote   byte   // in a string continued on the next line with a backslash
+	lastSig byte   // last character of code which is not whitespace, 0 at the start
+	word    string // last identifier or keyword, if it was the last thing in the code
+}
+
+// inText returns true if the lexer is in the middle of a comment, string or template literal, where
+// the whitespace at the start of a line means something.
+func (l *jsLexer) inText() bool {
+	return l.comment || l.quote != 0 || len(l.stack) > 0 && l.stack[len(l.stack)-1].c == '`'
+}
+
+// regexAllowed returns true if a / at this point starts a regular expression rather than a division.
+func (l *jsLexer) regexAllowed() bool {
+	switch l.word {
+	case "return", "typeof", "case", "do", "else", "in", "of", "new", "delete", "void", "throw", "yield", "await":
+		return true
+	case "":
+	default:
+		return false
+	}
+	return l.lastSig == 0 || strings.IndexByte("(,=:[!&|?{};+-*%<>~^", l.lastSig) >= 0
+}
+
+// scan reads one line (without its newline), starting at line number lineNo, which is indented by indent.
+func (l *jsLexer) scan(s string, lineNo, indent int) *FmtError {
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		if l.comment {
+			if end := strings.Index(s[i:], "*/"); end >= 0 {
+				l.comment = false
+				i += end + 1
+				continue
+			}
+			return nil
+		}
+
+		if l.quote != 0 {
+			end := jsStringEnd(s, i, l.quote)
+			if end < 0 {
+				if strings.HasSuffix(s, "\\") {
+					return nil
+				}
+				return &FmtError{Msg: "string not terminated", Line: lineNo, Column: i + 1}
+			}
+			l.quote, l.lastSig, l.word = 0, '"', ""
+			i = end
+			continue
+		}
+
+		if n := len(l.stack); n > 0 && l.stack[n-1].c == '`' {
+			switch {
+			case c == '\\':
+				i++
+			case c == '`':
+				l.stack = l.stack[:n-1]
+				l.lastSig, l.word = '`', ""
+			case c == '$' && i+1 < len(s) && s[i+1] == '{':
+				l.stack = append(l.stack, jsOpen{c: '$', indent: indent, line: lineNo, col: i + 1})
+				l.lastSig, l.word = '{', ""
+				i++
+			}
+			continue
+		}
+
+		switch {
+
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			continue
+
+		case c == '/' && i+1 < len(s) && s[i+1] == '/':
+			return nil
+
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			l.comment = true
+			i++
+			continue
+
+		case c == '/' && l.regexAllowed():
+			if end := jsRegexEnd(s, i); end > 0 {
+				i = end
+				l.lastSig, l.word = '/', ""
+				continue
+			}
+
+		case c == '"' || c == '\'':
+			end := jsStringEnd(s, i+1, c)
+			if end < 0 {
+				if strings.HasSuffix(s, "\\") {
+					l.quote = c
+					return nil
+				}
+				return &FmtError{Msg: "string not terminated", Line: lineNo, Column: i + 1}
+			}
+			i = end
+			l.lastSig, l.word = '"', ""
+			continue
+
+		case c == '`' || c == '{' || c == '[' || c == '(':
+			l.stack = append(l.stack, jsOpen{c: c, indent: indent, line: lineNo, col: i + 1})
+
+		case c == '}' || c == ']' || c == ')':
+			want := byte('(')
+			switch c {
+			case '}':
+				want = '{'
+			case ']':
+				want = '['
+			}
+			n := len(l.stack)
+			if n == 0 || l.stack[n-1].c != want && !(c == '}' && l.stack[n-1].c == '$') {
+				return &FmtError{Msg: fmt.Sprintf("unexpected %c", c), Line: lineNo, Column: i + 1}
+			}
+			l.stack = l.stack[:n-1]
+
+		case c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80:
+			j := i
+			for j < len(s) && (s[j] == '_' || s[j] == '$' || s[j] >= 'a' && s[j] <= 'z' || s[j] >= 'A' && s[j] <= 'Z' || s[j] >= '0' && s[j] <= '9' || s[j] >= 0x80) {
+				j++
+			}
+			l.lastSig, l.word = s[j-1], s[i:j]
+			i = j - 1
+			continue
+		}
+
+		l.lastSig, l.word = c, ""
+	}
+
+	return nil
+}
+
+// formatJS re-indents a script by indent for each level of nesting.  Input that is only whitespace
+// is returned as it is.  The Line and Column of a returned FmtError are relative to the start of
+// in, starting at 1.
+func formatJS(in []byte, indent string) ([]byte, *FmtError) {
+
+	if len(bytes.TrimSpace(in)) == 0 {
+		return in, nil
+	}
+
+	lines := strings.Split(string(in), "\n")
+	var out []string
+	var l jsLexer
+
+	for n, line := range lines {
+
+		if l.inText() {
+			// the start of the line is part of a comment, string or template literal
+			out = append(out, line)
+			if err := l.scan(line, n+1, -1); err != nil {
+				return in, err
+			}
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		level := 0
+		if k := len(l.stack); k > 0 {
+			top := l.stack[k-1]
+			level = top.indent + 1
+			if top.indent < 0 {
+				// opened on a line that was kept as it is, keep the indentation of the line
+				level = -1
+			}
+			if trimmed != "" && strings.IndexByte("}])", trimmed[0]) >= 0 {
+				level = top.indent
+			}
+		}
+
+		// scan the line as written, so error columns refer to the input
+		if err := l.scan(line, n+1, level); err != nil {
+			return in, err
+		}
+
+		switch {
+		case level < 0:
+			out = append(out, line)
+		case trimmed == "":
+			out = append(out, "")
+		case l.inText():
+			// trailing whitespace belongs to the text which continues on the next line
+			out = append(out, strings.Repeat(indent, level)+strings.TrimLeft(line, " \t"))
+		default:
+			out = append(out, strings.Repeat(indent, level)+trimmed)
+		}
+	}
+
+	switch {
+	case l.comment:
+		return in, &FmtError{Msg: "comment not terminated", Line: len(lines), Column: len(lines[len(lines)-1]) + 1}
+	case l.quote != 0:
+		return in, &FmtError{Msg: "string not terminated", Line: len(lines), Column: len(lines[len(lines)-1]) + 1}
+	case len(l.stack) > 0:
+		open := l.stack[len(l.stack)-1]
+		what := string(open.c)
+		switch open.c {
+		case '`':
+			return in, &FmtError{Msg: "template literal not terminated", Line: open.line, Column: open.col}
+		case '$':
+			what = "${"
+		}
+		return in, &FmtError{Msg: fmt.Sprintf("%s not closed", what), Line: open.line, Column: open.col}
+	}
+
+	// blank lines at the start and end are dropped
+	for len(out) > 0 && out[0] == "" {
+		out = out[1:]
+	}
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+
+	return []byte("\n" + strings.Join(out, "\n") + "\n"), nil
+}
+
+// jsStringEnd returns the index of the quote q which ends a string, starting the search at i, or -1
+// if it is not on the line
+func jsStringEnd(s string, i int, q byte) int {
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case q:
+			return i
+		}
+	}
+	return -1
+}
+
+// jsRegexEnd returns the index of the / which ends the regular expression starting at s[i], or -1
+// if it is not on the line
+func jsRegexEnd(s string, i int) int {
+	class := false
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			class = true
+		case ']':
+			class = false
+		case '/':
+			if !class {
+				return j
+			}
+		}
+	}
+	return -1
+}
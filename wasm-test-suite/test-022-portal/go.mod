@@ -0,0 +1,11 @@
+module github.com/vugu/vugu/wasm-test-suite/test
+
+go 1.14
+
+replace github.com/vugu/vugu => ../..
+
+require (
+	github.com/vugu/vgrouter v0.0.0-20200406224410-145a62403c71
+	github.com/vugu/vjson v0.0.0-20191111004939-722507e863cb
+	github.com/vugu/vugu v0.1.1-0.20200406224150-50acda24c5ef
+)
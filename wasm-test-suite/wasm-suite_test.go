@@ -649,11 +649,69 @@ func Test020VGForm(t *testing.T) {
 
 	log.Printf("URL: %s", "http://localhost:8846"+pathSuffix)
 
+	// select the options of the multiple select #produce with these keys, as the user would with ctrl+click
+	selectProduceJS := func(keys ...string) string {
+		return `(function() {
+			let keys = "` + strings.Join(keys, " ") + `".split(" ");
+			let sel = document.querySelector("#produce");
+			for (let opt of sel.options) { opt.selected = keys.includes(opt.value); }
+			sel.dispatchEvent(new Event("change"));
+			return true;
+		})()`
+	}
+	var tmpres bool
+
+	uploadPath := filepath.Join(dir, "upload.txt")
+	must(ioutil.WriteFile(uploadPath, []byte("hello vgform"), 0644))
+	defer os.Remove(uploadPath)
+
 	must(chromedp.Run(ctx,
 		chromedp.Navigate("http://localhost:8846"+pathSuffix),
 		chromedp.WaitVisible("#food_group_value"), // make sure things showed up
 		chromedp.SendKeys(`#food_group`, "Butterfinger Group"),
 		WaitInnerTextTrimEq("#food_group_value", "butterfinger_group"),
+		WaitInnerTextTrimEq("#agree_value", "false"),
+		chromedp.Click("#agree"),
+		WaitInnerTextTrimEq("#agree_value", "true"),
+		WaitInnerTextTrimEq("#size_value", "medium"),
+		chromedp.Click("#size_large"),
+		WaitInnerTextTrimEq("#size_value", "large"),
+		chromedp.Click("#color_blue"),
+		chromedp.Click("#color_red"),
+		WaitInnerTextTrimEq("#colors_value", "blue,red"),
+		chromedp.Click("#color_blue"),
+		WaitInnerTextTrimEq("#colors_value", "red"),
+		chromedp.SendKeys("#quantity", "5\t"),
+		WaitInnerTextTrimEq("#quantity_value", "5"),
+		chromedp.SetValue("#quantity", ""),
+		chromedp.SendKeys("#quantity", "42\t"),
+		WaitInnerTextTrimEq("#quantity_err", "must be at most 10"),
+		WaitInnerTextTrimEq("#quantity_value", "5"),
+		chromedp.SetValue("#quantity", ""),
+		chromedp.SendKeys("#quantity", "1e\t"), // accepted by the number input but not a number, so validity.badInput
+		WaitInnerTextTrimEq("#quantity_err", "not a valid whole number"),
+		WaitInnerTextTrimEq("#quantity_value", "5"),
+		chromedp.Click("#signup_submit"),
+		WaitInnerTextTrimEq("#signup_email_err", "a value is required"),
+		WaitInnerTextTrimEq("#signup_count", "0"),
+		chromedp.SendKeys("#signup_email", "joe\t"),
+		WaitInnerTextTrimEq("#signup_email_err", "is not in the expected format"),
+		chromedp.SendKeys("#signup_email", "@example.com\t"),
+		WaitInnerTextTrimEq("#signup_email_err", ""),
+		chromedp.Click("#signup_submit"),
+		WaitInnerTextTrimEq("#signup_count", "1"),
+		chromedp.Evaluate(selectProduceJS("pear", "carrot"), &tmpres),
+		WaitInnerTextTrimEq("#produce_value", "pear,carrot"),
+		chromedp.WaitReady(`#produce optgroup[label="Vegetables"] option[value="leek"][disabled]`),
+		chromedp.Click("#fruit_only"),
+		chromedp.Evaluate(selectProduceJS("apple", "pear"), &tmpres),
+		WaitInnerTextTrimEq("#produce_value", "pear,carrot,apple"),
+		chromedp.SetUploadFiles("#upload input", []string{uploadPath}),
+		WaitInnerTextTrimEq("#upload_name", "upload.txt"),
+		WaitInnerTextTrimEq("#upload_size", "12"),
+		WaitInnerTextTrimEq("#upload_data", "hello vgform"),
+		WaitInnerTextTrimEq("#upload_count", "1"),
+		WaitInnerTextTrimEq("#upload_progress", "1.000000"),
 	))
 
 }
@@ -692,6 +750,48 @@ func Test021Slots(t *testing.T) {
 
 }
 
+func Test022Portal(t *testing.T) {
+
+	dir, origDir := mustUseDir("test-022-portal")
+	defer os.Chdir(origDir)
+	mustGen(dir)
+	pathSuffix := mustBuildAndLoad(dir)
+	ctx, cancel := mustChromeCtx()
+	defer cancel()
+
+	log.Printf("URL: %s", "http://localhost:8846"+pathSuffix)
+
+	var targetHTML string
+	must(chromedp.Run(ctx,
+		chromedp.Navigate("http://localhost:8846"+pathSuffix),
+		chromedp.WaitVisible("#portal_target #counter"), // the component is rendered into the portal target
+		WaitInnerTextTrimEq("#count", "0"),
+
+		// events on elements in the portal reach the component
+		chromedp.Click("#increment"),
+		WaitInnerTextTrimEq("#count", "1"),
+
+		// re-rendering the root keeps the same component instance
+		chromedp.Click("#rerender"),
+		WaitInnerTextTrimEq("#renders", "1"),
+		WaitInnerTextTrimEq("#count", "1"),
+		chromedp.Click("#increment"),
+		WaitInnerTextTrimEq("#count", "2"),
+		chromedp.Click("#rerender"),
+		WaitInnerTextTrimEq("#renders", "2"),
+		WaitInnerTextTrimEq("#portal_target #count", "2"),
+
+		// removing the portal empties its target, and adding it back renders into it again
+		chromedp.Click("#toggle_portal"),
+		chromedp.WaitNotPresent("#portal_target #counter"),
+		chromedp.InnerHTML("#portal_target", &targetHTML),
+		chromedp.Click("#toggle_portal"),
+		chromedp.WaitVisible("#portal_target #counter"),
+	))
+	assert.Equal(t, "", targetHTML)
+
+}
+
 func Test100TinygoSimple(t *testing.T) {
 
 	// TODO: This is work in progress - it does actually compile but needs some more work to
@@ -0,0 +1,158 @@
+// +build !tinygo
+
+package vugu
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var builderType = reflect.TypeOf((*Builder)(nil)).Elem()
+
+type wireProvider struct {
+	typ       reflect.Type
+	scope     WireScope
+	fn        reflect.Value // provider function, invalid if val was given directly
+	takesComp bool          // fn accepts the component being wired
+	val       reflect.Value // singleton value, once created
+}
+
+// ProvideValue registers v as a singleton; it is injected wherever its exact type (or
+// an interface it implements) is requested.
+func (r *WireRegistry) ProvideValue(v interface{}) {
+	if v == nil {
+		panic(fmt.Errorf("WireRegistry.ProvideValue called with nil"))
+	}
+	rv := reflect.ValueOf(v)
+	r.providers = append(r.providers, &wireProvider{typ: rv.Type(), scope: WireSingleton, val: rv})
+}
+
+// ProvideFunc registers a provider function with the specified scope.  The function must have
+// the form func() T or func(vugu.Builder) T, in the latter case the component being
+// wired is passed in.  For WireSingleton the function is called the first time T is needed
+// and for WirePerComponent it is called once for each component that needs T.
+func (r *WireRegistry) ProvideFunc(scope WireScope, f interface{}) {
+	fv := reflect.ValueOf(f)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func || ft.NumOut() != 1 || ft.NumIn() > 1 ||
+		(ft.NumIn() == 1 && ft.In(0) != builderType) {
+		panic(fmt.Errorf("WireRegistry.ProvideFunc requires func() T or func(vugu.Builder) T, got %v", ft))
+	}
+	r.providers = append(r.providers, &wireProvider{
+		typ:       ft.Out(0),
+		scope:     scope,
+		fn:        fv,
+		takesComp: ft.NumIn() == 1,
+	})
+}
+
+// Wire injects values into the component.  It is intended to be passed to BuildEnv.SetWireFunc.
+// Fields tagged `vugu:"inject"` with no corresponding provider are recorded and can be
+// obtained with Missing; setter methods with no provider are simply not called.
+func (r *WireRegistry) Wire(c Builder) {
+
+	rv := reflect.ValueOf(c)
+	rt := rv.Type()
+
+	// per-component values are shared across injection points within the same component
+	perComp := make(map[*wireProvider]reflect.Value)
+
+	// setter methods
+	for i := 0; i < rt.NumMethod(); i++ {
+		m := rt.Method(i)
+		if len(m.Name) <= 3 || !strings.HasSuffix(m.Name, "Set") {
+			continue
+		}
+		// receiver plus one arg, nothing returned
+		if m.Type.NumIn() != 2 || m.Type.NumOut() != 0 {
+			continue
+		}
+		p := r.providerFor(m.Type.In(1))
+		if p == nil {
+			continue
+		}
+		rv.Method(i).Call([]reflect.Value{r.value(p, c, perComp)})
+	}
+
+	// tagged fields
+	if rt.Kind() == reflect.Ptr && rt.Elem().Kind() == reflect.Struct && !rv.IsNil() {
+		r.wireStruct(c, rt.String(), "", rv.Elem(), perComp)
+	}
+}
+
+func (r *WireRegistry) wireStruct(c Builder, compTypeName, prefix string, sv reflect.Value, perComp map[*wireProvider]reflect.Value) {
+
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		fv := sv.Field(i)
+
+		if hasTagPart(sf.Tag.Get("vugu"), "inject") {
+			if !fv.CanSet() {
+				panic(fmt.Errorf("field %s%s on %s is tagged vugu:\"inject\" but cannot be set (is it exported?)", prefix, sf.Name, compTypeName))
+			}
+			p := r.providerFor(sf.Type)
+			if p == nil {
+				r.addMissing(WireMissing{ComponentType: compTypeName, Field: prefix + sf.Name, Type: sf.Type.String()})
+				continue
+			}
+			fv.Set(r.value(p, c, perComp))
+			continue
+		}
+
+		// descend into embedded structs
+		if !sf.Anonymous {
+			continue
+		}
+		switch {
+		case fv.Kind() == reflect.Struct:
+			r.wireStruct(c, compTypeName, prefix+sf.Name+".", fv, perComp)
+		case fv.Kind() == reflect.Ptr && !fv.IsNil() && fv.Elem().Kind() == reflect.Struct:
+			r.wireStruct(c, compTypeName, prefix+sf.Name+".", fv.Elem(), perComp)
+		}
+	}
+}
+
+// providerFor returns the provider for the exact type t if there is one, otherwise the first
+// provider whose type is assignable to t.  Returns nil if none is found.
+func (r *WireRegistry) providerFor(t reflect.Type) *wireProvider {
+	for _, p := range r.providers {
+		if p.typ == t {
+			return p
+		}
+	}
+	for _, p := range r.providers {
+		if p.typ.AssignableTo(t) {
+			return p
+		}
+	}
+	return nil
+}
+
+// value returns the value to inject from p, creating it if needed according to its scope.
+func (r *WireRegistry) value(p *wireProvider, c Builder, perComp map[*wireProvider]reflect.Value) reflect.Value {
+
+	call := func() reflect.Value {
+		var in []reflect.Value
+		if p.takesComp {
+			in = []reflect.Value{reflect.ValueOf(&c).Elem()}
+		}
+		return p.fn.Call(in)[0]
+	}
+
+	switch p.scope {
+	case WirePerComponent:
+		v, ok := perComp[p]
+		if !ok {
+			v = call()
+			perComp[p] = v
+		}
+		return v
+	default:
+		if !p.val.IsValid() {
+			p.val = call()
+		}
+		return p.val
+	}
+}
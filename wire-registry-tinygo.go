@@ -0,0 +1,17 @@
+// +build tinygo
+
+package vugu
+
+type wireProvider struct{}
+
+func (r *WireRegistry) ProvideValue(v interface{}) {
+	panic("TBD tinygo")
+}
+
+func (r *WireRegistry) ProvideFunc(scope WireScope, f interface{}) {
+	panic("TBD tinygo")
+}
+
+func (r *WireRegistry) Wire(c Builder) {
+	panic("TBD tinygo")
+}
@@ -0,0 +1,90 @@
+package vugu
+
+import "fmt"
+
+// WireScope specifies how long a value created by a provider registered with WireRegistry lives.
+type WireScope int
+
+const (
+	// WireSingleton means the provider is called once and the same value is injected into every component.
+	WireSingleton WireScope = iota
+
+	// WirePerComponent means the provider is called once for each component being wired.
+	WirePerComponent
+)
+
+// String returns the name of the scope.
+func (s WireScope) String() string {
+	switch s {
+	case WireSingleton:
+		return "singleton"
+	case WirePerComponent:
+		return "per-component"
+	}
+	return fmt.Sprintf("WireScope(%d)", int(s))
+}
+
+// WireMissing describes an injection point on a component for which no provider was registered.
+type WireMissing struct {
+	ComponentType string // type name of the component, e.g. "*main.Root"
+	Field         string // name of the field (dotted for nested embedded structs)
+	Type          string // the type that was requested
+}
+
+// String returns a human readable description.
+func (m WireMissing) String() string {
+	return fmt.Sprintf("%s: field %s has no provider for type %s", m.ComponentType, m.Field, m.Type)
+}
+
+// WireRegistry provides declarative dependency injection for components.
+// Providers are registered by type and values are injected into exported
+// component fields tagged with `vugu:"inject"`, and into setter methods
+// of the form XSet(v T) (e.g. the CounterSet method of a CounterSetter interface)
+// where T is a provided type.  Fields of embedded structs are examined as well.
+//
+// The Wire method has the same signature expected by BuildEnv.SetWireFunc, so
+// a registry is used with a BuildEnv like so:
+//
+//	reg := vugu.NewWireRegistry()
+//	reg.ProvideValue(&Counter{})
+//	reg.ProvideFunc(vugu.WirePerComponent, func(c vugu.Builder) *Logger { return NewLogger(c) })
+//	buildEnv.SetWireFunc(reg.Wire)
+//
+// Since the generated code calls BuildEnv.WireComponent for each component it creates,
+// this is all that is needed for injection to happen throughout the tree.
+type WireRegistry struct {
+	providers []*wireProvider
+	missing   []WireMissing
+}
+
+// NewWireRegistry returns a new empty WireRegistry.
+func NewWireRegistry() *WireRegistry {
+	return &WireRegistry{}
+}
+
+// Missing returns the injection points encountered by Wire which could not be satisfied.
+// Each distinct component type and field is reported only once.
+func (r *WireRegistry) Missing() []WireMissing {
+	return r.missing
+}
+
+// MissingError returns an error describing all missing injections, or nil if there are none.
+func (r *WireRegistry) MissingError() error {
+	if len(r.missing) == 0 {
+		return nil
+	}
+	msg := r.missing[0].String()
+	if len(r.missing) > 1 {
+		msg = fmt.Sprintf("%s (and %d more)", msg, len(r.missing)-1)
+	}
+	return fmt.Errorf("unsatisfied injection: %s", msg)
+}
+
+func (r *WireRegistry) addMissing(m WireMissing) {
+	for _, em := range r.missing {
+		if em == m {
+			return
+		}
+	}
+	r.missing = append(r.missing, m)
+}
@@ -0,0 +1,97 @@
+package vugu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type wireTestCounter struct{ n int }
+
+type wireTestLogger interface{ Log(s string) }
+
+type wireTestLog struct{ prefix string }
+
+func (l *wireTestLog) Log(s string) {}
+
+type wireTestCounterRef struct{ Counter *wireTestCounter }
+
+func (r *wireTestCounterRef) CounterSet(c *wireTestCounter) { r.Counter = c }
+
+type wireTestComp struct {
+	wireTestCounterRef
+	Logger wireTestLogger   `vugu:"inject"`
+	Log2   *wireTestLog     `vugu:"inject"`
+	Other  *wireTestCounter // not tagged
+}
+
+func (c *wireTestComp) Build(in *BuildIn) (out *BuildOut) { return &BuildOut{} }
+
+type wireTestNeedy struct {
+	Missing *string `vugu:"inject"`
+}
+
+func (c *wireTestNeedy) Build(in *BuildIn) (out *BuildOut) { return &BuildOut{} }
+
+func TestWireRegistry(t *testing.T) {
+
+	assert := assert.New(t)
+
+	counter := &wireTestCounter{}
+	reg := NewWireRegistry()
+	reg.ProvideValue(counter)
+	logCalls := 0
+	reg.ProvideFunc(WirePerComponent, func(c Builder) *wireTestLog {
+		logCalls++
+		_, ok := c.(*wireTestComp)
+		assert.True(ok)
+		return &wireTestLog{}
+	})
+
+	be, err := NewBuildEnv()
+	assert.NoError(err)
+	be.SetWireFunc(reg.Wire)
+
+	c1 := &wireTestComp{}
+	be.WireComponent(c1)
+	assert.Equal(counter, c1.Counter)
+	assert.Nil(c1.Other)
+	assert.NotNil(c1.Log2)
+	assert.Equal(c1.Log2, c1.Logger) // same per-component value for both fields
+	assert.Equal(1, logCalls)
+
+	c2 := &wireTestComp{}
+	be.WireComponent(c2)
+	assert.Equal(counter, c2.Counter)
+	assert.True(c1.Log2 != c2.Log2)
+	assert.Equal(2, logCalls)
+
+	assert.Len(reg.Missing(), 0)
+	assert.NoError(reg.MissingError())
+
+	be.WireComponent(&wireTestNeedy{})
+	be.WireComponent(&wireTestNeedy{})
+	assert.Len(reg.Missing(), 1)
+	assert.Equal("Missing", reg.Missing()[0].Field)
+	assert.Error(reg.MissingError())
+}
+
+func TestWireRegistrySingletonFunc(t *testing.T) {
+
+	assert := assert.New(t)
+
+	calls := 0
+	reg := NewWireRegistry()
+	reg.ProvideFunc(WireSingleton, func() *wireTestLog {
+		calls++
+		return &wireTestLog{prefix: "x"}
+	})
+
+	c1, c2 := &wireTestComp{}, &wireTestComp{}
+	reg.Wire(c1)
+	reg.Wire(c2)
+	assert.Equal(1, calls)
+	assert.True(c1.Log2 == c2.Log2)
+
+	assert.Panics(func() { reg.ProvideFunc(WireSingleton, func(a, b int) int { return 0 }) })
+}